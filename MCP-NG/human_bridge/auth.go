@@ -0,0 +1,227 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// authConfig is the file given with -auth-file. Without it the bridge accepts anonymous
+// connections that may use every topic.
+//
+//	{
+//	  "allowed_origins": ["https://ops.example.com"],
+//	  "teams": {"ops": ["team:ops", "human_intervention_required"]},
+//	  "tokens": [{"token": "...", "operator": "alice", "teams": ["ops"]}],
+//	  "jwt": {"secret": "...", "issuer": "https://sso.example.com", "audience": "human_bridge"}
+//	}
+type authConfig struct {
+	// AllowedOrigins lists the Origin headers browsers may connect from. Empty allows any.
+	AllowedOrigins []string `json:"allowed_origins"`
+	// Teams maps a team to the topics, or path.Match patterns, its members may use.
+	Teams  map[string][]string `json:"teams"`
+	Tokens []staticToken       `json:"tokens"`
+	JWT    *jwtConfig          `json:"jwt"`
+}
+
+// staticToken grants a fixed bearer token to an operator.
+type staticToken struct {
+	Token    string   `json:"token"`
+	Operator string   `json:"operator"`
+	Teams    []string `json:"teams"`
+	Topics   []string `json:"topics"`
+}
+
+// jwtConfig accepts HS256 JWTs signed with Secret. The operator is the "sub" claim and the
+// optional "teams" and "topics" claims work like those of a static token.
+type jwtConfig struct {
+	Secret   string `json:"secret"`
+	Issuer   string `json:"issuer"`
+	Audience string `json:"audience"`
+}
+
+// identity is who a connection belongs to and which topics it may use.
+type identity struct {
+	Operator string
+	topics   []string
+	all      bool
+}
+
+// anonymous is the identity of every connection when authentication is off.
+var anonymous = &identity{all: true}
+
+// allowed reports whether the identity may use a topic.
+func (id *identity) allowed(topic string) bool {
+	if id.all {
+		return true
+	}
+	for _, pattern := range id.topics {
+		if ok, _ := path.Match(pattern, topic); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticator checks the credentials of upgrade requests. A nil authenticator lets
+// everyone in anonymously.
+type authenticator struct {
+	config authConfig
+}
+
+// loadAuth reads an auth file, or returns nil if path is empty.
+func loadAuth(path string) (*authenticator, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	a := &authenticator{}
+	if err := json.Unmarshal(data, &a.config); err != nil {
+		return nil, fmt.Errorf("failed to parse auth file %s: %w", path, err)
+	}
+	for _, t := range a.config.Tokens {
+		if t.Token == "" || t.Operator == "" {
+			return nil, errors.New("every token needs a token and an operator")
+		}
+	}
+	if a.config.JWT != nil && a.config.JWT.Secret == "" {
+		return nil, errors.New("jwt needs a secret")
+	}
+	return a, nil
+}
+
+// checkOrigin is the upgrader's origin check.
+func (a *authenticator) checkOrigin(r *http.Request) bool {
+	if a == nil || len(a.config.AllowedOrigins) == 0 {
+		return true
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true // Not a browser
+	}
+	for _, allowed := range a.config.AllowedOrigins {
+		if strings.EqualFold(origin, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticate resolves the bearer token of an upgrade request, taken from the
+// Authorization header or, for browsers that cannot set it, the "token" query parameter.
+func (a *authenticator) authenticate(r *http.Request) (*identity, error) {
+	if a == nil {
+		return anonymous, nil
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		token = r.URL.Query().Get("token")
+	}
+	if token == "" {
+		return nil, errors.New("missing bearer token")
+	}
+	for _, t := range a.config.Tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(t.Token)) == 1 {
+			return a.identity(t.Operator, t.Teams, t.Topics), nil
+		}
+	}
+	if a.config.JWT != nil && strings.Count(token, ".") == 2 {
+		claims, err := a.config.JWT.verify(token, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		return a.identity(claims.Subject, claims.Teams, claims.Topics), nil
+	}
+	return nil, errors.New("invalid token")
+}
+
+func (a *authenticator) identity(operator string, teams, topics []string) *identity {
+	id := &identity{Operator: operator, topics: append([]string(nil), topics...)}
+	for _, team := range teams {
+		id.topics = append(id.topics, a.config.Teams[team]...)
+	}
+	return id
+}
+
+// jwtClaims are the claims the bridge reads from a JWT.
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *int64   `json:"exp"`
+	NotBefore *int64   `json:"nbf"`
+	Teams     []string `json:"teams"`
+	Topics    []string `json:"topics"`
+}
+
+// audience is the "aud" claim, a string or an array of strings.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*a = audience{one}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(a))
+}
+
+// verify checks a JWT's signature and claims and returns the claims.
+func (c *jwtConfig) verify(token string, now time.Time) (*jwtClaims, error) {
+	parts := strings.Split(token, ".")
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil || header.Alg != "HS256" {
+		return nil, errors.New("invalid token: only HS256 JWTs are accepted")
+	}
+	mac := hmac.New(sha256.New, []byte(c.Secret))
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, errors.New("invalid token: bad signature")
+	}
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	switch {
+	case claims.Subject == "":
+		return nil, errors.New("invalid token: no subject")
+	case claims.ExpiresAt != nil && now.Unix() >= *claims.ExpiresAt:
+		return nil, errors.New("invalid token: expired")
+	case claims.NotBefore != nil && now.Unix() < *claims.NotBefore:
+		return nil, errors.New("invalid token: not valid yet")
+	case c.Issuer != "" && claims.Issuer != c.Issuer:
+		return nil, errors.New("invalid token: wrong issuer")
+	}
+	if c.Audience != "" {
+		for _, aud := range claims.Audience {
+			if aud == c.Audience {
+				return &claims, nil
+			}
+		}
+		return nil, errors.New("invalid token: wrong audience")
+	}
+	return &claims, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
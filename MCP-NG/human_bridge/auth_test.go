@@ -0,0 +1,102 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// signJWT builds an HS256 JWT with the given claims.
+func signJWT(secret string, claims map[string]interface{}) string {
+	enc := func(v interface{}) string {
+		data, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	unsigned := enc(map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + enc(claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func testAuthenticator() *authenticator {
+	return &authenticator{config: authConfig{
+		Teams:  map[string][]string{"ops": {"team:ops", "human_intervention_required"}},
+		Tokens: []staticToken{{Token: "alice-token", Operator: "alice", Teams: []string{"ops"}}, {Token: "tool-token", Operator: "human_input", Topics: []string{"*"}}},
+		JWT:    &jwtConfig{Secret: "s3cret", Issuer: "sso", Audience: "human_bridge"},
+	}}
+}
+
+func TestAuthenticate(t *testing.T) {
+	a := testAuthenticator()
+	request := func(header, query string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/ws"+query, nil)
+		if header != "" {
+			r.Header.Set("Authorization", header)
+		}
+		return r
+	}
+	id, err := a.authenticate(request("Bearer alice-token", ""))
+	if err != nil || id.Operator != "alice" || !id.allowed("team:ops") || id.allowed("team:support") {
+		t.Errorf("expected alice to see only the ops topics, got %+v, %v", id, err)
+	}
+	if id, err := a.authenticate(request("", "?token=tool-token")); err != nil || !id.allowed("team:support") {
+		t.Errorf("expected the query token to be accepted, got %+v, %v", id, err)
+	}
+
+	exp := time.Now().Add(time.Hour).Unix()
+	good := signJWT("s3cret", map[string]interface{}{"sub": "bob", "iss": "sso", "aud": []string{"human_bridge"}, "exp": exp, "teams": []string{"ops"}})
+	if id, err := a.authenticate(request("Bearer "+good, "")); err != nil || id.Operator != "bob" || !id.allowed("human_intervention_required") {
+		t.Errorf("expected bob's JWT to be accepted, got %+v, %v", id, err)
+	}
+	for name, token := range map[string]string{
+		"missing":       "",
+		"unknown":       "Bearer nope",
+		"bad signature": "Bearer " + signJWT("other", map[string]interface{}{"sub": "bob", "iss": "sso", "aud": "human_bridge"}),
+		"expired":       "Bearer " + signJWT("s3cret", map[string]interface{}{"sub": "bob", "iss": "sso", "aud": "human_bridge", "exp": time.Now().Add(-time.Minute).Unix()}),
+		"wrong issuer":  "Bearer " + signJWT("s3cret", map[string]interface{}{"sub": "bob", "iss": "evil", "aud": "human_bridge"}),
+		"wrong aud":     "Bearer " + signJWT("s3cret", map[string]interface{}{"sub": "bob", "iss": "sso", "aud": "other"}),
+	} {
+		if _, err := a.authenticate(request(token, "")); err == nil {
+			t.Errorf("%s: expected the token to be rejected", name)
+		}
+	}
+}
+
+func TestAuthenticatedBridge(t *testing.T) {
+	hub := newHub(&queue{historyLimit: 10, byID: make(map[string]*queuedMessage)}, nil)
+	auth := testAuthenticator()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveWs(hub, auth, w, r)
+	}))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+
+	if _, resp, err := websocket.DefaultDialer.Dial(url, nil); err == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected an anonymous connection to be refused, got %v", err)
+	}
+	alice := dial(t, url+"?token=alice-token&topics=team:ops")
+	send(t, alice, Envelope{Type: frameSubscribe, Topics: []string{"team:support"}})
+	if env := receive(t, alice); env.Type != frameError || env.Topic != "team:support" {
+		t.Errorf("expected alice to be refused the support topic, got %+v", env)
+	}
+
+	tool := dial(t, url+"?token=tool-token")
+	send(t, tool, Envelope{Type: framePublish, Topic: "team:ops", ID: "m1", From: "mallory", Payload: json.RawMessage(`{}`)})
+	receive(t, tool)
+	msg := receive(t, alice)
+	if msg.ID != "m1" || msg.From != "human_input" {
+		t.Errorf("expected the message to carry the publisher's identity, got %+v", msg)
+	}
+	send(t, alice, Envelope{Type: frameAck, ID: "m1"})
+	if env := receive(t, alice); env.Type != frameAcked || env.AckedBy != "alice" {
+		t.Errorf("expected the acknowledgement to be attributed to alice, got %+v", env)
+	}
+}
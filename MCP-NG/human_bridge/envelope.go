@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Frame types of the bridge protocol. Clients send subscribe, unsubscribe, publish, ack,
+// history and respond frames; the bridge answers with subscribed, unsubscribed, published,
+// acked, history, responded or error frames and delivers messages as message frames.
+const (
+	frameSubscribe    = "subscribe"
+	frameUnsubscribe  = "unsubscribe"
+	framePublish      = "publish"
+	frameAck          = "ack"
+	frameHistory      = "history" // Both the request and its reply
+	frameRespond      = "respond"
+	frameSubscribed   = "subscribed"
+	frameUnsubscribed = "unsubscribed"
+	framePublished    = "published"
+	frameAcked        = "acked"
+	frameResponded    = "responded"
+	frameMessage      = "message"
+	frameError        = "error"
+)
+
+// Envelope is a frame of the bridge protocol, sent as one JSON text message:
+//
+//	{"type": "subscribe", "topics": ["human_intervention_required", "team:ops"]}
+//	{"type": "publish", "topic": "team:ops", "id": "optional-id", "payload": {...}}
+//	{"type": "message", "topic": "team:ops", "id": "...", "payload": {...},
+//	 "published_at": "...", "delivered_at": "..."}
+//	{"type": "ack", "id": "..."}
+//	{"type": "history", "topic": "team:ops", "limit": 20}
+//	{"type": "respond", "task_id": "...", "response": {"approved": true}}
+type Envelope struct {
+	Type   string   `json:"type"`
+	Topic  string   `json:"topic,omitempty"`
+	Topics []string `json:"topics,omitempty"`
+	// ID identifies a published message. The bridge assigns one if the publisher did not.
+	ID string `json:"id,omitempty"`
+	// From is the authenticated operator who published the message, set by the bridge.
+	From    string          `json:"from,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+	// PublishedAt is when the bridge accepted the message, DeliveredAt when it sent this
+	// copy to the subscriber.
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+	// Delivered is the number of subscribers a published frame reached.
+	Delivered *int   `json:"delivered,omitempty"`
+	Error     string `json:"error,omitempty"`
+	// AckedAt is when an operator acknowledged the message; unacknowledged messages are
+	// delivered again to every client that subscribes to their topic, marked Redelivered.
+	AckedAt     *time.Time `json:"acked_at,omitempty"`
+	AckedBy     string     `json:"acked_by,omitempty"`
+	Redelivered bool       `json:"redelivered,omitempty"`
+	// Limit caps the messages a history request returns; Messages holds them, oldest first.
+	Limit    int         `json:"limit,omitempty"`
+	Messages []*Envelope `json:"messages,omitempty"`
+	// TaskID and Response are an operator's answer to a human-input task, which the bridge
+	// forwards to the MCP server. Duplicate marks a reply to an answer already delivered.
+	TaskID    string          `json:"task_id,omitempty"`
+	Response  json.RawMessage `json:"response,omitempty"`
+	Duplicate bool            `json:"duplicate,omitempty"`
+	// Code is the gRPC code of an error from the MCP server, such as InvalidArgument for a
+	// response that does not fill in the task's form.
+	Code string `json:"code,omitempty"`
+}
+
+func encode(env *Envelope) []byte {
+	data, err := json.Marshal(env)
+	if err != nil {
+		// Envelopes only hold JSON-safe values, so this cannot happen.
+		panic(err)
+	}
+	return data
+}
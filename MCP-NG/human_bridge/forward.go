@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	pb "mcp-ng/server/pkg/mcp"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	forwardAttempts = 5
+	forwardBackoff  = 500 * time.Millisecond
+	forwardTimeout  = 10 * time.Second
+	// forwardMemory is how many delivered task IDs are remembered to spot duplicates.
+	forwardMemory = 10000
+)
+
+// forwarder delivers operators' responses to the MCP server's ProvideHumanInput, retrying
+// while the server is unreachable and answering repeated responses without resending them.
+type forwarder struct {
+	client   pb.MCPClient
+	attempts int
+	backoff  time.Duration
+
+	mu       sync.Mutex
+	inflight map[string]bool
+	done     map[string]string // Task ID to the operator whose response was delivered
+	order    []string          // The keys of done, oldest first
+}
+
+func newForwarder(client pb.MCPClient) *forwarder {
+	return &forwarder{
+		client:   client,
+		attempts: forwardAttempts,
+		backoff:  forwardBackoff,
+		inflight: make(map[string]bool),
+		done:     make(map[string]string),
+	}
+}
+
+// dialForwarder connects a forwarder to the MCP server's gRPC address, or returns nil if
+// the address is empty.
+func dialForwarder(addr string) (*forwarder, error) {
+	if addr == "" {
+		return nil, nil
+	}
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	return newForwarder(pb.NewMCPClient(conn)), nil
+}
+
+// deliver hands a response to the MCP server. It reports whether the same operator's
+// response for the task had already been delivered, in which case nothing is sent.
+func (f *forwarder) deliver(taskID, operator string, response json.RawMessage) (bool, error) {
+	value := &structpb.Value{}
+	if err := protojson.Unmarshal(response, value); err != nil {
+		return false, status.Errorf(codes.InvalidArgument, "response must be JSON: %v", err)
+	}
+
+	f.mu.Lock()
+	if by, ok := f.done[taskID]; ok {
+		f.mu.Unlock()
+		if by != operator {
+			return false, status.Errorf(codes.AlreadyExists, "Human input task '%s' was already answered by %q.", taskID, by)
+		}
+		return true, nil
+	}
+	if f.inflight[taskID] {
+		f.mu.Unlock()
+		return false, status.Errorf(codes.Aborted, "A response for task '%s' is already being delivered.", taskID)
+	}
+	f.inflight[taskID] = true
+	f.mu.Unlock()
+
+	err := f.send(&pb.ProvideHumanInputRequest{TaskId: taskID, Response: value, Operator: operator})
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.inflight, taskID)
+	if err != nil {
+		return false, err
+	}
+	f.done[taskID] = operator
+	f.order = append(f.order, taskID)
+	if len(f.order) > forwardMemory {
+		delete(f.done, f.order[0])
+		f.order = f.order[1:]
+	}
+	return false, nil
+}
+
+// send calls ProvideHumanInput, retrying errors that may go away.
+func (f *forwarder) send(req *pb.ProvideHumanInputRequest) error {
+	backoff := f.backoff
+	var err error
+	for attempt := 1; attempt <= f.attempts; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), forwardTimeout)
+		_, err = f.client.ProvideHumanInput(ctx, req)
+		cancel()
+		switch status.Code(err) {
+		case codes.OK:
+			return nil
+		case codes.AlreadyExists:
+			if attempt > 1 {
+				return nil // An earlier attempt got through before timing out.
+			}
+			return err
+		case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal:
+			log.Printf("Failed to deliver the response for task %s (attempt %d of %d): %v", req.TaskId, attempt, f.attempts, err)
+			if attempt < f.attempts {
+				time.Sleep(backoff)
+				backoff *= 2
+			}
+		default:
+			return err
+		}
+	}
+	return fmt.Errorf("gave up delivering the response after %d attempts: %w", f.attempts, err)
+}
+
+// payloadTaskID returns the task ID of a human_input prompt carried by a message.
+func payloadTaskID(payload json.RawMessage) string {
+	var prompt struct {
+		TaskID string `json:"task_id"`
+	}
+	json.Unmarshal(payload, &prompt)
+	return prompt.TaskID
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pb "mcp-ng/server/pkg/mcp"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeMCPClient answers ProvideHumanInput with the queued errors, then with success.
+type fakeMCPClient struct {
+	pb.MCPClient
+	mu       sync.Mutex
+	errs     []error
+	requests []*pb.ProvideHumanInputRequest
+}
+
+func (f *fakeMCPClient) ProvideHumanInput(ctx context.Context, in *pb.ProvideHumanInputRequest, opts ...grpc.CallOption) (*pb.ProvideHumanInputResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, in)
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		return nil, err
+	}
+	return &pb.ProvideHumanInputResponse{Status: "received"}, nil
+}
+
+func (f *fakeMCPClient) calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.requests)
+}
+
+func testForwarder(errs ...error) (*forwarder, *fakeMCPClient) {
+	client := &fakeMCPClient{errs: errs}
+	f := newForwarder(client)
+	f.backoff = time.Millisecond
+	return f, client
+}
+
+func TestForwarderRetriesAndDeduplicates(t *testing.T) {
+	f, client := testForwarder(status.Error(codes.Unavailable, "down"), status.Error(codes.Unavailable, "down"))
+	if duplicate, err := f.deliver("t1", "alice", json.RawMessage(`{"approved": true}`)); err != nil || duplicate {
+		t.Fatalf("expected the response to get through after retrying, got %v, %v", duplicate, err)
+	}
+	if client.calls() != 3 || client.requests[2].Operator != "alice" || !client.requests[2].Response.GetStructValue().Fields["approved"].GetBoolValue() {
+		t.Fatalf("unexpected requests: %v", client.requests)
+	}
+	if duplicate, err := f.deliver("t1", "alice", json.RawMessage(`{"approved": true}`)); err != nil || !duplicate || client.calls() != 3 {
+		t.Errorf("expected a repeated response to be answered without resending it, got %v, %v", duplicate, err)
+	}
+	if _, err := f.deliver("t1", "bob", json.RawMessage(`{"approved": false}`)); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("expected another operator's late answer to be refused, got %v", err)
+	}
+}
+
+func TestForwarderErrors(t *testing.T) {
+	f, client := testForwarder(status.Error(codes.InvalidArgument, "Response does not match the task's form"))
+	if _, err := f.deliver("t1", "alice", json.RawMessage(`"yes"`)); status.Code(err) != codes.InvalidArgument || client.calls() != 1 {
+		t.Errorf("expected a validation error without retrying, got %v after %d calls", err, client.calls())
+	}
+	// The operator can fix the answer and send it again.
+	if _, err := f.deliver("t1", "alice", json.RawMessage(`{"approved": true}`)); err != nil {
+		t.Errorf("expected the corrected response to be delivered, got %v", err)
+	}
+
+	// A retry that finds the task answered means an earlier attempt got through.
+	f, _ = testForwarder(status.Error(codes.DeadlineExceeded, "slow"), status.Error(codes.AlreadyExists, "answered"))
+	if _, err := f.deliver("t2", "alice", json.RawMessage(`true`)); err != nil {
+		t.Errorf("expected the retried response to count as delivered, got %v", err)
+	}
+
+	f, client = testForwarder(status.Error(codes.Unavailable, "down"), status.Error(codes.Unavailable, "down"))
+	f.attempts = 2
+	if _, err := f.deliver("t3", "alice", json.RawMessage(`true`)); status.Code(err) != codes.Unavailable || client.calls() != 2 {
+		t.Errorf("expected delivery to give up after two attempts, got %v", err)
+	}
+}
+
+func TestRespondFrame(t *testing.T) {
+	f, client := testForwarder(status.Error(codes.InvalidArgument, "Response does not match the task's form"))
+	hub := newHub(&queue{historyLimit: 10, byID: make(map[string]*queuedMessage)}, f)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveWs(hub, nil, w, r)
+	}))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+
+	tool := dial(t, url)
+	send(t, tool, Envelope{Type: framePublish, Topic: "approvals", ID: "m1", Payload: json.RawMessage(`{"task_id":"t1","prompt":"Proceed?"}`)})
+	receive(t, tool)
+	operator := dial(t, url+"?topics=approvals")
+	receive(t, operator) // The queued prompt
+
+	send(t, operator, Envelope{Type: frameRespond, ID: "r1", TaskID: "t1", Response: json.RawMessage(`"yes"`)})
+	if env := receive(t, operator); env.Type != frameError || env.Code != "InvalidArgument" || env.TaskID != "t1" || env.ID != "r1" {
+		t.Fatalf("expected the validation error to be sent back, got %+v", env)
+	}
+	send(t, operator, Envelope{Type: frameRespond, ID: "r2", TaskID: "t1", Response: json.RawMessage(`{"approved":true}`)})
+	if env := receive(t, operator); env.Type != frameResponded || env.TaskID != "t1" || env.ID != "r2" || env.Duplicate {
+		t.Fatalf("expected the response to be confirmed, got %+v", env)
+	}
+	if client.calls() != 2 {
+		t.Errorf("expected two deliveries, got %d", client.calls())
+	}
+	// Answering the task acknowledges its prompt.
+	send(t, operator, Envelope{Type: frameHistory, Topic: "approvals"})
+	if env := receive(t, operator); len(env.Messages) != 1 || env.Messages[0].AckedAt == nil {
+		t.Errorf("expected the prompt to be acknowledged, got %+v", env)
+	}
+}
+
+func TestRespondFrameWithAuth(t *testing.T) {
+	f, client := testForwarder()
+	hub := newHub(&queue{historyLimit: 10, byID: make(map[string]*queuedMessage)}, f)
+	auth := testAuthenticator()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveWs(hub, auth, w, r)
+	}))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+
+	tool := dial(t, url+"?token=tool-token")
+	send(t, tool, Envelope{Type: framePublish, Topic: "team:support", ID: "m1", Payload: json.RawMessage(`{"task_id":"t1"}`)})
+	receive(t, tool)
+	send(t, tool, Envelope{Type: framePublish, Topic: "team:ops", ID: "m2", Payload: json.RawMessage(`{"task_id":"t2"}`)})
+	receive(t, tool)
+	alice := dial(t, url+"?token=alice-token&topics=team:ops")
+	receive(t, alice) // The queued ops prompt
+
+	// Alice may only answer tasks whose prompt is on one of her team's topics.
+	send(t, alice, Envelope{Type: frameRespond, ID: "r1", TaskID: "t1", Response: json.RawMessage(`true`)})
+	if env := receive(t, alice); env.Type != frameError || env.Topic != "team:support" {
+		t.Errorf("expected alice to be refused a support task, got %+v", env)
+	}
+	send(t, alice, Envelope{Type: frameRespond, ID: "r2", TaskID: "unknown", Response: json.RawMessage(`true`)})
+	if env := receive(t, alice); env.Type != frameError || env.TaskID != "unknown" || env.ID != "r2" {
+		t.Errorf("expected alice to be refused a task outside the queue, got %+v", env)
+	}
+	if client.calls() != 0 {
+		t.Fatalf("expected refused responses not to be forwarded, got %d calls", client.calls())
+	}
+	send(t, alice, Envelope{Type: frameRespond, ID: "r3", TaskID: "t2", Response: json.RawMessage(`true`)})
+	if env := receive(t, alice); env.Type != frameResponded || client.calls() != 1 || client.requests[0].Operator != "alice" {
+		t.Errorf("expected alice's answer to her team's task to be delivered, got %+v", env)
+	}
+}
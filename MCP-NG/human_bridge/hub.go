@@ -0,0 +1,374 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// sendBuffer is how many frames may wait for a slow client before it is dropped.
+	sendBuffer = 256
+	writeWait  = 10 * time.Second
+	pongWait   = 60 * time.Second
+	pingPeriod = pongWait * 9 / 10
+	// maxFrameSize bounds a frame read from a client.
+	maxFrameSize = 1 << 20
+	// defaultHistoryPage and maxHistoryPage bound the messages of a history reply.
+	defaultHistoryPage = 50
+	maxHistoryPage     = 500
+)
+
+// client is one WebSocket connection. Frames to it are queued on send and written by its
+// own goroutine, since a connection supports only one writer.
+type client struct {
+	hub      *Hub
+	conn     *websocket.Conn
+	identity *identity
+	send     chan []byte
+	topics   map[string]bool // Guarded by hub.mu
+	closed   bool            // Guarded by hub.mu
+}
+
+// Hub routes published messages to the clients subscribed to their topic and keeps them
+// queued until an operator acknowledges them. With a forwarder, it also delivers operators'
+// responses to the MCP server.
+type Hub struct {
+	mu        sync.Mutex
+	clients   map[*client]bool
+	topics    map[string]map[*client]bool
+	queue     *queue
+	forwarder *forwarder
+}
+
+func newHub(q *queue, f *forwarder) *Hub {
+	return &Hub{
+		clients:   make(map[*client]bool),
+		topics:    make(map[string]map[*client]bool),
+		queue:     q,
+		forwarder: f,
+	}
+}
+
+func (h *Hub) register(conn *websocket.Conn, id *identity) *client {
+	c := &client{hub: h, conn: conn, identity: id, send: make(chan []byte, sendBuffer), topics: make(map[string]bool)}
+	h.mu.Lock()
+	h.clients[c] = true
+	h.mu.Unlock()
+	log.Printf("Client registered: %s (operator %q)", conn.RemoteAddr(), id.Operator)
+	return c
+}
+
+// unregister removes a client and its subscriptions and stops its writer.
+func (h *Hub) unregister(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.drop(c)
+}
+
+// drop is unregister with h.mu held.
+func (h *Hub) drop(c *client) {
+	if c.closed {
+		return
+	}
+	c.closed = true
+	delete(h.clients, c)
+	for topic := range c.topics {
+		h.removeSubscriber(topic, c)
+	}
+	close(c.send)
+	log.Printf("Client unregistered: %s", c.conn.RemoteAddr())
+}
+
+func (h *Hub) removeSubscriber(topic string, c *client) {
+	subs := h.topics[topic]
+	delete(subs, c)
+	if len(subs) == 0 {
+		delete(h.topics, topic)
+	}
+}
+
+// subscribe adds the client to the topics, confirms it, and redelivers the messages of
+// those topics that no operator has acknowledged yet. Nothing is subscribed if the client
+// may not use one of the topics.
+func (h *Hub) subscribe(c *client, topics []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, topic := range topics {
+		if !c.identity.allowed(topic) {
+			h.push(c, encode(forbidden(topic)))
+			return
+		}
+	}
+	for _, topic := range topics {
+		if h.topics[topic] == nil {
+			h.topics[topic] = make(map[*client]bool)
+		}
+		h.topics[topic][c] = true
+		c.topics[topic] = true
+	}
+	h.push(c, encode(&Envelope{Type: frameSubscribed, Topics: topics}))
+	now := time.Now()
+	for _, topic := range topics {
+		for _, m := range h.queue.pending(topic) {
+			env := m.envelope()
+			env.DeliveredAt = &now
+			env.Redelivered = true
+			h.push(c, encode(env))
+		}
+	}
+}
+
+func (h *Hub) unsubscribe(c *client, topics []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, topic := range topics {
+		delete(c.topics, topic)
+		h.removeSubscriber(topic, c)
+	}
+}
+
+// publish queues a message and delivers it to the topic's subscribers other than the
+// sender. A message whose ID was already published is not delivered again.
+func (h *Hub) publish(sender *client, m *queuedMessage) *Envelope {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	m, added, err := h.queue.add(m)
+	if err != nil {
+		log.Printf("Failed to queue message %s: %v", m.ID, err)
+		return &Envelope{Type: frameError, ID: m.ID, Error: "failed to queue the message"}
+	}
+	published := m.PublishedAt
+	n := 0
+	if added {
+		for c := range h.topics[m.Topic] {
+			if c == sender {
+				continue
+			}
+			env := m.envelope()
+			now := time.Now()
+			env.DeliveredAt = &now
+			if h.push(c, encode(env)) {
+				n++
+			}
+		}
+		log.Printf("Published message %s on topic %q to %d subscribers", m.ID, m.Topic, n)
+	}
+	return &Envelope{Type: framePublished, Topic: m.Topic, ID: m.ID, PublishedAt: &published, Delivered: &n}
+}
+
+// ack records that an operator subscribed to a message's topic has taken care of it.
+func (h *Hub) ack(c *client, id string) *Envelope {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if m := h.queue.byID[id]; m == nil || !c.topics[m.Topic] {
+		return &Envelope{Type: frameError, ID: id, Error: "no such message on a subscribed topic"}
+	}
+	m, err := h.queue.ack(id, c.identity.Operator, time.Now())
+	if err != nil {
+		log.Printf("Failed to acknowledge message %s: %v", id, err)
+		return &Envelope{Type: frameError, ID: id, Error: "failed to acknowledge the message"}
+	}
+	return &Envelope{Type: frameAcked, Topic: m.Topic, ID: m.ID, AckedAt: m.AckedAt, AckedBy: m.AckedBy}
+}
+
+// history returns the newest messages of a topic, acknowledged or not.
+func (h *Hub) history(topic string, limit int) *Envelope {
+	if limit <= 0 {
+		limit = defaultHistoryPage
+	}
+	limit = min(limit, maxHistoryPage)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	messages := []*Envelope{}
+	for _, m := range h.queue.history(topic, limit) {
+		messages = append(messages, m.envelope())
+	}
+	return &Envelope{Type: frameHistory, Topic: topic, Messages: messages}
+}
+
+// respond forwards an operator's response to the MCP server in the background and tells the
+// operator whether it was accepted. Delivering it acknowledges the task's prompts. With
+// authentication on, operators may only answer tasks the bridge holds a prompt for, and
+// only if every prompt for the task is on one of their topics; otherwise an operator could
+// publish a copy of another team's prompt on their own topic and answer it.
+func (h *Hub) respond(c *client, env *Envelope) {
+	if env.TaskID == "" || len(env.Response) == 0 {
+		h.reply(c, &Envelope{Type: frameError, ID: env.ID, TaskID: env.TaskID, Error: "respond needs a task_id and a response"})
+		return
+	}
+	if h.forwarder == nil {
+		h.reply(c, &Envelope{Type: frameError, ID: env.ID, TaskID: env.TaskID, Error: "this bridge does not forward responses"})
+		return
+	}
+	h.mu.Lock()
+	prompts := h.queue.forTask(env.TaskID)
+	h.mu.Unlock()
+	if len(prompts) == 0 && !c.identity.all {
+		h.reply(c, &Envelope{Type: frameError, ID: env.ID, TaskID: env.TaskID, Error: "no prompt for task " + env.TaskID + " on this bridge"})
+		return
+	}
+	for _, prompt := range prompts {
+		if !c.identity.allowed(prompt.Topic) {
+			h.reply(c, forbidden(prompt.Topic))
+			return
+		}
+	}
+	go func() {
+		operator := c.identity.Operator
+		duplicate, err := h.forwarder.deliver(env.TaskID, operator, env.Response)
+		if err != nil {
+			log.Printf("Response for task %s from operator %q was not accepted: %v", env.TaskID, operator, err)
+			st := status.Convert(err)
+			h.reply(c, &Envelope{Type: frameError, ID: env.ID, TaskID: env.TaskID, Code: st.Code().String(), Error: st.Message()})
+			return
+		}
+		log.Printf("Delivered the response for task %s from operator %q", env.TaskID, operator)
+		h.mu.Lock()
+		for _, prompt := range prompts {
+			if _, err := h.queue.ack(prompt.ID, operator, time.Now()); err != nil {
+				log.Printf("Failed to acknowledge message %s: %v", prompt.ID, err)
+			}
+		}
+		h.mu.Unlock()
+		h.reply(c, &Envelope{Type: frameResponded, ID: env.ID, TaskID: env.TaskID, Duplicate: duplicate})
+	}()
+}
+
+func forbidden(topic string) *Envelope {
+	return &Envelope{Type: frameError, Topic: topic, Error: "not allowed to use topic " + topic}
+}
+
+// reply sends a frame to one client.
+func (h *Hub) reply(c *client, env *Envelope) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.push(c, encode(env))
+}
+
+// push hands a frame to a client's writer, dropping the client if it cannot keep up. The
+// caller must hold h.mu.
+func (h *Hub) push(c *client, frame []byte) bool {
+	if c.closed {
+		return false
+	}
+	select {
+	case c.send <- frame:
+		return true
+	default:
+		log.Printf("Client %s is too slow, disconnecting it", c.conn.RemoteAddr())
+		h.drop(c)
+		return false
+	}
+}
+
+// handle processes one frame from a client.
+func (h *Hub) handle(c *client, data []byte) {
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		h.reply(c, &Envelope{Type: frameError, Error: "frames must be JSON envelopes: " + err.Error()})
+		return
+	}
+	switch env.Type {
+	case frameSubscribe:
+		if len(env.Topics) == 0 {
+			h.reply(c, &Envelope{Type: frameError, Error: "subscribe needs topics"})
+			return
+		}
+		h.subscribe(c, env.Topics)
+	case frameUnsubscribe:
+		h.unsubscribe(c, env.Topics)
+		h.reply(c, &Envelope{Type: frameUnsubscribed, Topics: env.Topics})
+	case framePublish:
+		if env.Topic == "" {
+			h.reply(c, &Envelope{Type: frameError, ID: env.ID, Error: "publish needs a topic"})
+			return
+		}
+		if !c.identity.allowed(env.Topic) {
+			h.reply(c, forbidden(env.Topic))
+			return
+		}
+		if env.ID == "" {
+			env.ID = newMessageID()
+		}
+		m := &queuedMessage{Topic: env.Topic, ID: env.ID, Payload: env.Payload, PublishedAt: time.Now(), PublishedBy: c.identity.Operator}
+		h.reply(c, h.publish(c, m))
+	case frameAck:
+		h.reply(c, h.ack(c, env.ID))
+	case frameRespond:
+		h.respond(c, &env)
+	case frameHistory:
+		if env.Topic == "" {
+			h.reply(c, &Envelope{Type: frameError, Error: "history needs a topic"})
+			return
+		}
+		if !c.identity.allowed(env.Topic) {
+			h.reply(c, forbidden(env.Topic))
+			return
+		}
+		h.reply(c, h.history(env.Topic, env.Limit))
+	default:
+		h.reply(c, &Envelope{Type: frameError, ID: env.ID, Error: "unknown frame type: " + env.Type})
+	}
+}
+
+// readPump reads frames from the client until it disconnects.
+func (c *client) readPump() {
+	defer c.hub.unregister(c)
+	c.conn.SetReadLimit(maxFrameSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Printf("Unexpected close error: %v", err)
+			}
+			return
+		}
+		c.hub.handle(c, data)
+	}
+}
+
+// writePump writes queued frames and keep-alive pings until the client is unregistered.
+func (c *client) writePump() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+	for {
+		select {
+		case frame, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, frame); err != nil {
+				log.Printf("Error writing message: %v", err)
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// newMessageID returns a random message ID.
+func newMessageID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func startTestBridge(t *testing.T) string {
+	hub := newHub(&queue{historyLimit: 10, byID: make(map[string]*queuedMessage)}, nil)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveWs(hub, nil, w, r)
+	}))
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+}
+
+// dial connects to the bridge, waiting for the subscription to any topics in the URL.
+func dial(t *testing.T, url string) *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("failed to dial the bridge: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	if strings.Contains(url, "topics=") {
+		if env := receive(t, conn); env.Type != frameSubscribed {
+			t.Fatalf("expected the subscription to be confirmed, got %+v", env)
+		}
+	}
+	return conn
+}
+
+func send(t *testing.T, conn *websocket.Conn, env Envelope) {
+	if err := conn.WriteJSON(env); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func receive(t *testing.T, conn *websocket.Conn) Envelope {
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var env Envelope
+	if err := conn.ReadJSON(&env); err != nil {
+		t.Fatalf("failed to read a frame: %v", err)
+	}
+	return env
+}
+
+func TestTopicRouting(t *testing.T) {
+	url := startTestBridge(t)
+	ops := dial(t, url+"?topics=team:ops")
+	support := dial(t, url)
+	send(t, support, Envelope{Type: frameSubscribe, Topics: []string{"team:support", "human_intervention_required"}})
+	if env := receive(t, support); env.Type != frameSubscribed || len(env.Topics) != 2 {
+		t.Fatalf("expected the subscription to be confirmed, got %+v", env)
+	}
+
+	// A publisher subscribed to the topic itself does not get its own message back.
+	publisher := dial(t, url+"?topics=team:ops")
+	send(t, publisher, Envelope{Type: framePublish, Topic: "team:ops", Payload: json.RawMessage(`{"task_id":"t1"}`)})
+	ack := receive(t, publisher)
+	if ack.Type != framePublished || ack.ID == "" || ack.Delivered == nil || *ack.Delivered != 1 {
+		t.Fatalf("expected the message to reach one subscriber, got %+v", ack)
+	}
+	msg := receive(t, ops)
+	if msg.Type != frameMessage || msg.Topic != "team:ops" || msg.ID != ack.ID || string(msg.Payload) != `{"task_id":"t1"}` {
+		t.Errorf("unexpected delivery: %+v", msg)
+	}
+	if msg.PublishedAt == nil || msg.DeliveredAt == nil || msg.DeliveredAt.Before(*msg.PublishedAt) {
+		t.Errorf("expected publish and delivery timestamps, got %+v", msg)
+	}
+
+	// Support only sees its own topics.
+	send(t, publisher, Envelope{Type: framePublish, Topic: "team:support", ID: "m2", Payload: json.RawMessage(`2`)})
+	if ack := receive(t, publisher); ack.ID != "m2" || *ack.Delivered != 1 {
+		t.Fatalf("expected the publisher's ID to be kept, got %+v", ack)
+	}
+	if msg := receive(t, support); msg.ID != "m2" {
+		t.Errorf("expected support to get only its topic's message, got %+v", msg)
+	}
+
+	// A client stops hearing a topic after unsubscribing from it.
+	send(t, ops, Envelope{Type: frameUnsubscribe, Topics: []string{"team:ops"}})
+	if env := receive(t, ops); env.Type != frameUnsubscribed {
+		t.Fatalf("expected the unsubscription to be confirmed, got %+v", env)
+	}
+	send(t, support, Envelope{Type: framePublish, Topic: "team:ops", Payload: json.RawMessage(`3`)})
+	if ack := receive(t, support); *ack.Delivered != 1 {
+		t.Errorf("expected only the publisher to be left on the topic, got %+v", ack)
+	}
+	if msg := receive(t, publisher); msg.Type != frameMessage || string(msg.Payload) != "3" {
+		t.Errorf("unexpected delivery: %+v", msg)
+	}
+}
+
+func TestInvalidFrames(t *testing.T) {
+	conn := dial(t, startTestBridge(t))
+	for _, frame := range []string{`not json`, `{"type":"publish","payload":{}}`, `{"type":"subscribe"}`, `{"type":"shout"}`} {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(frame)); err != nil {
+			t.Fatal(err)
+		}
+		if env := receive(t, conn); env.Type != frameError || env.Error == "" {
+			t.Errorf("expected an error for %s, got %+v", frame, env)
+		}
+	}
+}
+
+func TestRedeliveryAndAck(t *testing.T) {
+	url := startTestBridge(t)
+	publisher := dial(t, url)
+	send(t, publisher, Envelope{Type: framePublish, Topic: "approvals", ID: "m1", Payload: json.RawMessage(`{"task_id":"t1"}`)})
+	if ack := receive(t, publisher); *ack.Delivered != 0 {
+		t.Fatalf("expected nobody to be listening yet, got %+v", ack)
+	}
+	// Publishing the same ID again does not queue a second copy.
+	send(t, publisher, Envelope{Type: framePublish, Topic: "approvals", ID: "m1", Payload: json.RawMessage(`{"task_id":"t1"}`)})
+	receive(t, publisher)
+
+	// An operator who connects later still gets the message, until someone acknowledges it.
+	alice := dial(t, url+"?topics=approvals")
+	msg := receive(t, alice)
+	if msg.ID != "m1" || !msg.Redelivered || msg.AckedAt != nil {
+		t.Fatalf("expected the queued message to be redelivered, got %+v", msg)
+	}
+	send(t, publisher, Envelope{Type: frameAck, ID: "m1"})
+	if env := receive(t, publisher); env.Type != frameError {
+		t.Errorf("expected a client not subscribed to the topic to be refused, got %+v", env)
+	}
+	send(t, alice, Envelope{Type: frameAck, ID: "m1"})
+	if env := receive(t, alice); env.Type != frameAcked || env.AckedAt == nil {
+		t.Fatalf("expected the acknowledgement to be confirmed, got %+v", env)
+	}
+	bob := dial(t, url+"?topics=approvals")
+	send(t, bob, Envelope{Type: frameHistory, Topic: "approvals"})
+	history := receive(t, bob)
+	if history.Type != frameHistory || len(history.Messages) != 1 || history.Messages[0].ID != "m1" || history.Messages[0].AckedAt == nil {
+		t.Errorf("expected bob to get only the acknowledged message in the history, got %+v", history)
+	}
+}
+
+func TestRespondRefusesSpoofedPrompt(t *testing.T) {
+	f, client := testForwarder()
+	hub := newHub(&queue{historyLimit: 10, byID: make(map[string]*queuedMessage)}, f)
+	auth := testAuthenticator()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveWs(hub, auth, w, r)
+	}))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+
+	tool := dial(t, url+"?token=tool-token")
+	send(t, tool, Envelope{Type: framePublish, Topic: "team:support", ID: "m1", Payload: json.RawMessage(`{"task_id":"t1"}`)})
+	receive(t, tool)
+
+	// Alice copies the support team's prompt onto her own topic to answer it.
+	alice := dial(t, url+"?token=alice-token&topics=team:ops")
+	send(t, alice, Envelope{Type: framePublish, Topic: "team:ops", ID: "fake", Payload: json.RawMessage(`{"task_id":"t1"}`)})
+	if ack := receive(t, alice); ack.Type != framePublished {
+		t.Fatalf("expected alice to be allowed to publish on her topic, got %+v", ack)
+	}
+	send(t, alice, Envelope{Type: frameRespond, ID: "r1", TaskID: "t1", Response: json.RawMessage(`true`)})
+	if env := receive(t, alice); env.Type != frameError || env.Topic != "team:support" {
+		t.Errorf("expected alice to be refused the support task, got %+v", env)
+	}
+	if client.calls() != 0 {
+		t.Errorf("expected the spoofed answer not to be forwarded, got %d calls", client.calls())
+	}
+}
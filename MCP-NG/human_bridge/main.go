@@ -1,110 +1,82 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"net/http"
-	"sync"
+	"strings"
 
 	"github.com/gorilla/websocket"
 )
 
-// Hub maintains the set of active clients and broadcasts messages to the clients.
-type Hub struct {
-	clients    map[*websocket.Conn]bool
-	broadcast  chan []byte
-	register   chan *websocket.Conn
-	unregister chan *websocket.Conn
-	mu         sync.Mutex
-}
-
-func newHub() *Hub {
-	return &Hub{
-		broadcast:  make(chan []byte),
-		register:   make(chan *websocket.Conn),
-		unregister: make(chan *websocket.Conn),
-		clients:    make(map[*websocket.Conn]bool),
-	}
-}
-
-func (h *Hub) run() {
-	for {
-		select {
-		case client := <-h.register:
-			h.mu.Lock()
-			h.clients[client] = true
-			h.mu.Unlock()
-			log.Println("Client registered")
-		case client := <-h.unregister:
-			h.mu.Lock()
-			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				client.Close()
-				log.Println("Client unregistered")
-			}
-			h.mu.Unlock()
-		case message := <-h.broadcast:
-			h.mu.Lock()
-			log.Printf("Broadcasting message to %d clients", len(h.clients))
-			for client := range h.clients {
-				err := client.WriteMessage(websocket.TextMessage, message)
-				if err != nil {
-					log.Printf("Error writing message: %v", err)
-					// Assume client is disconnected, schedule for unregister
-					go func(c *websocket.Conn) {
-						h.unregister <- c
-					}(client)
-				}
-			}
-			h.mu.Unlock()
-		}
+// serveWs authenticates a connection, upgrades it and serves the bridge protocol on it.
+// Clients may subscribe right away with a comma-separated "topics" query parameter, which
+// works like a subscribe frame.
+func serveWs(hub *Hub, auth *authenticator, w http.ResponseWriter, r *http.Request) {
+	id, err := auth.authenticate(r)
+	if err != nil {
+		log.Printf("Rejected connection from %s: %v", r.RemoteAddr, err)
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
 	}
-}
-
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool {
-		// Allow all connections for this simple bridge
-		return true
-	},
-}
-
-func serveWs(hub *Hub, w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{CheckOrigin: auth.checkOrigin}
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Println("Upgrade error:", err)
 		return
 	}
-	hub.register <- conn
+	c := hub.register(conn, id)
+	if topics := splitTopics(r.URL.Query().Get("topics")); len(topics) > 0 {
+		hub.subscribe(c, topics)
+	}
+	go c.writePump()
+	go c.readPump()
+}
 
-	// This goroutine handles messages from this specific client
-	// and puts them on the broadcast channel.
-	go func() {
-		defer func() {
-			hub.unregister <- conn
-		}()
-		for {
-			_, message, err := conn.ReadMessage()
-			if err != nil {
-				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-					log.Printf("Unexpected close error: %v", err)
-				}
-				break
-			}
-			hub.broadcast <- message
+func splitTopics(s string) []string {
+	var topics []string
+	for _, topic := range strings.Split(s, ",") {
+		if topic = strings.TrimSpace(topic); topic != "" {
+			topics = append(topics, topic)
 		}
-	}()
+	}
+	return topics
 }
 
 func main() {
-	hub := newHub()
-	go hub.run()
+	addr := flag.String("addr", ":8080", "address to listen on")
+	queueFile := flag.String("queue-file", "human_bridge_queue.json", "file that keeps unacknowledged messages across restarts; empty keeps them in memory only")
+	historyLimit := flag.Int("history", 1000, "number of acknowledged messages to keep for history requests")
+	serverAddr := flag.String("server", "localhost:8090", "gRPC address of the MCP server that operators' responses are delivered to; empty disables delivery")
+	authFile := flag.String("auth-file", "", "JSON file with the operators' tokens and topics; empty accepts anonymous connections")
+	flag.Parse()
+
+	auth, err := loadAuth(*authFile)
+	if err != nil {
+		log.Fatal("Failed to load the auth file: ", err)
+	}
+	if auth == nil {
+		log.Println("No auth file given, accepting anonymous connections")
+	}
+
+	q, err := openQueue(*queueFile, *historyLimit)
+	if err != nil {
+		log.Fatal("Failed to open the message queue: ", err)
+	}
+	f, err := dialForwarder(*serverAddr)
+	if err != nil {
+		log.Fatal("Failed to connect to the MCP server: ", err)
+	}
+	hub := newHub(q, f)
 
 	http.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
-		serveWs(hub, w, r)
+		serveWs(hub, auth, w, r)
 	})
 
-	log.Println("WebSocket Bridge Server starting on :8080")
-	err := http.ListenAndServe(":8080", nil)
+	log.Printf("WebSocket Bridge Server starting on %s", *addr)
+	err = http.ListenAndServe(*addr, nil)
 	if err != nil {
 		log.Fatal("ListenAndServe: ", err)
 	}
-}
\ No newline at end of file
+}
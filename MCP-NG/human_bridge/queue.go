@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// queuedMessage is a published message the bridge keeps until an operator acknowledges it,
+// and afterwards as history.
+type queuedMessage struct {
+	Topic       string          `json:"topic"`
+	ID          string          `json:"id"`
+	Payload     json.RawMessage `json:"payload,omitempty"`
+	PublishedAt time.Time       `json:"published_at"`
+	PublishedBy string          `json:"published_by,omitempty"`
+	AckedAt     *time.Time      `json:"acked_at,omitempty"`
+	AckedBy     string          `json:"acked_by,omitempty"`
+}
+
+func (m *queuedMessage) envelope() *Envelope {
+	published := m.PublishedAt
+	return &Envelope{Type: frameMessage, Topic: m.Topic, ID: m.ID, From: m.PublishedBy, Payload: m.Payload, PublishedAt: &published, AckedAt: m.AckedAt, AckedBy: m.AckedBy}
+}
+
+// queue holds published messages in publish order. Messages stay until acknowledged; of the
+// acknowledged ones only the newest historyLimit are kept. With a path, the queue is saved
+// to that file after every change and survives restarts. It is guarded by Hub.mu.
+type queue struct {
+	path         string
+	historyLimit int
+	messages     []*queuedMessage
+	byID         map[string]*queuedMessage
+}
+
+// openQueue loads the queue saved at path, or starts an in-memory queue if path is empty.
+func openQueue(path string, historyLimit int) (*queue, error) {
+	q := &queue{path: path, historyLimit: historyLimit, byID: make(map[string]*queuedMessage)}
+	if path == "" {
+		return q, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &q.messages); err != nil {
+		return nil, fmt.Errorf("failed to parse queue file %s: %w", path, err)
+	}
+	for _, m := range q.messages {
+		q.byID[m.ID] = m
+	}
+	q.trim()
+	return q, nil
+}
+
+// add queues a message. It reports false, without saving anything, if a message with the
+// same ID was already published, so a publisher can safely retry.
+func (q *queue) add(m *queuedMessage) (*queuedMessage, bool, error) {
+	if existing := q.byID[m.ID]; existing != nil {
+		return existing, false, nil
+	}
+	q.messages = append(q.messages, m)
+	q.byID[m.ID] = m
+	q.trim()
+	if err := q.save(); err != nil {
+		// Trimming never drops a pending message, so m is still the last one.
+		q.messages = q.messages[:len(q.messages)-1]
+		delete(q.byID, m.ID)
+		return m, false, err
+	}
+	return m, true, nil
+}
+
+// ack marks a message acknowledged by an operator. Acknowledging it again is a no-op.
+func (q *queue) ack(id, operator string, now time.Time) (*queuedMessage, error) {
+	m := q.byID[id]
+	if m == nil {
+		return nil, fmt.Errorf("unknown message: %s", id)
+	}
+	if m.AckedAt != nil {
+		return m, nil
+	}
+	m.AckedAt, m.AckedBy = &now, operator
+	if err := q.save(); err != nil {
+		m.AckedAt, m.AckedBy = nil, ""
+		return nil, err
+	}
+	q.trim()
+	return m, nil
+}
+
+// pending returns the unacknowledged messages of a topic, oldest first.
+func (q *queue) pending(topic string) []*queuedMessage {
+	var out []*queuedMessage
+	for _, m := range q.messages {
+		if m.Topic == topic && m.AckedAt == nil {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// forTask returns the messages carrying the prompt of a human-input task, oldest first. Any
+// client allowed on a topic may publish there, so there can be more than one.
+func (q *queue) forTask(taskID string) []*queuedMessage {
+	var out []*queuedMessage
+	for _, m := range q.messages {
+		if payloadTaskID(m.Payload) == taskID {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// history returns up to limit of the newest messages of a topic, oldest first.
+func (q *queue) history(topic string, limit int) []*queuedMessage {
+	var out []*queuedMessage
+	for i := len(q.messages) - 1; i >= 0 && len(out) < limit; i-- {
+		if q.messages[i].Topic == topic {
+			out = append(out, q.messages[i])
+		}
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
+
+// trim drops the oldest acknowledged messages beyond the history limit.
+func (q *queue) trim() {
+	acked := 0
+	for _, m := range q.messages {
+		if m.AckedAt != nil {
+			acked++
+		}
+	}
+	if acked <= q.historyLimit {
+		return
+	}
+	kept := q.messages[:0]
+	for _, m := range q.messages {
+		if m.AckedAt != nil && acked > q.historyLimit {
+			acked--
+			delete(q.byID, m.ID)
+			continue
+		}
+		kept = append(kept, m)
+	}
+	q.messages = kept
+}
+
+// save writes the queue to its file, replacing the old one only once the new one is
+// complete.
+func (q *queue) save() error {
+	if q.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(q.messages)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(q.path), filepath.Base(q.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), q.path)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestQueuePersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	q, err := openQueue(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for _, id := range []string{"a", "b", "c", "d"} {
+		if _, added, err := q.add(&queuedMessage{Topic: "ops", ID: id, Payload: json.RawMessage(`{}`), PublishedAt: now}); err != nil || !added {
+			t.Fatalf("failed to add %s: %v", id, err)
+		}
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		if _, err := q.ack(id, "alice", now); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	q, err = openQueue(path, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending := q.pending("ops"); len(pending) != 1 || pending[0].ID != "d" {
+		t.Errorf("expected d to still be pending after reopening, got %v", pending)
+	}
+	// Only the newest two acknowledged messages are kept.
+	history := q.history("ops", 10)
+	if len(history) != 3 || history[0].ID != "b" || history[2].ID != "d" {
+		t.Errorf("expected the history b, c, d, got %v", history)
+	}
+	if _, err := q.ack("a", "alice", now); err == nil {
+		t.Error("expected the trimmed message to be gone")
+	}
+}
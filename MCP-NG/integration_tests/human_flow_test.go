@@ -81,7 +81,7 @@ type HumanInputToolServer struct {
 
 func (s *HumanInputToolServer) Run(ctx context.Context, in *mcp.ToolRunRequest) (*mcp.ToolRunResponse, error) {
 	prompt, _ := in.Arguments.Fields["prompt"].AsInterface().(string)
-	pub, err := human_input_broker.NewWebSocketPublisher(s.brokerAddress)
+	pub, err := human_input_broker.NewWebSocketPublisher(s.brokerAddress, "")
 	if err != nil { return nil, err }
 	defer pub.Close()
 
@@ -0,0 +1,353 @@
+// File: MCP-NG/server/cmd/server/agent.go
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	pb "mcp-ng/server/pkg/mcp"
+	"mcp-ng/server/pkg/telemetry"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Agent run statuses reported in RunAgentResponse.status.
+const (
+	agentCompleted           = "completed"
+	agentMaxStepsExceeded    = "max_steps_exceeded"
+	agentTokenBudgetExceeded = "token_budget_exceeded"
+	agentFailed              = "failed"
+)
+
+const (
+	defaultAgentMaxSteps = 10
+	defaultAgentTimeout  = 60 * time.Second
+	// agentAPIKeyEnv is read when the agent configuration has no api_key.
+	agentAPIKeyEnv = "OPENAI_API_KEY"
+)
+
+// agentConfig is the "agent" section of the server's config.json. It points RunAgent at any
+// OpenAI-compatible chat completions API (OpenAI, vLLM, Ollama, LM Studio, ...).
+type agentConfig struct {
+	BaseURL        string `json:"base_url"` // e.g. "https://api.openai.com/v1"; /chat/completions is appended
+	APIKey         string `json:"api_key"`  // Falls back to the OPENAI_API_KEY environment variable
+	Model          string `json:"model"`
+	MaxSteps       int    `json:"max_steps"`       // Default model round trips per run; 0 means 10
+	MaxTokens      int64  `json:"max_tokens"`      // Default token budget per run; 0 means unlimited
+	TimeoutSeconds int    `json:"timeout_seconds"` // Per model request; 0 means 60
+}
+
+// Chat completions wire types. Only the fields RunAgent uses are declared.
+type chatMessage struct {
+	Role       string         `json:"role"`
+	Content    string         `json:"content"`
+	ToolCalls  []chatToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string         `json:"tool_call_id,omitempty"`
+}
+
+type chatToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"` // JSON-encoded object
+	} `json:"function"`
+}
+
+type chatRequest struct {
+	Model     string                   `json:"model"`
+	Messages  []chatMessage            `json:"messages"`
+	Tools     []map[string]interface{} `json:"tools,omitempty"`
+	MaxTokens int64                    `json:"max_tokens,omitempty"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message      chatMessage `json:"message"`
+		FinishReason string      `json:"finish_reason"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int64 `json:"prompt_tokens"`
+		CompletionTokens int64 `json:"completion_tokens"`
+		TotalTokens      int64 `json:"total_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// chatClient calls an OpenAI-compatible chat completions endpoint.
+type chatClient struct {
+	url    string
+	apiKey string
+	http   *http.Client
+}
+
+func newChatClient(cfg agentConfig) *chatClient {
+	timeout := defaultAgentTimeout
+	if cfg.TimeoutSeconds > 0 {
+		timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+	apiKey := cfg.APIKey
+	if apiKey == "" {
+		apiKey = os.Getenv(agentAPIKeyEnv)
+	}
+	return &chatClient{
+		url:    strings.TrimSuffix(cfg.BaseURL, "/") + "/chat/completions",
+		apiKey: apiKey,
+		http:   telemetry.HTTPClient(timeout),
+	}
+}
+
+func (c *chatClient) complete(ctx context.Context, req *chatRequest) (*chatResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode chat request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create chat request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.http.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("chat request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chat response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("chat API error (status %d): %s", resp.StatusCode, data)
+	}
+
+	var out chatResponse
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode chat response: %w", err)
+	}
+	if out.Error != nil {
+		return nil, fmt.Errorf("chat API error: %s", out.Error.Message)
+	}
+	if len(out.Choices) == 0 {
+		return nil, errors.New("chat response has no choices")
+	}
+	return &out, nil
+}
+
+// agentTools returns the OpenAI tool definitions for the allowed tools, or for every healthy
+// tool if allowed is empty, together with a map from the names shown to the model back to
+// registry names.
+func (s *server) agentTools(allowed []string) ([]map[string]interface{}, map[string]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var descs []*pb.ToolDescription
+	if len(allowed) == 0 {
+		for _, tool := range s.tools {
+			if tool.available() {
+				descs = append(descs, tool.description)
+			}
+		}
+	}
+	for _, name := range allowed {
+		tool, ok := s.tools[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown tool %q in allowed_tools", name)
+		}
+		descs = append(descs, tool.description)
+	}
+	return formatTools(descs, toolFormatOpenAI)
+}
+
+// RunAgent drives a chat model through a tool-calling loop. Every model round trip counts as
+// one step; tool calls are dispatched through ExecuteTools so they get the same health checks,
+// caching and metrics as direct calls. Problems talking to the model end the run with status
+// "failed" and are reported in the response together with the transcript so far.
+func (s *server) RunAgent(ctx context.Context, in *pb.RunAgentRequest) (*pb.RunAgentResponse, error) {
+	logger.Info("Received request to run agent", "task_id", in.TaskId, "allowed_tools", in.AllowedTools)
+
+	cfg := s.config.Agent
+	if cfg.BaseURL == "" {
+		return nil, status.Error(codes.FailedPrecondition, "agent is not configured: set agent.base_url in config.json")
+	}
+	if in.UserMessage == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_message cannot be empty")
+	}
+	model := in.Model
+	if model == "" {
+		model = cfg.Model
+	}
+	if model == "" {
+		return nil, status.Error(codes.InvalidArgument, "model is required: set it in the request or in agent.model")
+	}
+	maxSteps := int(in.MaxSteps)
+	if maxSteps <= 0 {
+		maxSteps = cfg.MaxSteps
+	}
+	if maxSteps <= 0 {
+		maxSteps = defaultAgentMaxSteps
+	}
+	budget := in.MaxTokens
+	if budget <= 0 {
+		budget = cfg.MaxTokens
+	}
+
+	tools, toolNames, err := s.agentTools(in.AllowedTools)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	taskID := in.TaskId
+	if taskID == "" {
+		taskID = uuid.NewString()
+	}
+	resp := &pb.RunAgentResponse{TaskId: taskID, Usage: &pb.AgentUsage{}}
+	var messages []chatMessage
+	addMessage := func(msg chatMessage, calls []*pb.AgentToolCall) {
+		messages = append(messages, msg)
+		resp.Transcript = append(resp.Transcript, &pb.AgentMessage{
+			Role: msg.Role, Content: msg.Content, ToolCalls: calls, ToolCallId: msg.ToolCallID,
+		})
+	}
+	if in.SystemPrompt != "" {
+		addMessage(chatMessage{Role: "system", Content: in.SystemPrompt}, nil)
+	}
+	addMessage(chatMessage{Role: "user", Content: in.UserMessage}, nil)
+
+	client := newChatClient(cfg)
+	resp.Status = agentMaxStepsExceeded
+	for resp.Steps < int32(maxSteps) {
+		req := &chatRequest{Model: model, Messages: messages, Tools: tools}
+		if budget > 0 {
+			remaining := budget - resp.Usage.TotalTokens
+			if remaining <= 0 {
+				resp.Status = agentTokenBudgetExceeded
+				break
+			}
+			req.MaxTokens = remaining
+		}
+
+		resp.Steps++
+		completion, err := client.complete(ctx, req)
+		if err != nil {
+			logger.Error("Agent model call failed", "task_id", taskID, "step", resp.Steps, "error", err)
+			resp.Status = agentFailed
+			resp.Error = err.Error()
+			break
+		}
+		resp.Usage.PromptTokens += completion.Usage.PromptTokens
+		resp.Usage.CompletionTokens += completion.Usage.CompletionTokens
+		resp.Usage.TotalTokens += completion.Usage.TotalTokens
+
+		reply := completion.Choices[0].Message
+		reply.Role = "assistant"
+		if len(reply.ToolCalls) == 0 {
+			addMessage(reply, nil)
+			resp.Status = agentCompleted
+			resp.FinalAnswer = reply.Content
+			break
+		}
+
+		calls := s.runAgentToolCalls(ctx, taskID, reply.ToolCalls, toolNames)
+		addMessage(reply, calls)
+		for _, call := range calls {
+			addMessage(chatMessage{Role: "tool", Content: agentToolContent(call), ToolCallID: call.Id}, nil)
+		}
+	}
+
+	logger.Info("Agent run finished", "task_id", taskID, "status", resp.Status, "steps", resp.Steps, "total_tokens", resp.Usage.TotalTokens)
+	return resp, nil
+}
+
+// runAgentToolCalls executes the tool calls of one assistant message concurrently. toolNames
+// maps the names offered to the model to registry names; calls to other tools or with
+// malformed arguments are answered with an error instead of being dispatched, so the model
+// can correct itself.
+func (s *server) runAgentToolCalls(ctx context.Context, taskID string, toolCalls []chatToolCall, toolNames map[string]string) []*pb.AgentToolCall {
+	calls := make([]*pb.AgentToolCall, len(toolCalls))
+	batch := &pb.ExecuteToolsRequest{}
+	var dispatched []*pb.AgentToolCall
+	for i, tc := range toolCalls {
+		call := &pb.AgentToolCall{Id: tc.ID, ToolName: tc.Function.Name}
+		calls[i] = call
+		name, ok := toolNames[tc.Function.Name]
+		if !ok {
+			call.Error = fmt.Sprintf("tool %q is not available", tc.Function.Name)
+			continue
+		}
+		call.ToolName = name
+		args := map[string]interface{}{}
+		if strings.TrimSpace(tc.Function.Arguments) != "" {
+			if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
+				call.Error = fmt.Sprintf("arguments are not a valid JSON object: %v", err)
+				continue
+			}
+		}
+		argsStruct, err := structpb.NewStruct(args)
+		if err != nil {
+			call.Error = fmt.Sprintf("invalid arguments: %v", err)
+			continue
+		}
+		call.Arguments = argsStruct
+		batch.Calls = append(batch.Calls, &pb.ExecuteToolRequest{
+			TaskId:    taskID + "/" + tc.ID,
+			ToolName:  name,
+			Arguments: argsStruct,
+		})
+		dispatched = append(dispatched, call)
+	}
+	if len(batch.Calls) == 0 {
+		return calls
+	}
+
+	results, err := s.ExecuteTools(ctx, batch)
+	if err != nil {
+		for _, call := range dispatched {
+			call.Error = status.Convert(err).Message()
+		}
+		return calls
+	}
+	for i, result := range results.Results {
+		dispatched[i].Result = result.Result
+		dispatched[i].Error = result.Error
+		dispatched[i].ApprovalTaskId = result.ApprovalTaskId
+	}
+	return calls
+}
+
+// agentToolContent renders a tool call's outcome as the content of a "tool" message. A call
+// held back for approval is reported as such, so the model does not take it as having run.
+func agentToolContent(call *pb.AgentToolCall) string {
+	var v interface{} = map[string]interface{}{"error": call.Error}
+	switch {
+	case call.ApprovalTaskId != "":
+		v = map[string]interface{}{
+			"status":  "waiting_for_human",
+			"task_id": call.ApprovalTaskId,
+			"error":   fmt.Sprintf("the call has not run: it is waiting for human approval (task %s)", call.ApprovalTaskId),
+		}
+	case call.Error == "":
+		v = call.Result.AsMap()
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf(`{"error": %q}`, err.Error())
+	}
+	return string(data)
+}
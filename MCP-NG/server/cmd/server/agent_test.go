@@ -0,0 +1,192 @@
+// File: MCP-NG/server/cmd/server/agent_test.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pb "mcp-ng/server/pkg/mcp"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// mockChatServer serves /chat/completions by passing each decoded request to reply.
+func mockChatServer(t *testing.T, reply func(req chatRequest) chatResponse) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		var req chatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(reply(req))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// chatReply builds a single-choice response with the given message and token usage.
+func chatReply(msg chatMessage, tokens int64) chatResponse {
+	var resp chatResponse
+	resp.Choices = make([]struct {
+		Message      chatMessage `json:"message"`
+		FinishReason string      `json:"finish_reason"`
+	}, 1)
+	resp.Choices[0].Message = msg
+	resp.Usage.TotalTokens = tokens
+	return resp
+}
+
+func toolCallMessage(id, name, args string) chatMessage {
+	call := chatToolCall{ID: id, Type: "function"}
+	call.Function.Name = name
+	call.Function.Arguments = args
+	return chatMessage{Role: "assistant", ToolCalls: []chatToolCall{call}}
+}
+
+func newAgentTestServer(baseURL string) *server {
+	s := newTestServerWithTools(map[string]*fakeToolClient{
+		"calculator": {run: func(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunResponse, error) {
+			return &pb.ToolRunResponse{Result: structpb.NewNumberValue(4)}, nil
+		}},
+		"file_writer": {run: func(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunResponse, error) {
+			return &pb.ToolRunResponse{Result: structpb.NewStringValue("written")}, nil
+		}},
+	})
+	s.config.Agent = agentConfig{BaseURL: baseURL + "/v1", Model: "test-model"}
+	return s
+}
+
+func TestRunAgentToolLoop(t *testing.T) {
+	srv := mockChatServer(t, func(req chatRequest) chatResponse {
+		if len(req.Tools) != 1 || req.Tools[0]["function"].(map[string]interface{})["name"] != "calculator" {
+			t.Errorf("expected only the allowed calculator tool, got %+v", req.Tools)
+		}
+		last := req.Messages[len(req.Messages)-1]
+		switch last.Role {
+		case "user":
+			resp := chatReply(toolCallMessage("call_1", "calculator", `{"expression": "2 + 2"}`), 30)
+			resp.Choices[0].Message.ToolCalls = append(resp.Choices[0].Message.ToolCalls,
+				toolCallMessage("call_2", "file_writer", `{}`).ToolCalls...)
+			return resp
+		default:
+			if !strings.Contains(req.Messages[len(req.Messages)-2].Content, `"result":4`) {
+				t.Errorf("expected the calculator result to be sent to the model, got %+v", req.Messages)
+			}
+			if !strings.Contains(last.Content, "not available") {
+				t.Errorf("expected the disallowed call to be rejected, got %q", last.Content)
+			}
+			return chatReply(chatMessage{Role: "assistant", Content: "The answer is 4."}, 20)
+		}
+	})
+	s := newAgentTestServer(srv.URL)
+
+	resp, err := s.RunAgent(context.Background(), &pb.RunAgentRequest{
+		SystemPrompt: "You are a calculator.",
+		UserMessage:  "What is 2 + 2?",
+		AllowedTools: []string{"calculator"},
+	})
+	if err != nil {
+		t.Fatalf("RunAgent failed: %v", err)
+	}
+	if resp.Status != agentCompleted || resp.FinalAnswer != "The answer is 4." {
+		t.Fatalf("unexpected outcome: %s %q (%s)", resp.Status, resp.FinalAnswer, resp.Error)
+	}
+	if resp.Steps != 2 || resp.Usage.TotalTokens != 50 {
+		t.Errorf("expected 2 steps and 50 tokens, got %d and %d", resp.Steps, resp.Usage.TotalTokens)
+	}
+
+	var roles []string
+	for _, msg := range resp.Transcript {
+		roles = append(roles, msg.Role)
+	}
+	if got := strings.Join(roles, ","); got != "system,user,assistant,tool,tool,assistant" {
+		t.Errorf("unexpected transcript roles: %s", got)
+	}
+	calls := resp.Transcript[2].ToolCalls
+	if calls[0].Result.Fields["result"].GetNumberValue() != 4 || calls[1].Error == "" {
+		t.Errorf("unexpected tool calls: %v", calls)
+	}
+}
+
+func TestRunAgentReportsGatedCalls(t *testing.T) {
+	srv := mockChatServer(t, func(req chatRequest) chatResponse {
+		last := req.Messages[len(req.Messages)-1]
+		if last.Role == "user" {
+			return chatReply(toolCallMessage("call_1", "file_writer", `{"path": "/etc/hosts"}`), 10)
+		}
+		if !strings.Contains(last.Content, "waiting for human approval") || !strings.Contains(last.Content, `"status":"waiting_for_human"`) {
+			t.Errorf("expected the model to be told the call waits for approval, got %q", last.Content)
+		}
+		return chatReply(chatMessage{Role: "assistant", Content: "Waiting for an operator."}, 10)
+	})
+	s := newAgentTestServer(srv.URL)
+	s.tools["file_writer"].config.RequiresApproval = &approvalConfig{enabled: true}
+
+	resp, err := s.RunAgent(context.Background(), &pb.RunAgentRequest{UserMessage: "Write the hosts file."})
+	if err != nil {
+		t.Fatalf("RunAgent failed: %v", err)
+	}
+	call := resp.Transcript[len(resp.Transcript)-3].ToolCalls[0]
+	if call.ApprovalTaskId == "" || call.Result != nil || call.Error != "" {
+		t.Errorf("expected the call to carry its approval task, got %v", call)
+	}
+}
+
+func TestRunAgentLimits(t *testing.T) {
+	srv := mockChatServer(t, func(req chatRequest) chatResponse {
+		return chatReply(toolCallMessage("call", "calculator", `{"expression": "1"}`), 40)
+	})
+	s := newAgentTestServer(srv.URL)
+
+	resp, err := s.RunAgent(context.Background(), &pb.RunAgentRequest{UserMessage: "loop", MaxSteps: 3})
+	if err != nil {
+		t.Fatalf("RunAgent failed: %v", err)
+	}
+	if resp.Status != agentMaxStepsExceeded || resp.Steps != 3 {
+		t.Errorf("expected to stop after 3 steps, got %s after %d", resp.Status, resp.Steps)
+	}
+
+	resp, err = s.RunAgent(context.Background(), &pb.RunAgentRequest{UserMessage: "loop", MaxTokens: 100})
+	if err != nil {
+		t.Fatalf("RunAgent failed: %v", err)
+	}
+	if resp.Status != agentTokenBudgetExceeded || resp.Steps != 3 {
+		t.Errorf("expected the token budget to stop the run after 3 steps, got %s after %d", resp.Status, resp.Steps)
+	}
+}
+
+func TestRunAgentErrors(t *testing.T) {
+	s := newAgentTestServer("")
+	s.config.Agent.BaseURL = ""
+	if _, err := s.RunAgent(context.Background(), &pb.RunAgentRequest{UserMessage: "hi"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition without a configured model endpoint, got %v", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error": {"message": "rate limited"}}`, http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+	s = newAgentTestServer(srv.URL)
+
+	if _, err := s.RunAgent(context.Background(), &pb.RunAgentRequest{UserMessage: "hi", AllowedTools: []string{"missing"}}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for an unknown tool, got %v", err)
+	}
+	resp, err := s.RunAgent(context.Background(), &pb.RunAgentRequest{UserMessage: "hi"})
+	if err != nil {
+		t.Fatalf("RunAgent failed: %v", err)
+	}
+	if resp.Status != agentFailed || !strings.Contains(resp.Error, "429") {
+		t.Errorf("expected the model error to be reported, got %s: %s", resp.Status, resp.Error)
+	}
+}
@@ -0,0 +1,296 @@
+// File: MCP-NG/server/cmd/server/approval.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"time"
+
+	"mcp-ng/server/pkg/humanform"
+	pb "mcp-ng/server/pkg/mcp"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// approvalCreator is the creator of the human-input tasks that gate tool calls.
+const approvalCreator = "mcp-server"
+
+// defaultApprovedCallTimeout bounds an approved call of a tool without a call timeout, so a
+// hung tool cannot leave the approval running forever.
+const defaultApprovedCallTimeout = 10 * time.Minute
+
+// Statuses of a tool call waiting for approval.
+const (
+	approvalWaiting   = "waiting"
+	approvalRejected  = "rejected"
+	approvalRunning   = "running"
+	approvalSucceeded = "succeeded"
+	approvalFailed    = "failed"
+)
+
+// approvalRules decide which calls of a tool need an operator's approval.
+type approvalRules struct {
+	// When lists argument rules; a call needs approval if any of them matches. Without
+	// rules, every call does.
+	When []approvalRule `json:"when"`
+	// TTLSeconds is how long the call waits for an operator before the approval task
+	// expires. Defaults to human_input.pending_ttl_seconds.
+	TTLSeconds int `json:"ttl_seconds"`
+}
+
+// approvalRule matches a call by one of its arguments.
+type approvalRule struct {
+	// Argument names the argument to look at; empty matches against all the arguments as
+	// a JSON object.
+	Argument string `json:"argument"`
+	// Pattern is a regular expression matched against the argument, or against its JSON
+	// encoding if it is not a string. Empty matches whenever the argument is present.
+	Pattern string `json:"pattern"`
+
+	re *regexp.Regexp
+}
+
+// UnmarshalJSON compiles the pattern, so an invalid one fails loading the config.
+func (r *approvalRule) UnmarshalJSON(data []byte) error {
+	type plain approvalRule
+	if err := json.Unmarshal(data, (*plain)(r)); err != nil {
+		return err
+	}
+	re, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid approval pattern %q: %v", r.Pattern, err)
+	}
+	r.re = re
+	return nil
+}
+
+// approvalConfig is the "requires_approval" setting of a tool's config.json: true for
+// every call, or an object with rules.
+type approvalConfig struct {
+	approvalRules
+	enabled bool
+}
+
+func (c *approvalConfig) UnmarshalJSON(data []byte) error {
+	var enabled bool
+	if err := json.Unmarshal(data, &enabled); err == nil {
+		c.enabled = enabled
+		return nil
+	}
+	c.enabled = true
+	return json.Unmarshal(data, &c.approvalRules)
+}
+
+// approvalPolicy is an entry of "approval_policies" in the server's config.json, requiring
+// approval for calls of several tools at once.
+type approvalPolicy struct {
+	// Tools are tool names or path.Match patterns such as "db_*".
+	Tools []string `json:"tools"`
+	approvalRules
+}
+
+// matches reports whether a call with the given arguments needs approval.
+func (r *approvalRules) matches(args *structpb.Struct) bool {
+	if len(r.When) == 0 {
+		return true
+	}
+	for _, rule := range r.When {
+		var value *structpb.Value
+		if rule.Argument == "" {
+			value = structpb.NewStructValue(args)
+		} else if value = args.GetFields()[rule.Argument]; value == nil {
+			continue
+		}
+		text, ok := value.GetKind().(*structpb.Value_StringValue)
+		subject := ""
+		if ok {
+			subject = text.StringValue
+		} else {
+			data, _ := json.Marshal(value.AsInterface())
+			subject = string(data)
+		}
+		if rule.re == nil || rule.re.MatchString(subject) {
+			return true
+		}
+	}
+	return false
+}
+
+// approvalFor returns the rules that require approval for a call, or nil if it can run
+// right away.
+func (s *server) approvalFor(tool *toolClient, in *pb.ExecuteToolRequest) *approvalRules {
+	if c := tool.config.RequiresApproval; c != nil && c.enabled && c.matches(in.Arguments) {
+		return &c.approvalRules
+	}
+	for i := range s.config.ApprovalPolicies {
+		p := &s.config.ApprovalPolicies[i]
+		for _, pattern := range p.Tools {
+			if ok, _ := path.Match(pattern, in.ToolName); ok && p.matches(in.Arguments) {
+				return &p.approvalRules
+			}
+		}
+	}
+	return nil
+}
+
+// toolApproval is the tool call held back by an approval task.
+type toolApproval struct {
+	ToolName   string          `json:"tool_name"`
+	Arguments  json.RawMessage `json:"arguments"` // The arguments Struct in protojson
+	CallTaskID string          `json:"call_task_id"`
+	Cache      string          `json:"cache,omitempty"`
+	Status     string          `json:"status"`
+	Result     json.RawMessage `json:"result,omitempty"` // The result Struct in protojson
+	Error      string          `json:"error,omitempty"`
+}
+
+func (a *toolApproval) proto() *pb.ToolApproval {
+	if a == nil {
+		return nil
+	}
+	p := &pb.ToolApproval{ToolName: a.ToolName, CallTaskId: a.CallTaskID, Status: a.Status, Error: a.Error}
+	p.Arguments = &structpb.Struct{}
+	protojson.Unmarshal(a.Arguments, p.Arguments)
+	if a.Result != nil {
+		p.Result = &structpb.Struct{}
+		protojson.Unmarshal(a.Result, p.Result)
+	}
+	return p
+}
+
+// requestApproval holds a call back and asks an operator to approve it. The call's result
+// tells the caller which task to wait for.
+func (s *server) requestApproval(in *pb.ExecuteToolRequest, rules *approvalRules) (*pb.ExecuteToolResponse, error) {
+	args, err := protojson.Marshal(in.Arguments)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid arguments: %v", err)
+	}
+	if in.Arguments == nil {
+		args = []byte("{}")
+	}
+	task := &humanTask{
+		Prompt:  fmt.Sprintf("Approve running tool '%s' with arguments %s?", in.ToolName, args),
+		Creator: approvalCreator,
+		Form:    &humanform.Form{Type: humanform.TypeApproval, Title: "Approve " + in.ToolName},
+		Approval: &toolApproval{
+			ToolName:   in.ToolName,
+			Arguments:  args,
+			CallTaskID: in.TaskId,
+			Cache:      in.Cache,
+			Status:     approvalWaiting,
+		},
+	}
+	if err := s.registerHumanTask(task, secondsOr(rules.TTLSeconds, s.config.HumanInput.pendingTTL())); err != nil {
+		return nil, err
+	}
+	logger.Info("Tool call is waiting for approval", "tool", in.ToolName, "task_id", in.TaskId, "approval_task_id", task.TaskID)
+	return &pb.ExecuteToolResponse{
+		TaskId: in.TaskId,
+		Result: &structpb.Struct{Fields: map[string]*structpb.Value{
+			"status":    structpb.NewStringValue("waiting_for_human"),
+			"task_id":   structpb.NewStringValue(task.TaskID),
+			"tool_name": structpb.NewStringValue(in.ToolName),
+		}},
+	}, nil
+}
+
+// gatedBy returns the ID of the approval task a call's response says it is waiting for, or
+// "" if the response is the tool's result.
+func (s *server) gatedBy(resp *pb.ExecuteToolResponse) string {
+	fields := resp.GetResult().GetFields()
+	if fields["status"].GetStringValue() != "waiting_for_human" {
+		return ""
+	}
+	task, err := s.humanInputs.Get(fields["task_id"].GetStringValue())
+	if err != nil || task == nil || task.Approval == nil {
+		return "" // A human_input tool asking its own question
+	}
+	return task.TaskID
+}
+
+// decideApproval records the operator's decision on an approval task from the answer to
+// its approval form and reports whether the call may run. The caller must hold s.humanMu.
+func (s *server) decideApproval(task *humanTask, answer *structpb.Value) bool {
+	approved := answer.GetStructValue().GetFields()["approved"].GetBoolValue()
+	if approved {
+		task.Approval.Status = approvalRunning
+	} else {
+		task.Approval.Status = approvalRejected
+	}
+	logger.Info("Tool call approval decided", "tool", task.Approval.ToolName, "approval_task_id", task.TaskID, "approved", approved, "operator", task.AnsweredBy)
+	return approved
+}
+
+// runApprovedCall runs the call an operator approved and records its outcome on the task.
+func (s *server) runApprovedCall(taskID string) {
+	task, err := s.humanInputs.Get(taskID)
+	if err != nil || task == nil || task.Approval == nil {
+		logger.Error("Failed to load approved tool call", "approval_task_id", taskID, "error", err)
+		return
+	}
+	a := task.Approval
+	timeout := defaultApprovedCallTimeout
+	s.mu.RLock()
+	if tool := s.tools[a.ToolName]; tool != nil && s.callTimeout(tool) > 0 {
+		timeout = s.callTimeout(tool)
+	}
+	s.mu.RUnlock()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	in := &pb.ExecuteToolRequest{TaskId: a.CallTaskID, ToolName: a.ToolName, Arguments: &structpb.Struct{}, Cache: a.Cache}
+	var result []byte
+	if err = protojson.Unmarshal(a.Arguments, in.Arguments); err == nil {
+		var resp *pb.ExecuteToolResponse
+		if resp, err = s.executeTool(ctx, in, true); err == nil {
+			result, err = protojson.Marshal(resp.Result)
+		}
+	}
+
+	s.humanMu.Lock()
+	defer s.humanMu.Unlock()
+	// Re-read the task: it may have been purged while the call ran.
+	if task, _ = s.humanInputs.Get(taskID); task == nil || task.Approval == nil {
+		return
+	}
+	if err != nil {
+		task.Approval.Status = approvalFailed
+		task.Approval.Error = err.Error()
+	} else {
+		task.Approval.Status = approvalSucceeded
+		task.Approval.Result = result
+	}
+	if err := s.humanInputs.Put(task); err != nil {
+		logger.Error("Failed to store the outcome of an approved tool call", "approval_task_id", taskID, "error", err)
+		return
+	}
+	s.publishHumanEvent(humanEventExecuted, task, nil, time.Now())
+}
+
+// failInterruptedApprovals fails the approved calls that were still running when the server
+// stopped; whether they finished is unknown.
+func (s *server) failInterruptedApprovals() {
+	tasks, err := s.humanInputs.List(humanStatusCompleted)
+	if err != nil {
+		logger.Error("Failed to list approved tool calls", "error", err)
+		return
+	}
+	for _, task := range tasks {
+		if task.Approval == nil || task.Approval.Status != approvalRunning {
+			continue
+		}
+		task.Approval.Status = approvalFailed
+		task.Approval.Error = "the server stopped while the call was running"
+		if err := s.humanInputs.Put(task); err != nil {
+			logger.Error("Failed to fail an interrupted tool call", "approval_task_id", task.TaskID, "error", err)
+			continue
+		}
+		logger.Warn("Approved tool call was interrupted by a server restart", "tool", task.Approval.ToolName, "approval_task_id", task.TaskID)
+	}
+}
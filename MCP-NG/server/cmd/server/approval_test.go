@@ -0,0 +1,177 @@
+// File: MCP-NG/server/cmd/server/approval_test.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pb "mcp-ng/server/pkg/mcp"
+
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestApprovalConfig(t *testing.T) {
+	var cfg toolConfig
+	if err := json.Unmarshal([]byte(`{"requires_approval": true}`), &cfg); err != nil || !cfg.RequiresApproval.enabled {
+		t.Errorf("expected true to require approval for every call, got %+v, %v", cfg.RequiresApproval, err)
+	}
+	cfg = toolConfig{}
+	if err := json.Unmarshal([]byte(`{"requires_approval": false}`), &cfg); err != nil || cfg.RequiresApproval.enabled {
+		t.Errorf("expected false to disable approval, got %+v, %v", cfg.RequiresApproval, err)
+	}
+	cfg = toolConfig{}
+	err := json.Unmarshal([]byte(`{"requires_approval": {"when": [{"argument": "query", "pattern": "(?i)^\\s*(delete|drop)"}], "ttl_seconds": 600}}`), &cfg)
+	if err != nil || !cfg.RequiresApproval.enabled || cfg.RequiresApproval.TTLSeconds != 600 {
+		t.Fatalf("unexpected config: %+v, %v", cfg.RequiresApproval, err)
+	}
+	args := func(query string) *structpb.Struct {
+		s, _ := structpb.NewStruct(map[string]interface{}{"query": query})
+		return s
+	}
+	if !cfg.RequiresApproval.matches(args(" DELETE FROM users")) || cfg.RequiresApproval.matches(args("SELECT 1")) {
+		t.Error("expected only the DELETE query to need approval")
+	}
+	if err := json.Unmarshal([]byte(`{"requires_approval": {"when": [{"pattern": "("}]}}`), &toolConfig{}); err == nil {
+		t.Error("expected an invalid pattern to fail loading the config")
+	}
+}
+
+func TestApprovalPolicies(t *testing.T) {
+	s := newTestServerWithTools(map[string]*fakeToolClient{"db_querier": {}, "calculator": {}})
+	if err := json.Unmarshal([]byte(`{"approval_policies": [{"tools": ["db_*"], "when": [{"argument": "query", "pattern": "(?i)delete"}]}]}`), s.config); err != nil {
+		t.Fatal(err)
+	}
+	call := func(tool, query string) *pb.ExecuteToolRequest {
+		args, _ := structpb.NewStruct(map[string]interface{}{"query": query})
+		return &pb.ExecuteToolRequest{ToolName: tool, Arguments: args}
+	}
+	if s.approvalFor(s.tools["db_querier"], call("db_querier", "delete from t")) == nil {
+		t.Error("expected the policy to match the DELETE")
+	}
+	if s.approvalFor(s.tools["db_querier"], call("db_querier", "select 1")) != nil {
+		t.Error("expected the SELECT to run right away")
+	}
+	if s.approvalFor(s.tools["calculator"], call("calculator", "delete")) != nil {
+		t.Error("expected the policy to apply only to the matching tools")
+	}
+}
+
+func TestApprovalGate(t *testing.T) {
+	var calls atomic.Int32
+	s := newTestServerWithTools(map[string]*fakeToolClient{
+		"file_writer": {run: func(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunResponse, error) {
+			calls.Add(1)
+			return &pb.ToolRunResponse{Result: structpb.NewStringValue("wrote " + in.Arguments.Fields["path"].GetStringValue())}, nil
+		}},
+	})
+	s.tools["file_writer"].config.RequiresApproval = &approvalConfig{enabled: true}
+	ctx := context.Background()
+	args, _ := structpb.NewStruct(map[string]interface{}{"path": "/etc/hosts"})
+	execute := func() string {
+		resp, err := s.ExecuteTool(ctx, &pb.ExecuteToolRequest{TaskId: "call-1", ToolName: "file_writer", Arguments: args})
+		if err != nil {
+			t.Fatalf("ExecuteTool failed: %v", err)
+		}
+		fields := resp.Result.Fields
+		if fields["status"].GetStringValue() != "waiting_for_human" || fields["tool_name"].GetStringValue() != "file_writer" {
+			t.Fatalf("expected the call to wait for approval, got %v", resp.Result)
+		}
+		return fields["task_id"].GetStringValue()
+	}
+
+	// An approved call runs once the operator approves, and records who did.
+	taskID := execute()
+	if calls.Load() != 0 {
+		t.Fatal("expected the tool not to run before approval")
+	}
+	pending, _ := s.ListPendingHumanInputs(ctx, &pb.ListPendingHumanInputsRequest{Creator: approvalCreator})
+	if len(pending.Tasks) != 1 || pending.Tasks[0].Approval.GetToolName() != "file_writer" || pending.Tasks[0].Approval.Arguments.Fields["path"].GetStringValue() != "/etc/hosts" {
+		t.Fatalf("expected an approval task with the call, got %v", pending.Tasks)
+	}
+	approve, _ := structpb.NewValue(map[string]interface{}{"approved": true})
+	if _, err := s.ProvideHumanInput(ctx, &pb.ProvideHumanInputRequest{TaskId: taskID, Response: approve, Operator: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := s.WaitHumanInput(ctx, &pb.WaitHumanInputRequest{TaskId: taskID, TimeoutSeconds: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	approval := resp.Task.Approval
+	if approval.Status != approvalSucceeded || approval.Result.Fields["result"].GetStringValue() != "wrote /etc/hosts" || approval.CallTaskId != "call-1" {
+		t.Errorf("unexpected approval outcome: %v", approval)
+	}
+	if resp.Task.AnsweredBy != "alice" || calls.Load() != 1 {
+		t.Errorf("expected alice to be recorded and the tool to run once, got %q, %d calls", resp.Task.AnsweredBy, calls.Load())
+	}
+
+	// A rejected call never runs.
+	taskID = execute()
+	reject, _ := structpb.NewValue(map[string]interface{}{"approved": false, "comment": "Not that file"})
+	if _, err := s.ProvideHumanInput(ctx, &pb.ProvideHumanInputRequest{TaskId: taskID, Response: reject, Operator: "bob"}); err != nil {
+		t.Fatal(err)
+	}
+	resp, _ = s.WaitHumanInput(ctx, &pb.WaitHumanInputRequest{TaskId: taskID})
+	if resp.Task.Approval.Status != approvalRejected || calls.Load() != 1 {
+		t.Errorf("expected the call to be rejected without running, got %v, %d calls", resp.Task.Approval, calls.Load())
+	}
+}
+
+func TestApprovedCallTimesOut(t *testing.T) {
+	s := newTestServerWithTools(map[string]*fakeToolClient{
+		"file_writer": {run: func(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunResponse, error) {
+			<-ctx.Done()
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}},
+	})
+	s.tools["file_writer"].config.RequiresApproval = &approvalConfig{enabled: true}
+	s.tools["file_writer"].config.CallTimeoutSeconds = 1
+	ctx := context.Background()
+	resp, err := s.ExecuteTool(ctx, &pb.ExecuteToolRequest{ToolName: "file_writer"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	taskID := resp.Result.Fields["task_id"].GetStringValue()
+	approve, _ := structpb.NewValue(map[string]interface{}{"approved": true})
+	if _, err := s.ProvideHumanInput(ctx, &pb.ProvideHumanInputRequest{TaskId: taskID, Response: approve}); err != nil {
+		t.Fatal(err)
+	}
+	// The task settles when the operator answers; wait for the call to end.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		task, _ := s.humanInputs.Get(taskID)
+		if task.Approval.Status != approvalRunning {
+			if task.Approval.Status != approvalFailed || !strings.Contains(task.Approval.Error, "DeadlineExceeded") {
+				t.Errorf("expected the hung call to fail with a timeout, got %+v", task.Approval)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the hung call to time out")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestInterruptedApprovalsFail(t *testing.T) {
+	s := newTestServerWithTools(nil)
+	running := &humanTask{TaskID: "a1", Status: humanStatusCompleted, Approval: &toolApproval{ToolName: "file_writer", Status: approvalRunning}}
+	done := &humanTask{TaskID: "a2", Status: humanStatusCompleted, Approval: &toolApproval{ToolName: "file_writer", Status: approvalSucceeded}}
+	for _, task := range []*humanTask{running, done} {
+		if err := s.humanInputs.Put(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s.failInterruptedApprovals()
+	if task, _ := s.humanInputs.Get("a1"); task.Approval.Status != approvalFailed || task.Approval.Error == "" {
+		t.Errorf("expected the interrupted call to fail, got %+v", task.Approval)
+	}
+	if task, _ := s.humanInputs.Get("a2"); task.Approval.Status != approvalSucceeded {
+		t.Errorf("expected the finished call to be left alone, got %+v", task.Approval)
+	}
+}
@@ -0,0 +1,81 @@
+// File: MCP-NG/server/cmd/server/batch.go
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pb "mcp-ng/server/pkg/mcp"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxBatchCalls bounds the number of calls accepted in a single ExecuteTools request.
+const maxBatchCalls = 100
+
+// ExecuteTools runs several tool calls concurrently under a shared deadline.
+// Failures are reported per call; the RPC itself only fails for an invalid request.
+func (s *server) ExecuteTools(ctx context.Context, in *pb.ExecuteToolsRequest) (*pb.ExecuteToolsResponse, error) {
+	logger.Info("Received request to execute tool batch", "calls", len(in.Calls), "timeout_ms", in.TimeoutMs)
+
+	if len(in.Calls) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "calls cannot be empty")
+	}
+	if len(in.Calls) > maxBatchCalls {
+		return nil, status.Errorf(codes.InvalidArgument, "too many calls in batch: %d (max %d)", len(in.Calls), maxBatchCalls)
+	}
+
+	if in.TimeoutMs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(in.TimeoutMs)*time.Millisecond)
+		defer cancel()
+	}
+
+	concurrency := int(in.MaxConcurrency)
+	if concurrency <= 0 || concurrency > len(in.Calls) {
+		concurrency = len(in.Calls)
+	}
+	sem := make(chan struct{}, concurrency)
+
+	results := make([]*pb.ExecuteToolResult, len(in.Calls))
+	var wg sync.WaitGroup
+	for i, call := range in.Calls {
+		wg.Add(1)
+		go func(i int, call *pb.ExecuteToolRequest) {
+			defer wg.Done()
+			result := &pb.ExecuteToolResult{TaskId: call.TaskId, ToolName: call.ToolName}
+			results[i] = result
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				setBatchError(result, status.FromContextError(ctx.Err()).Err())
+				return
+			}
+
+			resp, err := s.ExecuteTool(ctx, call)
+			if err != nil {
+				setBatchError(result, err)
+				return
+			}
+			if result.ApprovalTaskId = s.gatedBy(resp); result.ApprovalTaskId != "" {
+				return
+			}
+			result.Result = resp.Result
+			result.Cached = resp.Cached
+		}(i, call)
+	}
+	wg.Wait()
+
+	return &pb.ExecuteToolsResponse{Results: results}, nil
+}
+
+// setBatchError records a call's failure using its gRPC status.
+func setBatchError(result *pb.ExecuteToolResult, err error) {
+	st := status.Convert(err)
+	result.ErrorCode = st.Code().String()
+	result.Error = st.Message()
+}
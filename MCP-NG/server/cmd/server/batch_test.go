@@ -0,0 +1,81 @@
+// File: MCP-NG/server/cmd/server/batch_test.go
+package main
+
+import (
+	"context"
+	"testing"
+
+	pb "mcp-ng/server/pkg/mcp"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestExecuteToolsPartialFailure(t *testing.T) {
+	s := newTestServerWithTools(map[string]*fakeToolClient{
+		"echo": {run: func(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunResponse, error) {
+			return &pb.ToolRunResponse{Result: structpb.NewStringValue(in.Arguments.Fields["text"].GetStringValue())}, nil
+		}},
+		"broken": {run: func(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunResponse, error) {
+			return &pb.ToolRunResponse{Error: "boom"}, nil
+		}},
+		"slow": {run: func(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunResponse, error) {
+			<-ctx.Done()
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}},
+	})
+
+	args, _ := structpb.NewStruct(map[string]interface{}{"text": "hi"})
+	resp, err := s.ExecuteTools(context.Background(), &pb.ExecuteToolsRequest{
+		TimeoutMs: 100,
+		Calls: []*pb.ExecuteToolRequest{
+			{TaskId: "1", ToolName: "echo", Arguments: args},
+			{TaskId: "2", ToolName: "broken"},
+			{TaskId: "3", ToolName: "slow"},
+			{TaskId: "4", ToolName: "missing"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteTools failed: %v", err)
+	}
+	if len(resp.Results) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(resp.Results))
+	}
+
+	if r := resp.Results[0]; r.TaskId != "1" || r.Error != "" || r.Result.Fields["result"].GetStringValue() != "hi" {
+		t.Errorf("unexpected result for echo: %v", r)
+	}
+	wantCodes := []codes.Code{codes.OK, codes.Aborted, codes.DeadlineExceeded, codes.NotFound}
+	for i, want := range wantCodes[1:] {
+		if got := resp.Results[i+1].ErrorCode; got != want.String() {
+			t.Errorf("call %d: expected error code %s, got %q (%s)", i+2, want, got, resp.Results[i+1].Error)
+		}
+	}
+}
+
+func TestExecuteToolsRejectsEmptyBatch(t *testing.T) {
+	s := newTestServerWithTools(nil)
+	_, err := s.ExecuteTools(context.Background(), &pb.ExecuteToolsRequest{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+}
+
+func TestExecuteToolsReportsGatedCalls(t *testing.T) {
+	s := newTestServerWithTools(map[string]*fakeToolClient{
+		"file_writer": {run: func(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunResponse, error) {
+			t.Error("expected the gated call not to run")
+			return &pb.ToolRunResponse{}, nil
+		}},
+	})
+	s.tools["file_writer"].config.RequiresApproval = &approvalConfig{enabled: true}
+	resp, err := s.ExecuteTools(context.Background(), &pb.ExecuteToolsRequest{Calls: []*pb.ExecuteToolRequest{{TaskId: "1", ToolName: "file_writer"}}})
+	if err != nil {
+		t.Fatalf("ExecuteTools failed: %v", err)
+	}
+	r := resp.Results[0]
+	if r.ApprovalTaskId == "" || r.Result != nil || r.Error != "" {
+		t.Errorf("expected the call to be reported as waiting for approval, got %v", r)
+	}
+}
@@ -0,0 +1,324 @@
+// File: MCP-NG/server/cmd/server/cache.go
+package main
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	pb "mcp-ng/server/pkg/mcp"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// cacheBypass is the ExecuteToolRequest.cache value that skips the cache lookup.
+const cacheBypass = "bypass"
+
+// Cache backends selectable in a tool's config.json.
+const (
+	cacheBackendMemory = "memory"
+	cacheBackendDisk   = "disk"
+)
+
+// cacheConfig is the optional "cache" section of a tool's config.json.
+// Caching is opt-in: a tool without this section, or with enabled=false, is never cached.
+type cacheConfig struct {
+	Enabled    bool   `json:"enabled"`
+	Backend    string `json:"backend"`     // "memory" (default) or "disk"
+	TTLSeconds int    `json:"ttl_seconds"` // 0 means entries never expire
+	MaxEntries int    `json:"max_entries"` // 0 means no limit
+	MaxBytes   int64  `json:"max_bytes"`   // 0 means no limit
+	Dir        string `json:"dir"`         // Disk backend only; relative paths are resolved against the project root
+}
+
+var mcpToolCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "mcp_tool_cache_requests_total",
+	Help: "Result cache lookups, by tool and result (hit or miss).",
+}, []string{"tool", "result"})
+
+func init() {
+	metricsRegistry.MustRegister(mcpToolCacheRequests)
+}
+
+// resultCache stores successful tool results keyed by tool name and canonical arguments.
+type resultCache interface {
+	Get(key string) (*structpb.Struct, bool)
+	Set(key string, result *structpb.Struct)
+}
+
+// newResultCache builds the cache described by cfg, or returns nil if caching is disabled.
+func newResultCache(toolName, projectRoot string, cfg *cacheConfig) (resultCache, error) {
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+	idx := newLRUIndex(time.Duration(cfg.TTLSeconds)*time.Second, cfg.MaxEntries, cfg.MaxBytes)
+	switch cfg.Backend {
+	case "", cacheBackendMemory:
+		return &memoryCache{index: idx}, nil
+	case cacheBackendDisk:
+		dir := cfg.Dir
+		if dir == "" {
+			dir = filepath.Join(".cache", "tools", toolName)
+		}
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(projectRoot, dir)
+		}
+		return newDiskCache(dir, idx)
+	default:
+		return nil, fmt.Errorf("unsupported cache backend: %q", cfg.Backend)
+	}
+}
+
+// cacheKey derives a stable key from the tool name and its arguments. The arguments are
+// canonicalized through encoding/json, which sorts object keys, so {"a":1,"b":2} and
+// {"b":2,"a":1} map to the same entry.
+func cacheKey(toolName string, args *structpb.Struct) (string, error) {
+	canonical, err := json.Marshal(args.AsMap())
+	if err != nil {
+		return "", fmt.Errorf("failed to canonicalize arguments: %w", err)
+	}
+	sum := sha256.Sum256(append([]byte(toolName+"\x00"), canonical...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// lruEntry is the bookkeeping kept for every cached result.
+type lruEntry struct {
+	key     string
+	value   []byte // Encoded result; nil for the disk backend, which keeps it in a file.
+	size    int64
+	expires time.Time // Zero means the entry never expires.
+}
+
+// lruIndex is a least-recently-used index with TTL, entry-count and byte-size limits.
+// It is shared by both backends; onEvict lets the disk backend remove evicted files.
+type lruIndex struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	maxBytes   int64
+	bytes      int64
+	order      *list.List // Front is most recently used.
+	entries    map[string]*list.Element
+	onEvict    func(e *lruEntry)
+}
+
+func newLRUIndex(ttl time.Duration, maxEntries int, maxBytes int64) *lruIndex {
+	return &lruIndex{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// get returns a live entry and marks it as recently used. Expired entries are evicted.
+func (c *lruIndex) get(key string) (*lruEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*lruEntry)
+	if !e.expires.IsZero() && time.Now().After(e.expires) {
+		c.removeLocked(el)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return e, true
+}
+
+// put adds or replaces an entry and evicts old entries until the limits hold.
+// Entries larger than maxBytes on their own are not stored.
+func (c *lruIndex) put(e *lruEntry) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.maxBytes > 0 && e.size > c.maxBytes {
+		return false
+	}
+	if e.expires.IsZero() && c.ttl > 0 {
+		e.expires = time.Now().Add(c.ttl)
+	}
+	if el, ok := c.entries[e.key]; ok {
+		c.bytes -= el.Value.(*lruEntry).size
+		el.Value = e
+		c.order.MoveToFront(el)
+	} else {
+		c.entries[e.key] = c.order.PushFront(e)
+	}
+	c.bytes += e.size
+	for (c.maxEntries > 0 && c.order.Len() > c.maxEntries) || (c.maxBytes > 0 && c.bytes > c.maxBytes) {
+		c.removeLocked(c.order.Back())
+	}
+	return true
+}
+
+func (c *lruIndex) removeLocked(el *list.Element) {
+	e := el.Value.(*lruEntry)
+	c.order.Remove(el)
+	delete(c.entries, e.key)
+	c.bytes -= e.size
+	if c.onEvict != nil {
+		c.onEvict(e)
+	}
+}
+
+// memoryCache keeps encoded results in process memory.
+type memoryCache struct {
+	index *lruIndex
+}
+
+func (m *memoryCache) Get(key string) (*structpb.Struct, bool) {
+	e, ok := m.index.get(key)
+	if !ok {
+		return nil, false
+	}
+	result := &structpb.Struct{}
+	if err := protojson.Unmarshal(e.value, result); err != nil {
+		return nil, false
+	}
+	return result, true
+}
+
+func (m *memoryCache) Set(key string, result *structpb.Struct) {
+	data, err := protojson.Marshal(result)
+	if err != nil {
+		logger.Warn("Failed to encode result for cache", "error", err)
+		return
+	}
+	m.index.put(&lruEntry{key: key, value: data, size: int64(len(data))})
+}
+
+// diskCache stores one JSON file per entry so cached results survive restarts.
+type diskCache struct {
+	dir   string
+	index *lruIndex
+}
+
+// diskCacheFile is the on-disk representation of a cached result.
+type diskCacheFile struct {
+	ExpiresAt time.Time       `json:"expires_at"`
+	Result    json.RawMessage `json:"result"`
+}
+
+// newDiskCache opens (creating if needed) a cache directory and indexes the entries already in it.
+func newDiskCache(dir string, index *lruIndex) (*diskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	d := &diskCache{dir: dir, index: index}
+	index.onEvict = func(e *lruEntry) {
+		if err := os.Remove(d.path(e.key)); err != nil && !os.IsNotExist(err) {
+			logger.Warn("Failed to remove evicted cache file", "path", d.path(e.key), "error", err)
+		}
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+	for _, f := range files {
+		key, ok := strings.CutSuffix(f.Name(), ".json")
+		if f.IsDir() || !ok {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			continue
+		}
+		var entry diskCacheFile
+		if err := json.Unmarshal(data, &entry); err != nil || (!entry.ExpiresAt.IsZero() && time.Now().After(entry.ExpiresAt)) {
+			os.Remove(filepath.Join(dir, f.Name()))
+			continue
+		}
+		index.put(&lruEntry{key: key, size: int64(len(data)), expires: entry.ExpiresAt})
+	}
+	return d, nil
+}
+
+func (d *diskCache) path(key string) string {
+	return filepath.Join(d.dir, key+".json")
+}
+
+func (d *diskCache) Get(key string) (*structpb.Struct, bool) {
+	if _, ok := d.index.get(key); !ok {
+		return nil, false
+	}
+	data, err := os.ReadFile(d.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var entry diskCacheFile
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	result := &structpb.Struct{}
+	if err := protojson.Unmarshal(entry.Result, result); err != nil {
+		return nil, false
+	}
+	return result, true
+}
+
+func (d *diskCache) Set(key string, result *structpb.Struct) {
+	encoded, err := protojson.Marshal(result)
+	if err != nil {
+		logger.Warn("Failed to encode result for cache", "error", err)
+		return
+	}
+	entry := diskCacheFile{Result: encoded}
+	if d.index.ttl > 0 {
+		entry.ExpiresAt = time.Now().Add(d.index.ttl)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		logger.Warn("Failed to encode cache file", "error", err)
+		return
+	}
+	if d.index.maxBytes > 0 && int64(len(data)) > d.index.maxBytes {
+		return
+	}
+	// Write to a temporary file first so readers never see a partial entry.
+	tmp := d.path(key) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		logger.Warn("Failed to write cache file", "path", tmp, "error", err)
+		return
+	}
+	if err := os.Rename(tmp, d.path(key)); err != nil {
+		logger.Warn("Failed to write cache file", "path", d.path(key), "error", err)
+		os.Remove(tmp)
+		return
+	}
+	d.index.put(&lruEntry{key: key, size: int64(len(data)), expires: entry.ExpiresAt})
+}
+
+// lookupCache returns the cache key for a call and, unless the caller asked to bypass the
+// cache, a previously stored result. An empty key means the call is not cacheable.
+func lookupCache(cache resultCache, in *pb.ExecuteToolRequest) (string, *structpb.Struct) {
+	if cache == nil {
+		return "", nil
+	}
+	key, err := cacheKey(in.ToolName, in.Arguments)
+	if err != nil {
+		logger.Warn("Failed to compute cache key, not caching", "tool", in.ToolName, "error", err)
+		return "", nil
+	}
+	if in.Cache == cacheBypass {
+		return key, nil
+	}
+	if result, ok := cache.Get(key); ok {
+		mcpToolCacheRequests.WithLabelValues(in.ToolName, "hit").Inc()
+		return key, result
+	}
+	mcpToolCacheRequests.WithLabelValues(in.ToolName, "miss").Inc()
+	return key, nil
+}
@@ -0,0 +1,123 @@
+// File: MCP-NG/server/cmd/server/cache_test.go
+package main
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	pb "mcp-ng/server/pkg/mcp"
+
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func mustStruct(t *testing.T, m map[string]interface{}) *structpb.Struct {
+	t.Helper()
+	s, err := structpb.NewStruct(m)
+	if err != nil {
+		t.Fatalf("failed to create struct: %v", err)
+	}
+	return s
+}
+
+func TestCacheKeyIsCanonical(t *testing.T) {
+	a, _ := cacheKey("web_search", mustStruct(t, map[string]interface{}{"query": "go", "max_results": 5}))
+	b, _ := cacheKey("web_search", mustStruct(t, map[string]interface{}{"max_results": 5, "query": "go"}))
+	c, _ := cacheKey("calculator", mustStruct(t, map[string]interface{}{"query": "go", "max_results": 5}))
+	if a != b {
+		t.Errorf("argument order changed the key: %s != %s", a, b)
+	}
+	if a == c {
+		t.Error("different tools produced the same key")
+	}
+}
+
+func TestMemoryCacheLimits(t *testing.T) {
+	cache, err := newResultCache("calculator", t.TempDir(), &cacheConfig{Enabled: true, MaxEntries: 2})
+	if err != nil {
+		t.Fatalf("newResultCache failed: %v", err)
+	}
+	for _, k := range []string{"a", "b"} {
+		cache.Set(k, mustStruct(t, map[string]interface{}{"result": k}))
+	}
+	cache.Get("a") // "b" is now the least recently used entry.
+	cache.Set("c", mustStruct(t, map[string]interface{}{"result": "c"}))
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("expected 'b' to be evicted")
+	}
+	got, ok := cache.Get("a")
+	if !ok || got.Fields["result"].GetStringValue() != "a" {
+		t.Errorf("expected 'a' to be cached, got %v", got)
+	}
+}
+
+func TestMemoryCacheTTL(t *testing.T) {
+	idx := newLRUIndex(time.Hour, 0, 0)
+	cache := &memoryCache{index: idx}
+	cache.Set("k", mustStruct(t, map[string]interface{}{"result": 1}))
+	idx.entries["k"].Value.(*lruEntry).expires = time.Now().Add(-time.Second)
+
+	if _, ok := cache.Get("k"); ok {
+		t.Error("expected expired entry to be a miss")
+	}
+	if len(idx.entries) != 0 {
+		t.Error("expected expired entry to be evicted")
+	}
+}
+
+func TestDiskCacheSurvivesReopen(t *testing.T) {
+	root := t.TempDir()
+	cfg := &cacheConfig{Enabled: true, Backend: cacheBackendDisk, TTLSeconds: 60, MaxEntries: 1}
+
+	cache, err := newResultCache("web_search", root, cfg)
+	if err != nil {
+		t.Fatalf("newResultCache failed: %v", err)
+	}
+	cache.Set("old", mustStruct(t, map[string]interface{}{"result": "old"}))
+	cache.Set("new", mustStruct(t, map[string]interface{}{"result": "new"}))
+
+	files, _ := os.ReadDir(cache.(*diskCache).dir)
+	if len(files) != 1 {
+		t.Fatalf("expected evicted file to be removed, found %d files", len(files))
+	}
+
+	reopened, err := newResultCache("web_search", root, cfg)
+	if err != nil {
+		t.Fatalf("reopening cache failed: %v", err)
+	}
+	got, ok := reopened.Get("new")
+	if !ok || got.Fields["result"].GetStringValue() != "new" {
+		t.Errorf("expected entry to survive reopen, got %v", got)
+	}
+}
+
+func TestCacheHitSkipsTheTool(t *testing.T) {
+	calls := 0
+	s := newTestServerWithTools(map[string]*fakeToolClient{
+		"web_search": {run: func(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunResponse, error) {
+			calls++
+			return &pb.ToolRunResponse{Result: structpb.NewStringValue("results")}, nil
+		}},
+	})
+	tool := s.tools["web_search"]
+	cache, err := newResultCache("web_search", t.TempDir(), &cacheConfig{Enabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tool.cache = cache
+	in := &pb.ExecuteToolRequest{ToolName: "web_search", Arguments: mustStruct(t, map[string]interface{}{"query": "go"})}
+	if _, err := s.ExecuteTool(context.Background(), in); err != nil {
+		t.Fatal(err)
+	}
+
+	// A tool that is down, or stopped and waiting to start again, still serves its cache.
+	tool.replicas[0].status = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	tool.status = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	resp, err := s.ExecuteTool(context.Background(), in)
+	if err != nil || !resp.Cached || calls != 1 {
+		t.Errorf("expected a cache hit without calling the tool, got %v, %v after %d calls", resp, err, calls)
+	}
+}
@@ -0,0 +1,208 @@
+// File: MCP-NG/server/cmd/server/formats.go
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	pb "mcp-ng/server/pkg/mcp"
+)
+
+// Tool catalog formats accepted by ListToolsRequest.format.
+const (
+	toolFormatMCP       = "mcp"
+	toolFormatOpenAI    = "openai"
+	toolFormatAnthropic = "anthropic"
+	toolFormatGemini    = "gemini"
+)
+
+// toolFormatter describes how one vendor expects tools to be declared.
+type toolFormatter struct {
+	invalidName *regexp.Regexp // Characters that are not allowed in a tool name
+	maxNameLen  int
+	letterStart bool // Names must start with a letter or an underscore
+	render      func(name string, desc *pb.ToolDescription) map[string]interface{}
+}
+
+var toolFormatters = map[string]*toolFormatter{
+	// https://modelcontextprotocol.io/specification/2025-06-18/server/tools
+	toolFormatMCP: {
+		invalidName: regexp.MustCompile(`[^A-Za-z0-9_.-]`),
+		maxNameLen:  128,
+		render: func(name string, desc *pb.ToolDescription) map[string]interface{} {
+			tool := map[string]interface{}{
+				"name":        name,
+				"description": desc.Description,
+				"inputSchema": toolSchema(desc.Parameters, toolFormatMCP),
+			}
+			// Manifest annotations such as readOnlyHint are MCP tool annotations.
+			if len(desc.Annotations.GetFields()) > 0 {
+				tool["annotations"] = desc.Annotations.AsMap()
+			}
+			return tool
+		},
+	},
+	// https://platform.openai.com/docs/guides/function-calling
+	toolFormatOpenAI: {
+		invalidName: regexp.MustCompile(`[^A-Za-z0-9_-]`),
+		maxNameLen:  64,
+		render: func(name string, desc *pb.ToolDescription) map[string]interface{} {
+			return map[string]interface{}{
+				"type": "function",
+				"function": map[string]interface{}{
+					"name":        name,
+					"description": desc.Description,
+					"parameters":  toolSchema(desc.Parameters, toolFormatOpenAI),
+				},
+			}
+		},
+	},
+	// https://docs.anthropic.com/en/docs/agents-and-tools/tool-use/overview
+	toolFormatAnthropic: {
+		invalidName: regexp.MustCompile(`[^A-Za-z0-9_-]`),
+		maxNameLen:  64,
+		render: func(name string, desc *pb.ToolDescription) map[string]interface{} {
+			return map[string]interface{}{
+				"name":         name,
+				"description":  desc.Description,
+				"input_schema": toolSchema(desc.Parameters, toolFormatAnthropic),
+			}
+		},
+	},
+	// https://ai.google.dev/gemini-api/docs/function-calling
+	toolFormatGemini: {
+		invalidName: regexp.MustCompile(`[^A-Za-z0-9_.:-]`),
+		maxNameLen:  64,
+		letterStart: true,
+		render: func(name string, desc *pb.ToolDescription) map[string]interface{} {
+			decl := map[string]interface{}{"name": name, "description": desc.Description}
+			// Gemini rejects an OBJECT schema without properties, so parameterless tools omit it.
+			if len(desc.Parameters.GetProperties()) > 0 {
+				decl["parameters"] = toolSchema(desc.Parameters, toolFormatGemini)
+			}
+			return decl
+		},
+	},
+}
+
+// formatTools renders tool descriptions in the given vendor format, sorted by name. It also
+// returns the formatted name of every tool mapped to its registry name. For Gemini the tools
+// are wrapped in a single {"functionDeclarations": [...]} entry, matching its request layout.
+func formatTools(descs []*pb.ToolDescription, format string) ([]map[string]interface{}, map[string]string, error) {
+	f, ok := toolFormatters[format]
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported tool format %q", format)
+	}
+	sorted := append([]*pb.ToolDescription(nil), descs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	// Names that are already valid are kept first, so a sanitized name never takes them over.
+	names := make(map[string]string, len(sorted))
+	formatted := make([]string, len(sorted))
+	for i, desc := range sorted {
+		if f.validName(desc.Name) {
+			formatted[i] = desc.Name
+			names[desc.Name] = desc.Name
+		}
+	}
+	tools := make([]map[string]interface{}, 0, len(sorted))
+	for i, desc := range sorted {
+		if formatted[i] == "" {
+			formatted[i] = f.sanitizeName(desc.Name, names)
+			names[formatted[i]] = desc.Name
+		}
+		tools = append(tools, f.render(formatted[i], desc))
+	}
+
+	if format == toolFormatGemini {
+		decls := make([]interface{}, len(tools))
+		for i, t := range tools {
+			decls[i] = t
+		}
+		tools = []map[string]interface{}{{"functionDeclarations": decls}}
+	}
+	return tools, names, nil
+}
+
+// validName reports whether a name can be used by the vendor unchanged.
+func (f *toolFormatter) validName(name string) bool {
+	return name != "" && len(name) <= f.maxNameLen && !f.invalidName.MatchString(name) &&
+		(!f.letterStart || isNameStart(name[0]))
+}
+
+// sanitizeName replaces characters the vendor does not allow, enforces its length limit and
+// appends a numeric suffix if the result collides with a name already in use.
+func (f *toolFormatter) sanitizeName(name string, used map[string]string) string {
+	clean := f.invalidName.ReplaceAllString(name, "_")
+	if clean == "" {
+		clean = "tool"
+	}
+	if f.letterStart && !isNameStart(clean[0]) {
+		clean = "_" + clean
+	}
+	candidate := truncate(clean, f.maxNameLen)
+	for i := 2; ; i++ {
+		if _, taken := used[candidate]; !taken {
+			return candidate
+		}
+		suffix := fmt.Sprintf("_%d", i)
+		candidate = truncate(clean, f.maxNameLen-len(suffix)) + suffix
+	}
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+// toolSchema converts a tool's parameters into the schema dialect of a format. MCP, OpenAI and
+// Anthropic take JSON Schema; Gemini takes an OpenAPI 3.0 subset with upper-case type names.
+// Array parameters get an items schema because OpenAI and Gemini reject arrays without one.
+func toolSchema(params *pb.ToolParameters, format string) map[string]interface{} {
+	typeName := func(t string) string {
+		if t == "" {
+			t = "string"
+		}
+		if format == toolFormatGemini {
+			return strings.ToUpper(t)
+		}
+		return t
+	}
+
+	properties := make(map[string]interface{})
+	for name, p := range params.GetProperties() {
+		prop := map[string]interface{}{"type": typeName(p.Type)}
+		if p.Description != "" {
+			prop["description"] = p.Description
+		}
+		if p.Type == "array" {
+			if format == toolFormatGemini {
+				prop["items"] = map[string]interface{}{"type": "STRING"}
+			} else {
+				prop["items"] = map[string]interface{}{}
+			}
+		}
+		properties[name] = prop
+	}
+	schema := map[string]interface{}{"type": typeName("object"), "properties": properties}
+
+	// Vendors reject required entries that do not name a declared property.
+	var required []interface{}
+	for _, r := range params.GetRequired() {
+		if _, ok := properties[r]; ok {
+			required = append(required, r)
+		}
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
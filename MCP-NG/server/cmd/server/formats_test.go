@@ -0,0 +1,124 @@
+// File: MCP-NG/server/cmd/server/formats_test.go
+package main
+
+import (
+	"context"
+	"testing"
+
+	pb "mcp-ng/server/pkg/mcp"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var formatTestTools = []*pb.ToolDescription{
+	{
+		Name:        "web_search",
+		Description: "Searches the web.",
+		Parameters: &pb.ToolParameters{
+			Type: "object",
+			Properties: map[string]*pb.ToolParameter{
+				"query": {Type: "string", Description: "Search query."},
+				"tags":  {Type: "array"},
+			},
+			Required: []string{"query", "missing"},
+		},
+	},
+	{Name: "2fa.code generator", Description: "No parameters."},
+}
+
+func TestFormatToolsOpenAI(t *testing.T) {
+	tools, names, err := formatTools(formatTestTools, toolFormatOpenAI)
+	if err != nil {
+		t.Fatalf("formatTools failed: %v", err)
+	}
+	if names["2fa_code_generator"] != "2fa.code generator" || names["web_search"] != "web_search" {
+		t.Errorf("unexpected name mapping: %v", names)
+	}
+
+	fn := tools[1]["function"].(map[string]interface{})
+	if tools[1]["type"] != "function" || fn["name"] != "web_search" {
+		t.Fatalf("unexpected OpenAI tool: %v", tools[1])
+	}
+	params := fn["parameters"].(map[string]interface{})
+	if _, ok := params["properties"].(map[string]interface{})["tags"].(map[string]interface{})["items"]; !ok {
+		t.Error("expected array parameter to get an items schema")
+	}
+	if req := params["required"].([]interface{}); len(req) != 1 || req[0] != "query" {
+		t.Errorf("expected undeclared required entries to be dropped, got %v", req)
+	}
+}
+
+func TestFormatToolsGemini(t *testing.T) {
+	tools, names, err := formatTools(formatTestTools, toolFormatGemini)
+	if err != nil {
+		t.Fatalf("formatTools failed: %v", err)
+	}
+	if len(tools) != 1 {
+		t.Fatalf("expected a single functionDeclarations entry, got %d", len(tools))
+	}
+	decls := tools[0]["functionDeclarations"].([]interface{})
+	noParams := decls[0].(map[string]interface{})
+	if noParams["name"] != "_2fa.code_generator" || names["_2fa.code_generator"] != "2fa.code generator" {
+		t.Errorf("expected name to start with an underscore, got %v", noParams["name"])
+	}
+	if _, ok := noParams["parameters"]; ok {
+		t.Error("expected parameterless tool to omit parameters")
+	}
+	params := decls[1].(map[string]interface{})["parameters"].(map[string]interface{})
+	query := params["properties"].(map[string]interface{})["query"].(map[string]interface{})
+	if params["type"] != "OBJECT" || query["type"] != "STRING" {
+		t.Errorf("expected upper-case OpenAPI types, got %v", params)
+	}
+}
+
+func TestFormatToolsAnthropicAndMCP(t *testing.T) {
+	tools, _, err := formatTools(formatTestTools, toolFormatAnthropic)
+	if err != nil {
+		t.Fatalf("formatTools failed: %v", err)
+	}
+	if _, ok := tools[1]["input_schema"].(map[string]interface{}); !ok || tools[1]["name"] != "web_search" {
+		t.Errorf("unexpected Anthropic tool: %v", tools[1])
+	}
+
+	tools, names, err := formatTools(formatTestTools, toolFormatMCP)
+	if err != nil {
+		t.Fatalf("formatTools failed: %v", err)
+	}
+	if tools[0]["name"] != "2fa.code_generator" || names["2fa.code_generator"] == "" {
+		t.Errorf("expected MCP to keep dots in names, got %v", tools[0]["name"])
+	}
+	if _, ok := tools[0]["inputSchema"].(map[string]interface{}); !ok {
+		t.Errorf("unexpected MCP tool: %v", tools[0])
+	}
+}
+
+func TestSanitizeNameAvoidsCollisions(t *testing.T) {
+	tools := []*pb.ToolDescription{{Name: "a.b"}, {Name: "a_b"}, {Name: "a b"}}
+	_, names, err := formatTools(tools, toolFormatOpenAI)
+	if err != nil {
+		t.Fatalf("formatTools failed: %v", err)
+	}
+	if names["a_b"] != "a_b" || names["a_b_2"] != "a b" || names["a_b_3"] != "a.b" {
+		t.Errorf("unexpected name mapping: %v", names)
+	}
+}
+
+func TestListToolsFormat(t *testing.T) {
+	s := newTestServerWithTools(map[string]*fakeToolClient{"calculator": {}})
+
+	resp, err := s.ListTools(context.Background(), &pb.ListToolsRequest{Format: toolFormatAnthropic})
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	if len(resp.Tools) != 0 || len(resp.FormattedTools) != 1 || len(resp.ToolNames) != 0 {
+		t.Errorf("unexpected response: %v", resp)
+	}
+	if got := resp.FormattedTools[0].Fields["name"].GetStringValue(); got != "calculator" {
+		t.Errorf("expected calculator, got %q", got)
+	}
+
+	if _, err := s.ListTools(context.Background(), &pb.ListToolsRequest{Format: "cohere"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for an unknown format, got %v", err)
+	}
+}
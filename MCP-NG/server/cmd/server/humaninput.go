@@ -0,0 +1,503 @@
+// File: MCP-NG/server/cmd/server/humaninput.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"mcp-ng/server/pkg/humanform"
+	pb "mcp-ng/server/pkg/mcp"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Human-input task stores selectable in the server's config.json.
+const (
+	humanStoreMemory = "memory"
+	humanStoreBolt   = "bolt"
+	humanStoreSQLite = "sqlite"
+)
+
+// Statuses of a human-input task.
+const (
+	humanStatusPending   = "pending"
+	humanStatusCompleted = "completed"
+	humanStatusCanceled  = "canceled"
+	humanStatusExpired   = "expired" // Reported for pending tasks past their deadline; never stored
+)
+
+const (
+	defaultHumanInputTTL     = 24 * time.Hour
+	defaultHumanInputCleanup = time.Minute
+)
+
+// humanInputConfig is the "human_input" section of the server's config.json.
+type humanInputConfig struct {
+	Store string `json:"store"` // "memory" (default), "bolt" or "sqlite"
+	// Path is the database file of the bolt and sqlite stores; relative paths are resolved
+	// against the project root. Defaults to .data/human_inputs.<store>.
+	Path string `json:"path"`
+	// PendingTTLSeconds is how long a task waits for a response before it expires, and
+	// ResponseTTLSeconds how long a task is kept once it was answered, canceled or expired.
+	// Both default to a day.
+	PendingTTLSeconds  int `json:"pending_ttl_seconds"`
+	ResponseTTLSeconds int `json:"response_ttl_seconds"`
+	// CleanupIntervalSeconds is how often expired tasks are purged. Defaults to 60.
+	CleanupIntervalSeconds int `json:"cleanup_interval_seconds"`
+}
+
+func (c *humanInputConfig) pendingTTL() time.Duration {
+	return secondsOr(c.PendingTTLSeconds, defaultHumanInputTTL)
+}
+
+func (c *humanInputConfig) responseTTL() time.Duration {
+	return secondsOr(c.ResponseTTLSeconds, defaultHumanInputTTL)
+}
+
+func (c *humanInputConfig) cleanupInterval() time.Duration {
+	return secondsOr(c.CleanupIntervalSeconds, defaultHumanInputCleanup)
+}
+
+// secondsOr converts a number of seconds from a config file, using def when it is not set.
+func secondsOr(seconds int, def time.Duration) time.Duration {
+	if seconds <= 0 {
+		return def
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// humanTask is a human-input task as kept in the store.
+type humanTask struct {
+	TaskID       string          `json:"task_id"`
+	Status       string          `json:"status"`
+	Prompt       string          `json:"prompt"`
+	Creator      string          `json:"creator"`
+	Form         *humanform.Form `json:"form,omitempty"`
+	Approval     *toolApproval   `json:"approval,omitempty"` // Set for tasks that gate a tool call
+	Response     json.RawMessage `json:"response,omitempty"` // The response Value in protojson
+	AnsweredBy   string          `json:"answered_by,omitempty"`
+	CancelReason string          `json:"cancel_reason,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	CompletedAt  time.Time       `json:"completed_at,omitempty"` // Answered or canceled
+	ExpiresAt    time.Time       `json:"expires_at"`             // Deadline for a response
+	PurgeAt      time.Time       `json:"purge_at"`               // When the cleanup removes the task
+}
+
+// expired reports whether the task is still pending past its deadline.
+func (t *humanTask) expired(now time.Time) bool {
+	return t.Status == humanStatusPending && now.After(t.ExpiresAt)
+}
+
+// purgeable reports whether the cleanup may remove the task.
+func (t *humanTask) purgeable(now time.Time) bool {
+	return now.After(t.PurgeAt)
+}
+
+// settled reports whether nothing more will happen to the task: it is no longer pending
+// and, if it gates a tool call, the call is not running.
+func (t *humanTask) settled(now time.Time) bool {
+	if t.reportedStatus(now) == humanStatusPending {
+		return false
+	}
+	return t.Approval == nil || t.Approval.Status != approvalRunning
+}
+
+// reportedStatus is the status clients see, which includes expiry.
+func (t *humanTask) reportedStatus(now time.Time) string {
+	if t.expired(now) {
+		return humanStatusExpired
+	}
+	return t.Status
+}
+
+func (t *humanTask) proto(now time.Time) *pb.HumanInputTask {
+	return &pb.HumanInputTask{
+		TaskId:            t.TaskID,
+		Status:            t.reportedStatus(now),
+		Prompt:            t.Prompt,
+		Creator:           t.Creator,
+		CreatedAtUnixMs:   t.CreatedAt.UnixMilli(),
+		ExpiresAtUnixMs:   t.ExpiresAt.UnixMilli(),
+		CompletedAtUnixMs: unixMilliOrZero(t.CompletedAt),
+		CancelReason:      t.CancelReason,
+		Form:              formStruct(t.Form),
+		AnsweredBy:        t.AnsweredBy,
+		Approval:          t.Approval.proto(),
+	}
+}
+
+func formStruct(form *humanform.Form) *structpb.Struct {
+	if form == nil {
+		return nil
+	}
+	s, _ := structpb.NewStruct(form.Map())
+	return s
+}
+
+func unixMilliOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+// humanInputStore keeps human-input tasks and their responses. Implementations are safe for
+// concurrent use.
+type humanInputStore interface {
+	// Get returns the task, or nil if there is none with that ID.
+	Get(taskID string) (*humanTask, error)
+	// Put creates or replaces a task.
+	Put(task *humanTask) error
+	// List returns the tasks with the given status, oldest first.
+	List(status string) ([]*humanTask, error)
+	// DeleteExpired removes the tasks whose purge time is before now and returns how many.
+	DeleteExpired(now time.Time) (int, error)
+	Close() error
+}
+
+// newHumanInputStore opens the store described by cfg.
+func newHumanInputStore(projectRoot string, cfg *humanInputConfig) (humanInputStore, error) {
+	path := cfg.Path
+	if path == "" {
+		path = filepath.Join(".data", "human_inputs."+cfg.Store)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(projectRoot, path)
+	}
+	switch cfg.Store {
+	case "", humanStoreMemory:
+		return newMemoryHumanInputStore(), nil
+	case humanStoreBolt:
+		return newBoltHumanInputStore(path)
+	case humanStoreSQLite:
+		return newSQLiteHumanInputStore(path)
+	default:
+		return nil, fmt.Errorf("unsupported human input store: %q", cfg.Store)
+	}
+}
+
+// memoryHumanInputStore keeps tasks in memory; they are lost when the server restarts.
+type memoryHumanInputStore struct {
+	mu    sync.Mutex
+	tasks map[string]humanTask
+}
+
+func newMemoryHumanInputStore() *memoryHumanInputStore {
+	return &memoryHumanInputStore{tasks: make(map[string]humanTask)}
+}
+
+func (m *memoryHumanInputStore) Get(taskID string) (*humanTask, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	task, ok := m.tasks[taskID]
+	if !ok {
+		return nil, nil
+	}
+	return copyHumanTask(&task), nil
+}
+
+func (m *memoryHumanInputStore) Put(task *humanTask) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tasks[task.TaskID] = *copyHumanTask(task)
+	return nil
+}
+
+func (m *memoryHumanInputStore) List(status string) ([]*humanTask, error) {
+	m.mu.Lock()
+	var tasks []*humanTask
+	for _, task := range m.tasks {
+		if task.Status == status {
+			tasks = append(tasks, copyHumanTask(&task))
+		}
+	}
+	m.mu.Unlock()
+	sortHumanTasks(tasks)
+	return tasks, nil
+}
+
+// copyHumanTask copies a task along with its approval, which callers update in place, so
+// they never share it with the memory store.
+func copyHumanTask(task *humanTask) *humanTask {
+	c := *task
+	if task.Approval != nil {
+		approval := *task.Approval
+		c.Approval = &approval
+	}
+	return &c
+}
+
+func (m *memoryHumanInputStore) DeleteExpired(now time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for id, task := range m.tasks {
+		if task.purgeable(now) {
+			delete(m.tasks, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *memoryHumanInputStore) Close() error { return nil }
+
+// sortHumanTasks orders tasks by creation time, then ID.
+func sortHumanTasks(tasks []*humanTask) {
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].CreatedAt.Equal(tasks[j].CreatedAt) {
+			return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+		}
+		return tasks[i].TaskID < tasks[j].TaskID
+	})
+}
+
+// startHumanInputCleanup periodically purges expired human-input tasks until shutdown.
+func (s *server) startHumanInputCleanup() {
+	go func() {
+		ticker := time.NewTicker(s.config.HumanInput.cleanupInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.purgeExpiredHumanInputs(time.Now())
+			case <-s.shutdown:
+				return
+			}
+		}
+	}()
+}
+
+func (s *server) purgeExpiredHumanInputs(now time.Time) {
+	n, err := s.humanInputs.DeleteExpired(now)
+	if err != nil {
+		logger.Error("Failed to purge expired human input tasks", "error", err)
+		return
+	}
+	if n > 0 {
+		logger.Info("Purged expired human input tasks", "count", n)
+	}
+}
+
+// RegisterHumanInput records a task that is about to be shown to an operator.
+func (s *server) RegisterHumanInput(ctx context.Context, in *pb.RegisterHumanInputRequest) (*pb.HumanInputTask, error) {
+	if in.Prompt == "" {
+		return nil, status.Error(codes.InvalidArgument, "prompt cannot be empty")
+	}
+	if in.TtlSeconds < 0 {
+		return nil, status.Error(codes.InvalidArgument, "ttl_seconds must not be negative")
+	}
+	var form *humanform.Form
+	if in.Form != nil {
+		var err error
+		if form, err = humanform.Parse(in.Form.AsMap()); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	task := &humanTask{TaskID: in.TaskId, Prompt: in.Prompt, Creator: in.Creator, Form: form}
+	if err := s.registerHumanTask(task, secondsOr(int(in.TtlSeconds), s.config.HumanInput.pendingTTL())); err != nil {
+		return nil, err
+	}
+	logger.Info("Registered human input task", "task_id", task.TaskID, "creator", task.Creator)
+	return task.proto(time.Now()), nil
+}
+
+// registerHumanTask stores a new pending task from the prompt, creator, form and approval
+// of task, generating its ID if it has none, and fills in the rest.
+func (s *server) registerHumanTask(task *humanTask, ttl time.Duration) error {
+	if task.TaskID == "" {
+		task.TaskID = uuid.New().String()
+	}
+	s.humanMu.Lock()
+	defer s.humanMu.Unlock()
+	existing, err := s.humanInputs.Get(task.TaskID)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to read task: %v", err)
+	}
+	if existing != nil {
+		return status.Errorf(codes.AlreadyExists, "Human input task '%s' already exists.", task.TaskID)
+	}
+	now := time.Now()
+	task.Status = humanStatusPending
+	task.CreatedAt = now
+	task.ExpiresAt = now.Add(ttl)
+	task.PurgeAt = now.Add(ttl + s.config.HumanInput.responseTTL())
+	if err := s.humanInputs.Put(task); err != nil {
+		return status.Errorf(codes.Internal, "failed to store task: %v", err)
+	}
+	s.publishHumanEvent(humanEventRegistered, task, nil, now)
+	return nil
+}
+
+// pendingHumanTask returns a task that can still be answered or canceled, or the error
+// explaining why it cannot. The caller must hold s.humanMu.
+func (s *server) pendingHumanTask(taskID string, now time.Time) (*humanTask, error) {
+	task, err := s.humanInputs.Get(taskID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read task: %v", err)
+	}
+	if task == nil {
+		return nil, status.Errorf(codes.NotFound, "Human input task '%s' not found.", taskID)
+	}
+	switch task.reportedStatus(now) {
+	case humanStatusPending:
+		return task, nil
+	case humanStatusCompleted:
+		return nil, status.Errorf(codes.AlreadyExists, "Human input task '%s' was already answered.", taskID)
+	default:
+		return nil, status.Errorf(codes.FailedPrecondition, "Human input task '%s' is %s.", taskID, task.reportedStatus(now))
+	}
+}
+
+// ProvideHumanInput stores the response from a human for a registered, pending task.
+func (s *server) ProvideHumanInput(ctx context.Context, in *pb.ProvideHumanInputRequest) (*pb.ProvideHumanInputResponse, error) {
+	logger.Info("Received human input", "task_id", in.TaskId, "operator", in.Operator)
+	if in.TaskId == "" {
+		logger.Error("Received ProvideHumanInput request with empty task_id")
+		return nil, status.Error(codes.InvalidArgument, "task_id cannot be empty")
+	}
+	response, err := protojson.Marshal(in.Response)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid response: %v", err)
+	}
+
+	s.humanMu.Lock()
+	defer s.humanMu.Unlock()
+	now := time.Now()
+	task, err := s.pendingHumanTask(in.TaskId, now)
+	if err != nil {
+		logger.Warn("Rejected human input", "task_id", in.TaskId, "error", err)
+		return nil, err
+	}
+	if task.Form != nil {
+		if err := task.Form.Check(in.Response.AsInterface()); err != nil {
+			logger.Warn("Rejected human input that does not fill in the form", "task_id", in.TaskId, "error", err)
+			return nil, status.Errorf(codes.InvalidArgument, "Response does not match the task's form: %v", err)
+		}
+	}
+	task.Status = humanStatusCompleted
+	task.Response = response
+	task.AnsweredBy = in.Operator
+	task.CompletedAt = now
+	task.PurgeAt = now.Add(s.config.HumanInput.responseTTL())
+	approved := task.Approval != nil && s.decideApproval(task, in.Response)
+	if err := s.humanInputs.Put(task); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to store response: %v", err)
+	}
+	s.publishHumanEvent(humanEventCompleted, task, in.Response, now)
+	if approved {
+		go s.runApprovedCall(task.TaskID)
+	}
+
+	return &pb.ProvideHumanInputResponse{Status: "received"}, nil
+}
+
+// CancelHumanInput withdraws a pending task.
+func (s *server) CancelHumanInput(ctx context.Context, in *pb.CancelHumanInputRequest) (*pb.HumanInputTask, error) {
+	if in.TaskId == "" {
+		return nil, status.Error(codes.InvalidArgument, "task_id cannot be empty")
+	}
+	s.humanMu.Lock()
+	defer s.humanMu.Unlock()
+	now := time.Now()
+	task, err := s.pendingHumanTask(in.TaskId, now)
+	if err != nil {
+		return nil, err
+	}
+	task.Status = humanStatusCanceled
+	task.CancelReason = in.Reason
+	task.CompletedAt = now
+	task.PurgeAt = now.Add(s.config.HumanInput.responseTTL())
+	if err := s.humanInputs.Put(task); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to store task: %v", err)
+	}
+	s.publishHumanEvent(humanEventCanceled, task, nil, now)
+	logger.Info("Canceled human input task", "task_id", in.TaskId, "reason", in.Reason)
+	return task.proto(now), nil
+}
+
+// ListPendingHumanInputs lists the tasks that can still be answered.
+func (s *server) ListPendingHumanInputs(ctx context.Context, in *pb.ListPendingHumanInputsRequest) (*pb.ListPendingHumanInputsResponse, error) {
+	tasks, err := s.humanInputs.List(humanStatusPending)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list tasks: %v", err)
+	}
+	now := time.Now()
+	resp := &pb.ListPendingHumanInputsResponse{}
+	for _, task := range tasks {
+		if task.expired(now) || (in.Creator != "" && task.Creator != in.Creator) {
+			continue
+		}
+		resp.Tasks = append(resp.Tasks, task.proto(now))
+	}
+	return resp, nil
+}
+
+// trackPendingHumanInput registers tasks from results that ask for a human response, e.g.
+// {"status": "waiting_for_human", "task_id": "..."}, unless the tool already registered
+// them itself. The prompt and form are taken from the call's arguments.
+func (s *server) trackPendingHumanInput(toolName string, args, result *structpb.Struct) {
+	if result.GetFields()["status"].GetStringValue() != "waiting_for_human" {
+		return
+	}
+	taskID := result.GetFields()["task_id"].GetStringValue()
+	if taskID == "" {
+		return
+	}
+	existing, err := s.humanInputs.Get(taskID)
+	if err != nil {
+		logger.Error("Failed to read human input task", "task_id", taskID, "error", err)
+		return
+	}
+	if existing != nil {
+		return
+	}
+	prompt := args.GetFields()["prompt"].GetStringValue()
+	var form *humanform.Form
+	if raw := args.GetFields()["form"].GetStructValue(); raw != nil {
+		if form, err = humanform.Parse(raw.AsMap()); err != nil {
+			logger.Warn("Ignoring invalid form of human input task", "task_id", taskID, "error", err)
+			form = nil
+		}
+	}
+	task := &humanTask{TaskID: taskID, Prompt: prompt, Creator: toolName, Form: form}
+	if err := s.registerHumanTask(task, s.config.HumanInput.pendingTTL()); err != nil && status.Code(err) != codes.AlreadyExists {
+		logger.Error("Failed to store human input task", "task_id", taskID, "error", err)
+	}
+}
+
+// GetHumanInput retrieves the status of a task and, once it was answered, the response.
+func (s *server) GetHumanInput(ctx context.Context, in *pb.GetHumanInputRequest) (*pb.GetHumanInputResponse, error) {
+	logger.Info("Checking for human input", "task_id", in.TaskId)
+	if in.TaskId == "" {
+		logger.Error("Received GetHumanInput request with empty task_id")
+		return nil, status.Error(codes.InvalidArgument, "task_id cannot be empty")
+	}
+
+	task, err := s.humanInputs.Get(in.TaskId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read task: %v", err)
+	}
+	if task == nil {
+		return nil, status.Errorf(codes.NotFound, "Human input task '%s' not found.", in.TaskId)
+	}
+	now := time.Now()
+	resp := &pb.GetHumanInputResponse{Status: task.reportedStatus(now), Task: task.proto(now)}
+	if task.Status == humanStatusCompleted {
+		logger.Info("Found completed response for task", "task_id", in.TaskId)
+		resp.Response = &structpb.Value{}
+		if err := protojson.Unmarshal(task.Response, resp.Response); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to decode stored response: %v", err)
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,115 @@
+// File: MCP-NG/server/cmd/server/humaninput_bolt.go
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// humanTasksBucket holds the tasks of the bolt store, keyed by task ID, as JSON.
+var humanTasksBucket = []byte("human_inputs")
+
+// boltHumanInputStore keeps tasks in a BoltDB file.
+type boltHumanInputStore struct {
+	db *bolt.DB
+}
+
+func newBoltHumanInputStore(path string) (*boltHumanInputStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	// A second server on the same file would block forever without the timeout.
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(humanTasksBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &boltHumanInputStore{db: db}, nil
+}
+
+func (b *boltHumanInputStore) Get(taskID string) (*humanTask, error) {
+	var task *humanTask
+	err := b.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(humanTasksBucket).Get([]byte(taskID))
+		if data == nil {
+			return nil
+		}
+		task = &humanTask{}
+		return json.Unmarshal(data, task)
+	})
+	return task, err
+}
+
+func (b *boltHumanInputStore) Put(task *humanTask) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(humanTasksBucket).Put([]byte(task.TaskID), data)
+	})
+}
+
+func (b *boltHumanInputStore) List(status string) ([]*humanTask, error) {
+	var tasks []*humanTask
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(humanTasksBucket).ForEach(func(_, data []byte) error {
+			task := &humanTask{}
+			if err := json.Unmarshal(data, task); err != nil {
+				return err
+			}
+			if task.Status == status {
+				tasks = append(tasks, task)
+			}
+			return nil
+		})
+	})
+	sortHumanTasks(tasks)
+	return tasks, err
+}
+
+func (b *boltHumanInputStore) DeleteExpired(now time.Time) (int, error) {
+	n := 0
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(humanTasksBucket)
+		// Deleting while iterating with a cursor skips keys, so collect them first.
+		var expired [][]byte
+		err := bucket.ForEach(func(key, data []byte) error {
+			var task humanTask
+			if err := json.Unmarshal(data, &task); err != nil {
+				return err
+			}
+			if task.purgeable(now) {
+				expired = append(expired, append([]byte(nil), key...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, key := range expired {
+			if err := bucket.Delete(key); err != nil {
+				return err
+			}
+		}
+		n = len(expired)
+		return nil
+	})
+	return n, err
+}
+
+func (b *boltHumanInputStore) Close() error {
+	return b.db.Close()
+}
@@ -0,0 +1,101 @@
+// File: MCP-NG/server/cmd/server/humaninput_sqlite.go
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite" // Pure Go, so the server still builds with CGO_ENABLED=0
+)
+
+// sqliteHumanInputStore keeps tasks in an SQLite database. The status and purge time have their
+// own columns so listing and cleanup run as queries; the whole task is stored as JSON.
+type sqliteHumanInputStore struct {
+	db *sql.DB
+}
+
+const sqliteHumanInputSchema = `CREATE TABLE IF NOT EXISTS human_inputs (
+	task_id    TEXT PRIMARY KEY,
+	status     TEXT NOT NULL,
+	created_at INTEGER NOT NULL,
+	purge_at   INTEGER NOT NULL,
+	data       TEXT NOT NULL
+)`
+
+func newSQLiteHumanInputStore(path string) (*sqliteHumanInputStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	if _, err := db.Exec(sqliteHumanInputSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	return &sqliteHumanInputStore{db: db}, nil
+}
+
+func (q *sqliteHumanInputStore) Get(taskID string) (*humanTask, error) {
+	var data string
+	err := q.db.QueryRow(`SELECT data FROM human_inputs WHERE task_id = ?`, taskID).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	task := &humanTask{}
+	return task, json.Unmarshal([]byte(data), task)
+}
+
+func (q *sqliteHumanInputStore) Put(task *humanTask) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+	_, err = q.db.Exec(`INSERT INTO human_inputs (task_id, status, created_at, purge_at, data) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (task_id) DO UPDATE SET status = excluded.status, created_at = excluded.created_at,
+		purge_at = excluded.purge_at, data = excluded.data`,
+		task.TaskID, task.Status, task.CreatedAt.UnixNano(), task.PurgeAt.UnixNano(), string(data))
+	return err
+}
+
+func (q *sqliteHumanInputStore) List(status string) ([]*humanTask, error) {
+	rows, err := q.db.Query(`SELECT data FROM human_inputs WHERE status = ? ORDER BY created_at, task_id`, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var tasks []*humanTask
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		task := &humanTask{}
+		if err := json.Unmarshal([]byte(data), task); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, rows.Err()
+}
+
+func (q *sqliteHumanInputStore) DeleteExpired(now time.Time) (int, error) {
+	res, err := q.db.Exec(`DELETE FROM human_inputs WHERE purge_at < ?`, now.UnixNano())
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func (q *sqliteHumanInputStore) Close() error {
+	return q.db.Close()
+}
@@ -0,0 +1,366 @@
+// File: MCP-NG/server/cmd/server/humaninput_test.go
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pb "mcp-ng/server/pkg/mcp"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// openHumanInputStores opens one store of every kind in a temporary directory.
+func openHumanInputStores(t *testing.T) map[string]func() humanInputStore {
+	t.Helper()
+	dir := t.TempDir()
+	open := func(store string) func() humanInputStore {
+		return func() humanInputStore {
+			s, err := newHumanInputStore(dir, &humanInputConfig{Store: store})
+			if err != nil {
+				t.Fatalf("failed to open the %s store: %v", store, err)
+			}
+			return s
+		}
+	}
+	return map[string]func() humanInputStore{
+		humanStoreMemory: open(humanStoreMemory),
+		humanStoreBolt:   open(humanStoreBolt),
+		humanStoreSQLite: open(humanStoreSQLite),
+	}
+}
+
+func TestHumanInputStores(t *testing.T) {
+	for name, open := range openHumanInputStores(t) {
+		t.Run(name, func(t *testing.T) {
+			store := open()
+			defer store.Close()
+			now := time.Now()
+
+			if task, err := store.Get("missing"); task != nil || err != nil {
+				t.Fatalf("expected no task and no error, got %v, %v", task, err)
+			}
+			tasks := []*humanTask{
+				{TaskID: "b", Status: humanStatusPending, CreatedAt: now, ExpiresAt: now.Add(time.Hour), PurgeAt: now.Add(2 * time.Hour)},
+				{TaskID: "a", Status: humanStatusPending, CreatedAt: now.Add(-time.Minute), ExpiresAt: now.Add(time.Hour), PurgeAt: now.Add(2 * time.Hour)},
+				{TaskID: "old", Status: humanStatusCompleted, Prompt: "Proceed?", Response: []byte(`"yes"`), CreatedAt: now, PurgeAt: now.Add(-time.Second)},
+			}
+			for _, task := range tasks {
+				if err := store.Put(task); err != nil {
+					t.Fatalf("Put failed: %v", err)
+				}
+			}
+
+			pending, err := store.List(humanStatusPending)
+			if err != nil || len(pending) != 2 || pending[0].TaskID != "a" || pending[1].TaskID != "b" {
+				t.Fatalf("expected the pending tasks oldest first, got %v, %v", pending, err)
+			}
+			got, err := store.Get("old")
+			if err != nil || got == nil || string(got.Response) != `"yes"` || got.Prompt != "Proceed?" || !got.PurgeAt.Equal(tasks[2].PurgeAt) {
+				t.Fatalf("unexpected task: %+v, %v", got, err)
+			}
+
+			// Answering a task replaces it.
+			tasks[0].Status = humanStatusCompleted
+			if err := store.Put(tasks[0]); err != nil {
+				t.Fatal(err)
+			}
+			if pending, _ := store.List(humanStatusPending); len(pending) != 1 {
+				t.Errorf("expected one pending task after answering one, got %v", pending)
+			}
+
+			if n, err := store.DeleteExpired(now); err != nil || n != 1 {
+				t.Errorf("expected one expired task to be removed, got %d, %v", n, err)
+			}
+			if task, _ := store.Get("old"); task != nil {
+				t.Errorf("expected the expired task to be gone, got %+v", task)
+			}
+		})
+	}
+}
+
+func TestHumanInputSurvivesRestart(t *testing.T) {
+	for name, open := range openHumanInputStores(t) {
+		if name == humanStoreMemory {
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			s := newTestServerWithTools(nil)
+			s.humanInputs = open()
+			_, err := s.RegisterHumanInput(context.Background(), &pb.RegisterHumanInputRequest{TaskId: "restart-" + name, Prompt: "Proceed?"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.humanInputs.Close()
+
+			// The pending task is still there after the server comes back.
+			s.humanInputs = open()
+			defer s.humanInputs.Close()
+			pending, err := s.humanInputs.List(humanStatusPending)
+			if err != nil || len(pending) != 1 || pending[0].TaskID != "restart-"+name {
+				t.Fatalf("expected the pending task to survive, got %v, %v", pending, err)
+			}
+			ctx := context.Background()
+			_, err = s.ProvideHumanInput(ctx, &pb.ProvideHumanInputRequest{TaskId: "restart-" + name, Response: structpb.NewBoolValue(true)})
+			if err != nil {
+				t.Fatalf("ProvideHumanInput failed: %v", err)
+			}
+			resp, err := s.GetHumanInput(ctx, &pb.GetHumanInputRequest{TaskId: "restart-" + name})
+			if err != nil || resp.Status != humanStatusCompleted || !resp.Response.GetBoolValue() {
+				t.Fatalf("unexpected response: %v, %v", resp, err)
+			}
+		})
+	}
+}
+
+func TestHumanInputLifecycle(t *testing.T) {
+	s := newTestServerWithTools(nil)
+	ctx := context.Background()
+
+	if _, err := s.RegisterHumanInput(ctx, &pb.RegisterHumanInputRequest{TaskId: "t1"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected a task without a prompt to be rejected, got %v", err)
+	}
+	task, err := s.RegisterHumanInput(ctx, &pb.RegisterHumanInputRequest{Prompt: "Deploy?", Creator: "agent-a", TtlSeconds: 60})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.TaskId == "" || task.Status != humanStatusPending || task.ExpiresAtUnixMs-task.CreatedAtUnixMs != 60000 {
+		t.Errorf("unexpected task: %v", task)
+	}
+	if _, err := s.RegisterHumanInput(ctx, &pb.RegisterHumanInputRequest{TaskId: task.TaskId, Prompt: "Again?"}); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("expected a duplicate task to be rejected, got %v", err)
+	}
+	other, _ := s.RegisterHumanInput(ctx, &pb.RegisterHumanInputRequest{Prompt: "Scale?", Creator: "agent-b"})
+
+	list, err := s.ListPendingHumanInputs(ctx, &pb.ListPendingHumanInputsRequest{Creator: "agent-b"})
+	if err != nil || len(list.Tasks) != 1 || list.Tasks[0].TaskId != other.TaskId {
+		t.Fatalf("expected only agent-b's task, got %v, %v", list, err)
+	}
+
+	// A canceled task can no longer be answered.
+	canceled, err := s.CancelHumanInput(ctx, &pb.CancelHumanInputRequest{TaskId: other.TaskId, Reason: "no longer needed"})
+	if err != nil || canceled.Status != humanStatusCanceled || canceled.CancelReason != "no longer needed" {
+		t.Fatalf("unexpected cancel result: %v, %v", canceled, err)
+	}
+	if _, err := s.ProvideHumanInput(ctx, &pb.ProvideHumanInputRequest{TaskId: other.TaskId, Response: structpb.NewBoolValue(true)}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected the answer to a canceled task to be rejected, got %v", err)
+	}
+	if _, err := s.CancelHumanInput(ctx, &pb.CancelHumanInputRequest{TaskId: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected canceling an unknown task to fail, got %v", err)
+	}
+
+	// A task is answered once.
+	if _, err := s.ProvideHumanInput(ctx, &pb.ProvideHumanInputRequest{TaskId: task.TaskId, Response: structpb.NewBoolValue(true)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ProvideHumanInput(ctx, &pb.ProvideHumanInputRequest{TaskId: task.TaskId, Response: structpb.NewBoolValue(false)}); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("expected a second answer to be rejected, got %v", err)
+	}
+	if list, _ := s.ListPendingHumanInputs(ctx, &pb.ListPendingHumanInputsRequest{}); len(list.Tasks) != 0 {
+		t.Errorf("expected no pending tasks, got %v", list.Tasks)
+	}
+}
+
+func TestHumanInputFromToolResult(t *testing.T) {
+	s := newTestServerWithTools(nil)
+	args, _ := structpb.NewStruct(map[string]interface{}{"prompt": "Approve?"})
+	s.trackPendingHumanInput("approver", args, &structpb.Struct{Fields: map[string]*structpb.Value{
+		"status":  structpb.NewStringValue("waiting_for_human"),
+		"task_id": structpb.NewStringValue("from-result"),
+	}})
+	task, _ := s.humanInputs.Get("from-result")
+	if task == nil || task.Prompt != "Approve?" || task.Creator != "approver" || task.Status != humanStatusPending {
+		t.Fatalf("expected the task to be registered from the tool result, got %+v", task)
+	}
+}
+
+func TestHumanInputExpiry(t *testing.T) {
+	s := newTestServerWithTools(nil)
+	s.config.HumanInput.ResponseTTLSeconds = 60
+	ctx := context.Background()
+	if _, err := s.RegisterHumanInput(ctx, &pb.RegisterHumanInputRequest{TaskId: "t1", Prompt: "Proceed?"}); err != nil {
+		t.Fatal(err)
+	}
+	task, _ := s.humanInputs.Get("t1")
+	task.ExpiresAt = time.Now().Add(-time.Second)
+	s.humanInputs.Put(task)
+
+	// Past its deadline the task is reported as expired, left out of the pending list and
+	// no longer accepts an answer.
+	if resp, _ := s.GetHumanInput(ctx, &pb.GetHumanInputRequest{TaskId: "t1"}); resp.Status != humanStatusExpired {
+		t.Errorf("expected the task to be expired, got %v", resp)
+	}
+	if list, _ := s.ListPendingHumanInputs(ctx, &pb.ListPendingHumanInputsRequest{}); len(list.Tasks) != 0 {
+		t.Errorf("expected no pending tasks, got %v", list.Tasks)
+	}
+	if _, err := s.ProvideHumanInput(ctx, &pb.ProvideHumanInputRequest{TaskId: "t1", Response: structpb.NewStringValue("ok")}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected the answer to an expired task to be rejected, got %v", err)
+	}
+
+	// Answered tasks are kept for the response TTL, then the cleanup removes them.
+	s.RegisterHumanInput(ctx, &pb.RegisterHumanInputRequest{TaskId: "t2", Prompt: "Proceed?"})
+	if _, err := s.ProvideHumanInput(ctx, &pb.ProvideHumanInputRequest{TaskId: "t2", Response: structpb.NewStringValue("ok")}); err != nil {
+		t.Fatal(err)
+	}
+	answered, _ := s.humanInputs.Get("t2")
+	if ttl := answered.PurgeAt.Sub(answered.CompletedAt); ttl != time.Minute {
+		t.Errorf("expected the response TTL to be applied, got %v", ttl)
+	}
+	s.purgeExpiredHumanInputs(time.Now().Add(2 * time.Minute))
+	if _, err := s.GetHumanInput(ctx, &pb.GetHumanInputRequest{TaskId: "t2"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected the purged task to be gone, got %v", err)
+	}
+}
+
+func TestHumanInputStoreConfig(t *testing.T) {
+	if _, err := newHumanInputStore(t.TempDir(), &humanInputConfig{Store: "redis"}); err == nil {
+		t.Error("expected an unsupported store to be rejected")
+	}
+	dir := t.TempDir()
+	store, err := newHumanInputStore(dir, &humanInputConfig{Store: humanStoreBolt, Path: "state/tasks.db"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+	if _, err := os.Stat(filepath.Join(dir, "state", "tasks.db")); err != nil {
+		t.Errorf("expected the store at the relative path under the project root: %v", err)
+	}
+}
+
+func TestWaitHumanInput(t *testing.T) {
+	s := newTestServerWithTools(nil)
+	ctx := context.Background()
+	if _, err := s.WaitHumanInput(ctx, &pb.WaitHumanInputRequest{TaskId: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected waiting on an unknown task to fail, got %v", err)
+	}
+	s.RegisterHumanInput(ctx, &pb.RegisterHumanInputRequest{TaskId: "t1", Prompt: "Proceed?"})
+
+	// The wait returns as soon as the response arrives.
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		s.ProvideHumanInput(ctx, &pb.ProvideHumanInputRequest{TaskId: "t1", Response: structpb.NewStringValue("ok")})
+	}()
+	start := time.Now()
+	resp, err := s.WaitHumanInput(ctx, &pb.WaitHumanInputRequest{TaskId: "t1", TimeoutSeconds: 10})
+	if err != nil || resp.Status != humanStatusCompleted || resp.Response.GetStringValue() != "ok" {
+		t.Fatalf("unexpected response: %v, %v", resp, err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the wait to end with the response, took %v", elapsed)
+	}
+
+	// A task that is already answered returns at once, and a pending one at its deadline.
+	if resp, _ := s.WaitHumanInput(ctx, &pb.WaitHumanInputRequest{TaskId: "t1"}); resp.Status != humanStatusCompleted {
+		t.Errorf("expected the completed task, got %v", resp)
+	}
+	s.RegisterHumanInput(ctx, &pb.RegisterHumanInputRequest{TaskId: "t2", Prompt: "Proceed?", TtlSeconds: 1})
+	if resp, _ := s.WaitHumanInput(ctx, &pb.WaitHumanInputRequest{TaskId: "t2", TimeoutSeconds: 10}); resp.Status != humanStatusExpired {
+		t.Errorf("expected the wait to end when the task expired, got %v", resp)
+	}
+}
+
+// fakeWatchStream collects the events sent to a WatchHumanInputs stream.
+type fakeWatchStream struct {
+	pb.MCP_WatchHumanInputsServer
+	ctx    context.Context
+	events chan *pb.HumanInputEvent
+}
+
+func (f *fakeWatchStream) Context() context.Context { return f.ctx }
+
+func (f *fakeWatchStream) Send(event *pb.HumanInputEvent) error {
+	f.events <- event
+	return nil
+}
+
+func TestWatchHumanInputs(t *testing.T) {
+	s := newTestServerWithTools(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.RegisterHumanInput(ctx, &pb.RegisterHumanInputRequest{TaskId: "before", Prompt: "Proceed?", Creator: "a"})
+
+	stream := &fakeWatchStream{ctx: ctx, events: make(chan *pb.HumanInputEvent, 10)}
+	done := make(chan error, 1)
+	go func() {
+		done <- s.WatchHumanInputs(&pb.WatchHumanInputsRequest{Creator: "a", IncludePending: true}, stream)
+	}()
+	next := func() *pb.HumanInputEvent {
+		select {
+		case event := <-stream.events:
+			return event
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for an event")
+			return nil
+		}
+	}
+	if event := next(); event.Type != humanEventPending || event.Task.TaskId != "before" {
+		t.Fatalf("expected the pending task first, got %v", event)
+	}
+
+	// Only changes to tasks of the watched creator come through.
+	s.RegisterHumanInput(ctx, &pb.RegisterHumanInputRequest{TaskId: "other", Prompt: "Proceed?", Creator: "b"})
+	s.ProvideHumanInput(ctx, &pb.ProvideHumanInputRequest{TaskId: "before", Response: structpb.NewBoolValue(true)})
+	if event := next(); event.Type != humanEventCompleted || event.Task.TaskId != "before" || !event.Response.GetBoolValue() {
+		t.Fatalf("expected the response, got %v", event)
+	}
+	s.RegisterHumanInput(ctx, &pb.RegisterHumanInputRequest{TaskId: "after", Prompt: "Proceed?", Creator: "a"})
+	s.CancelHumanInput(ctx, &pb.CancelHumanInputRequest{TaskId: "after"})
+	if event := next(); event.Type != humanEventRegistered || event.Task.TaskId != "after" {
+		t.Fatalf("expected the registration, got %v", event)
+	}
+	if event := next(); event.Type != humanEventCanceled || event.Task.Status != humanStatusCanceled {
+		t.Fatalf("expected the cancellation, got %v", event)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("expected the watch to end cleanly, got %v", err)
+	}
+	if len(s.humanWatchers) != 0 {
+		t.Errorf("expected the watcher to be removed, got %d", len(s.humanWatchers))
+	}
+}
+
+func TestHumanInputForms(t *testing.T) {
+	s := newTestServerWithTools(nil)
+	ctx := context.Background()
+	badForm, _ := structpb.NewStruct(map[string]interface{}{"type": "single_choice"})
+	if _, err := s.RegisterHumanInput(ctx, &pb.RegisterHumanInputRequest{Prompt: "Pick one", Form: badForm}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected an invalid form to be rejected, got %v", err)
+	}
+
+	form, _ := structpb.NewStruct(map[string]interface{}{"type": "number", "min": 1, "max": 5, "integer": true})
+	task, err := s.RegisterHumanInput(ctx, &pb.RegisterHumanInputRequest{TaskId: "replicas", Prompt: "How many replicas?", Form: form})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Form.AsMap()["type"] != "number" {
+		t.Errorf("expected the form on the task, got %v", task.Form)
+	}
+
+	// Answers outside the form are rejected and leave the task pending.
+	for _, answer := range []*structpb.Value{structpb.NewStringValue("3"), structpb.NewNumberValue(7), structpb.NewNumberValue(2.5)} {
+		_, err := s.ProvideHumanInput(ctx, &pb.ProvideHumanInputRequest{TaskId: "replicas", Response: answer})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("expected %v to be rejected, got %v", answer, err)
+		}
+	}
+	if _, err := s.ProvideHumanInput(ctx, &pb.ProvideHumanInputRequest{TaskId: "replicas", Response: structpb.NewNumberValue(3)}); err != nil {
+		t.Fatalf("expected a valid answer to be accepted, got %v", err)
+	}
+
+	// Forms from tool arguments apply to tasks the server registers from the result.
+	args, _ := structpb.NewStruct(map[string]interface{}{"prompt": "Deploy?", "form": map[string]interface{}{"type": "approval"}})
+	s.trackPendingHumanInput("approver", args, &structpb.Struct{Fields: map[string]*structpb.Value{
+		"status":  structpb.NewStringValue("waiting_for_human"),
+		"task_id": structpb.NewStringValue("deploy"),
+	}})
+	if _, err := s.ProvideHumanInput(ctx, &pb.ProvideHumanInputRequest{TaskId: "deploy", Response: structpb.NewBoolValue(true)}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected a bare boolean to be rejected for an approval form, got %v", err)
+	}
+}
@@ -0,0 +1,170 @@
+// File: MCP-NG/server/cmd/server/humaninput_watch.go
+package main
+
+import (
+	"context"
+	"time"
+
+	pb "mcp-ng/server/pkg/mcp"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Types of human-input events.
+const (
+	humanEventPending    = "pending" // Sent at the start of a watch for tasks already waiting
+	humanEventRegistered = "registered"
+	humanEventCompleted  = "completed"
+	humanEventCanceled   = "canceled"
+	humanEventExecuted   = "executed" // An approved tool call finished
+)
+
+const (
+	defaultHumanWaitTimeout = 30 * time.Second
+	maxHumanWaitTimeout     = 5 * time.Minute
+	humanWatcherBuffer      = 64
+)
+
+// humanWatcher receives the events of the tasks it selects. A watcher that falls behind is
+// dropped and its channel closed rather than silently missing a response.
+type humanWatcher struct {
+	events  chan *pb.HumanInputEvent
+	creator string
+	taskIDs map[string]bool
+}
+
+func (w *humanWatcher) match(task *humanTask) bool {
+	if w.creator != "" && task.Creator != w.creator {
+		return false
+	}
+	return len(w.taskIDs) == 0 || w.taskIDs[task.TaskID]
+}
+
+// watchHumanInputs adds a watcher. The caller must hold s.humanMu, so that what it read from
+// the store and the events that follow line up without gaps.
+func (s *server) watchHumanInputs(creator string, taskIDs ...string) *humanWatcher {
+	w := &humanWatcher{events: make(chan *pb.HumanInputEvent, humanWatcherBuffer), creator: creator}
+	if len(taskIDs) > 0 {
+		w.taskIDs = make(map[string]bool, len(taskIDs))
+		for _, id := range taskIDs {
+			w.taskIDs[id] = true
+		}
+	}
+	if s.humanWatchers == nil {
+		s.humanWatchers = make(map[*humanWatcher]struct{})
+	}
+	s.humanWatchers[w] = struct{}{}
+	return w
+}
+
+func (s *server) unwatchHumanInputs(w *humanWatcher) {
+	s.humanMu.Lock()
+	delete(s.humanWatchers, w)
+	s.humanMu.Unlock()
+}
+
+// publishHumanEvent hands a task change to the watchers. The caller must hold s.humanMu.
+func (s *server) publishHumanEvent(eventType string, task *humanTask, response *structpb.Value, now time.Time) {
+	event := &pb.HumanInputEvent{Type: eventType, Task: task.proto(now), Response: response}
+	for w := range s.humanWatchers {
+		if !w.match(task) {
+			continue
+		}
+		select {
+		case w.events <- event:
+		default:
+			delete(s.humanWatchers, w)
+			close(w.events)
+		}
+	}
+}
+
+// WaitHumanInput blocks until the task is settled or the timeout passes.
+func (s *server) WaitHumanInput(ctx context.Context, in *pb.WaitHumanInputRequest) (*pb.GetHumanInputResponse, error) {
+	if in.TaskId == "" {
+		return nil, status.Error(codes.InvalidArgument, "task_id cannot be empty")
+	}
+	timeout := defaultHumanWaitTimeout
+	if in.TimeoutSeconds < 0 {
+		return nil, status.Error(codes.InvalidArgument, "timeout_seconds must not be negative")
+	} else if in.TimeoutSeconds > 0 {
+		timeout = min(time.Duration(in.TimeoutSeconds)*time.Second, maxHumanWaitTimeout)
+	}
+
+	s.humanMu.Lock()
+	task, err := s.humanInputs.Get(in.TaskId)
+	if err != nil || task == nil || task.settled(time.Now()) {
+		s.humanMu.Unlock()
+		return s.GetHumanInput(ctx, &pb.GetHumanInputRequest{TaskId: in.TaskId})
+	}
+	w := s.watchHumanInputs("", in.TaskId)
+	s.humanMu.Unlock()
+	defer s.unwatchHumanInputs(w)
+
+	// Expiry is not an event, so stop waiting at the task's deadline as well.
+	timer := time.NewTimer(min(timeout, time.Until(task.ExpiresAt)+time.Millisecond))
+	defer timer.Stop()
+	for {
+		select {
+		case event, ok := <-w.events:
+			if ok && event.Task.Status == humanStatusCompleted && event.Task.Approval.GetStatus() == approvalRunning {
+				continue // The approved call is still running.
+			}
+		case <-timer.C:
+		case <-s.shutdown:
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+		return s.GetHumanInput(ctx, &pb.GetHumanInputRequest{TaskId: in.TaskId})
+	}
+}
+
+// WatchHumanInputs streams task changes until the client disconnects.
+func (s *server) WatchHumanInputs(in *pb.WatchHumanInputsRequest, stream pb.MCP_WatchHumanInputsServer) error {
+	ctx := stream.Context()
+	s.humanMu.Lock()
+	var backlog []*pb.HumanInputEvent
+	if in.IncludePending {
+		tasks, err := s.humanInputs.List(humanStatusPending)
+		if err != nil {
+			s.humanMu.Unlock()
+			return status.Errorf(codes.Internal, "failed to list tasks: %v", err)
+		}
+		now := time.Now()
+		for _, task := range tasks {
+			if task.expired(now) {
+				continue
+			}
+			backlog = append(backlog, &pb.HumanInputEvent{Type: humanEventPending, Task: task.proto(now)})
+		}
+	}
+	w := s.watchHumanInputs(in.Creator, in.TaskIds...)
+	s.humanMu.Unlock()
+	defer s.unwatchHumanInputs(w)
+
+	for _, event := range backlog {
+		if !w.match(&humanTask{TaskID: event.Task.TaskId, Creator: event.Task.Creator}) {
+			continue
+		}
+		if err := stream.Send(event); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.shutdown:
+			return nil
+		case event, ok := <-w.events:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "The watcher fell behind and missed events; watch again with include_pending.")
+			}
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,542 @@
+// File: MCP-NG/server/cmd/server/logs.go
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "mcp-ng/server/pkg/mcp"
+
+	grpcRuntime "github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// toolLogsConfig configures how the stdout and stderr of launched tools are captured.
+type toolLogsConfig struct {
+	// BufferLines is how many recent lines are kept in memory per tool. Defaults to 1000.
+	BufferLines int `json:"buffer_lines"`
+	// Dir, when set, also writes every tool's lines to <dir>/<tool>.log as JSON lines;
+	// relative paths are resolved against the project root.
+	Dir string `json:"dir"`
+	// MaxBytes rotates a tool's log file once it would grow beyond this size. Defaults to 10 MiB.
+	MaxBytes int64 `json:"max_bytes"`
+	// MaxFiles is how many rotated files (<tool>.log.1, .2, ...) are kept. Defaults to 5.
+	MaxFiles int `json:"max_files"`
+	// Forward also writes the lines to the server's own log, tagged with the tool and PID.
+	// Defaults to true.
+	Forward *bool `json:"forward"`
+}
+
+const (
+	defaultLogBufferLines = 1000
+	defaultLogMaxBytes    = 10 << 20
+	defaultLogMaxFiles    = 5
+	// logSubscriberBuffer is how many lines a follower may fall behind before lines are
+	// dropped for it.
+	logSubscriberBuffer = 256
+)
+
+// Log levels of captured lines, in increasing severity.
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+// levelPattern finds the level in plain-text lines, such as those of Python's logging.
+var levelPattern = regexp.MustCompile(`\b(DEBUG|INFO|WARN|WARNING|ERROR|CRITICAL|FATAL)\b`)
+
+// logLine is one line a tool wrote.
+type logLine struct {
+	Time   time.Time `json:"time"`
+	Tool   string    `json:"tool"`
+	PID    int       `json:"pid"`
+	Stream string    `json:"stream"`
+	Level  string    `json:"level"`
+	Line   string    `json:"line"`
+}
+
+func (l logLine) proto() *pb.ToolLogLine {
+	return &pb.ToolLogLine{
+		ToolName:   l.Tool,
+		Pid:        int32(l.PID),
+		Stream:     l.Stream,
+		Level:      l.Level,
+		TimeUnixMs: l.Time.UnixMilli(),
+		Line:       l.Line,
+	}
+}
+
+// toolLogs holds the captured output of one tool, across all its processes.
+type toolLogs struct {
+	name    string
+	forward bool
+
+	mu    sync.Mutex
+	lines []logLine // Ring buffer
+	next  int       // Where the next line goes
+	full  bool      // Whether the buffer has wrapped
+	file  *rotatingFile
+	subs  map[chan logLine]struct{}
+}
+
+// newToolLogs sets up the log capture of a tool. A log file that cannot be opened is
+// reported and skipped; the in-memory buffer always works.
+func (s *server) newToolLogs(name string) *toolLogs {
+	cfg := s.config.ToolLogs
+	l := &toolLogs{
+		name:    name,
+		forward: cfg.Forward == nil || *cfg.Forward,
+		subs:    make(map[chan logLine]struct{}),
+	}
+	size := cfg.BufferLines
+	if size <= 0 {
+		size = defaultLogBufferLines
+	}
+	l.lines = make([]logLine, size)
+	if cfg.Dir != "" {
+		dir := cfg.Dir
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(s.projectRoot, dir)
+		}
+		file, err := openRotatingFile(filepath.Join(dir, name+".log"), cfg.MaxBytes, cfg.MaxFiles)
+		if err != nil {
+			logger.Warn("Failed to open tool log file, keeping logs in memory only", "tool", name, "error", err)
+		} else {
+			l.file = file
+		}
+	}
+	return l
+}
+
+// logCapture connects a tool process's stdout and stderr to its toolLogs.
+type logCapture struct {
+	logs   *toolLogs
+	pipes  [2]*os.File // Read ends: stdout, stderr
+	writes [2]*os.File // Write ends, handed to the process
+}
+
+// attach redirects the output of cmd into the tool's logs. The caller must call started
+// after cmd.Start succeeded, or abort if it failed.
+func (l *toolLogs) attach(cmd *exec.Cmd) (*logCapture, error) {
+	c := &logCapture{logs: l}
+	for i := range c.pipes {
+		r, w, err := os.Pipe()
+		if err != nil {
+			c.abort()
+			return nil, err
+		}
+		c.pipes[i], c.writes[i] = r, w
+	}
+	cmd.Stdout, cmd.Stderr = c.writes[0], c.writes[1]
+	return c, nil
+}
+
+// started begins reading the output of the process with the given PID.
+func (c *logCapture) started(pid int) {
+	for i, stream := range []string{"stdout", "stderr"} {
+		c.writes[i].Close() // The process has its own copy
+		go c.logs.read(c.pipes[i], pid, stream)
+	}
+}
+
+// abort closes the pipes of a process that did not start.
+func (c *logCapture) abort() {
+	for _, f := range append(c.pipes[:], c.writes[:]...) {
+		if f != nil {
+			f.Close()
+		}
+	}
+}
+
+// read captures lines from r until the process and its children close it.
+func (l *toolLogs) read(r io.ReadCloser, pid int, stream string) {
+	defer r.Close()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		text := scanner.Text()
+		l.add(logLine{Time: time.Now(), Tool: l.name, PID: pid, Stream: stream, Level: parseLevel(text), Line: text})
+	}
+}
+
+// add records a line and hands it to the file, the followers and the server's log.
+func (l *toolLogs) add(line logLine) {
+	l.mu.Lock()
+	l.lines[l.next] = line
+	l.next = (l.next + 1) % len(l.lines)
+	if l.next == 0 {
+		l.full = true
+	}
+	if l.file != nil {
+		if err := l.file.writeLine(line); err != nil {
+			logger.Warn("Failed to write tool log file", "tool", l.name, "error", err)
+		}
+	}
+	for sub := range l.subs {
+		select {
+		case sub <- line:
+		default: // The follower is too slow; it misses this line.
+		}
+	}
+	l.mu.Unlock()
+
+	if l.forward {
+		logger.Log(context.Background(), slogLevel(line.Level), "Tool output", "tool", line.Tool, "pid", line.PID, "stream", line.Stream, "line", line.Line)
+	}
+}
+
+// subscribe returns the buffered lines, oldest first, and, with follow set, a channel that
+// receives every later line. Both are taken under one lock so no line is missed or repeated.
+func (l *toolLogs) subscribe(follow bool) ([]logLine, chan logLine) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var backlog []logLine
+	if l.full {
+		backlog = append(backlog, l.lines[l.next:]...)
+	}
+	backlog = append(backlog, l.lines[:l.next]...)
+	if !follow {
+		return backlog, nil
+	}
+	sub := make(chan logLine, logSubscriberBuffer)
+	l.subs[sub] = struct{}{}
+	return backlog, sub
+}
+
+func (l *toolLogs) unsubscribe(sub chan logLine) {
+	if sub == nil {
+		return
+	}
+	l.mu.Lock()
+	delete(l.subs, sub)
+	l.mu.Unlock()
+}
+
+// parseLevel finds the level of a line: the "level" field of a JSON line, as written by the
+// Go tools, or the first level word in a plain-text line. Lines without one count as info.
+func parseLevel(text string) string {
+	var level string
+	if strings.HasPrefix(text, "{") {
+		var entry struct {
+			Level string `json:"level"`
+		}
+		if json.Unmarshal([]byte(text), &entry) == nil {
+			level = entry.Level
+		}
+	}
+	if level == "" {
+		level = levelPattern.FindString(text)
+	}
+	switch strings.ToUpper(level) {
+	case "DEBUG":
+		return "debug"
+	case "WARN", "WARNING":
+		return "warn"
+	case "ERROR", "CRITICAL", "FATAL":
+		return "error"
+	default:
+		return "info"
+	}
+}
+
+// levelRank orders levels by severity; unknown levels rank lowest.
+func levelRank(level string) int {
+	for i, l := range logLevels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
+
+func slogLevel(level string) slog.Level {
+	switch level {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// logFilter selects the lines a StreamToolLogs request asked for.
+type logFilter struct {
+	minRank      int
+	since, until time.Time
+}
+
+func newLogFilter(in *pb.StreamToolLogsRequest) (logFilter, error) {
+	f := logFilter{}
+	if in.MinLevel != "" {
+		if f.minRank = levelRank(in.MinLevel); f.minRank < 0 {
+			return f, status.Errorf(codes.InvalidArgument, "min_level must be one of %s", strings.Join(logLevels, ", "))
+		}
+	}
+	if in.SinceUnixMs > 0 {
+		f.since = time.UnixMilli(in.SinceUnixMs)
+	}
+	if in.UntilUnixMs > 0 {
+		f.until = time.UnixMilli(in.UntilUnixMs)
+	}
+	if in.Tail < 0 {
+		return f, status.Error(codes.InvalidArgument, "tail must not be negative")
+	}
+	return f, nil
+}
+
+func (f logFilter) match(l logLine) bool {
+	return levelRank(l.Level) >= f.minRank &&
+		(f.since.IsZero() || !l.Time.Before(f.since)) &&
+		(f.until.IsZero() || !l.Time.After(f.until))
+}
+
+// StreamToolLogs streams a tool's captured output.
+func (s *server) StreamToolLogs(in *pb.StreamToolLogsRequest, stream pb.MCP_StreamToolLogsServer) error {
+	return s.streamToolLogs(stream.Context(), in, stream.Send)
+}
+
+// streamToolLogs sends the lines of a tool that pass the request's filters: the buffered
+// ones, then new ones while following. It backs both the RPC and the SSE route.
+func (s *server) streamToolLogs(ctx context.Context, in *pb.StreamToolLogsRequest, send func(*pb.ToolLogLine) error) error {
+	logs := s.findToolLogs(in.ToolName)
+	if logs == nil {
+		return status.Errorf(codes.NotFound, "No logs for tool '%s'.", in.ToolName)
+	}
+	filter, err := newLogFilter(in)
+	if err != nil {
+		return err
+	}
+	backlog, sub := logs.subscribe(in.Follow)
+	defer logs.unsubscribe(sub)
+
+	var matched []logLine
+	for _, line := range backlog {
+		if filter.match(line) {
+			matched = append(matched, line)
+		}
+	}
+	if in.Tail > 0 && len(matched) > int(in.Tail) {
+		matched = matched[len(matched)-int(in.Tail):]
+	}
+	for _, line := range matched {
+		if err := send(line.proto()); err != nil {
+			return err
+		}
+	}
+	if sub == nil {
+		return nil
+	}
+
+	// A follower with an end time stops once it has passed.
+	var deadline <-chan time.Time
+	if !filter.until.IsZero() {
+		timer := time.NewTimer(time.Until(filter.until))
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-deadline:
+			return nil
+		case line := <-sub:
+			if filter.match(line) {
+				if err := send(line.proto()); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// handleToolLogsSSE streams a tool's logs as server-sent events, one ToolLogLine in JSON per
+// event, for browsers and curl. The query parameters level, since, until, tail and follow
+// mirror StreamToolLogsRequest; since and until take an RFC 3339 time or a duration ago,
+// such as 10m.
+func (s *server) handleToolLogsSSE(mux *grpcRuntime.ServeMux) grpcRuntime.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		_, marshaler := grpcRuntime.MarshalerForRequest(mux, r)
+		in, err := parseLogQuery(pathParams["name"], r)
+		if err != nil {
+			grpcRuntime.HTTPError(r.Context(), mux, marshaler, w, r, err)
+			return
+		}
+		flusher, _ := w.(http.Flusher)
+		wroteHeader := false
+		err = s.streamToolLogs(r.Context(), in, func(line *pb.ToolLogLine) error {
+			if !wroteHeader {
+				w.Header().Set("Content-Type", "text/event-stream")
+				w.Header().Set("Cache-Control", "no-cache")
+				wroteHeader = true
+			}
+			data, err := protojson.Marshal(line)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+			return nil
+		})
+		if err != nil && !wroteHeader {
+			grpcRuntime.HTTPError(r.Context(), mux, marshaler, w, r, err)
+			return
+		}
+		if !wroteHeader {
+			// Nothing matched; still answer with an empty event stream.
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.WriteHeader(http.StatusOK)
+		}
+	}
+}
+
+// parseLogQuery builds a StreamToolLogsRequest from the query of an SSE request.
+func parseLogQuery(name string, r *http.Request) (*pb.StreamToolLogsRequest, error) {
+	q := r.URL.Query()
+	in := &pb.StreamToolLogsRequest{ToolName: name, MinLevel: q.Get("level")}
+	var err error
+	if in.SinceUnixMs, err = parseLogTime(q.Get("since")); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid since: %v", err)
+	}
+	if in.UntilUnixMs, err = parseLogTime(q.Get("until")); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid until: %v", err)
+	}
+	if v := q.Get("tail"); v != "" {
+		tail, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid tail: %v", err)
+		}
+		in.Tail = int32(tail)
+	}
+	if v := q.Get("follow"); v != "" {
+		if in.Follow, err = strconv.ParseBool(v); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid follow: %v", err)
+		}
+	}
+	return in, nil
+}
+
+// parseLogTime reads an RFC 3339 time or a duration before now, in Unix milliseconds.
+func parseLogTime(v string) (int64, error) {
+	if v == "" {
+		return 0, nil
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return time.Now().Add(-d).UnixMilli(), nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return 0, err
+	}
+	return t.UnixMilli(), nil
+}
+
+// findToolLogs looks a tool's logs up by its registered name or its directory name.
+func (s *server) findToolLogs(name string) *toolLogs {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if tool, ok := s.tools[name]; ok && tool.logs != nil {
+		return tool.logs
+	}
+	for _, tool := range s.discovered {
+		if tool.logs != nil && tool.logs.name == name {
+			return tool.logs
+		}
+	}
+	return nil
+}
+
+// rotatingFile is an append-only JSON-lines file that is rotated by size.
+type rotatingFile struct {
+	path     string
+	maxBytes int64
+	maxFiles int
+	f        *os.File
+	size     int64
+}
+
+func openRotatingFile(path string, maxBytes int64, maxFiles int) (*rotatingFile, error) {
+	if maxBytes <= 0 {
+		maxBytes = defaultLogMaxBytes
+	}
+	if maxFiles <= 0 {
+		maxFiles = defaultLogMaxFiles
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	r := &rotatingFile{path: path, maxBytes: maxBytes, maxFiles: maxFiles}
+	return r, r.open()
+}
+
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f, r.size = f, info.Size()
+	return nil
+}
+
+func (r *rotatingFile) writeLine(line logLine) error {
+	data, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if r.size > 0 && r.size+int64(len(data)) > r.maxBytes {
+		if err := r.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := r.f.Write(data)
+	r.size += int64(n)
+	return err
+}
+
+// rotate shifts <path>.N to <path>.N+1, dropping the oldest, and starts a new file.
+func (r *rotatingFile) rotate() error {
+	r.f.Close()
+	os.Remove(fmt.Sprintf("%s.%d", r.path, r.maxFiles))
+	for i := r.maxFiles - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+	}
+	if err := os.Rename(r.path, r.path+".1"); err != nil {
+		return err
+	}
+	return r.open()
+}
+
+// toolLogsFor returns the logs of a discovered tool, creating them on its first launch.
+func (s *server) toolLogsFor(tool *toolClient) *toolLogs {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if tool.logs == nil {
+		tool.logs = s.newToolLogs(filepath.Base(tool.dir))
+	}
+	return tool.logs
+}
@@ -0,0 +1,202 @@
+// File: MCP-NG/server/cmd/server/logs_test.go
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	pb "mcp-ng/server/pkg/mcp"
+
+	grpcRuntime "github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseLevel(t *testing.T) {
+	cases := map[string]string{
+		`{"time":"2024-01-01T00:00:00Z","level":"WARN","msg":"slow"}`: "warn",
+		`{"level":"DEBUG","msg":"details"}`:                           "debug",
+		"2024-01-01 00:00:00 - ERROR - something broke":               "error",
+		"CRITICAL: out of memory":                                     "error",
+		"WARNING:root:deprecated":                                     "warn",
+		"Server started on port 50051":                                "info",
+		`{"msg":"no level"}`:                                          "info",
+	}
+	for line, want := range cases {
+		if got := parseLevel(line); got != want {
+			t.Errorf("parseLevel(%q) = %q, expected %q", line, got, want)
+		}
+	}
+}
+
+// collectLogs runs streamToolLogs and returns the lines it sent.
+func collectLogs(t *testing.T, s *server, in *pb.StreamToolLogsRequest) []*pb.ToolLogLine {
+	t.Helper()
+	var lines []*pb.ToolLogLine
+	err := s.streamToolLogs(context.Background(), in, func(l *pb.ToolLogLine) error {
+		lines = append(lines, l)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("streamToolLogs failed: %v", err)
+	}
+	return lines
+}
+
+// newLoggingServer returns a server with an "echo" tool whose logs keep the last three lines.
+func newLoggingServer() (*server, *toolLogs) {
+	s := newTestServerWithTools(map[string]*fakeToolClient{"echo": {}})
+	s.config.ToolLogs.BufferLines = 3
+	forward := false
+	s.config.ToolLogs.Forward = &forward
+	logs := s.newToolLogs("echo")
+	s.tools["echo"].logs = logs
+	return s, logs
+}
+
+func TestStreamToolLogsFilters(t *testing.T) {
+	s, logs := newLoggingServer()
+	start := time.Now()
+	for i, level := range []string{"info", "debug", "error", "warn", "info"} {
+		logs.add(logLine{Time: start.Add(time.Duration(i) * time.Second), Tool: "echo", PID: 42, Stream: "stdout", Level: level, Line: level})
+	}
+
+	lines := collectLogs(t, s, &pb.StreamToolLogsRequest{ToolName: "echo"})
+	if len(lines) != 3 || lines[0].Line != "error" || lines[2].Line != "info" {
+		t.Fatalf("expected the last three lines, oldest first, got %v", lines)
+	}
+	if lines := collectLogs(t, s, &pb.StreamToolLogsRequest{ToolName: "echo", MinLevel: "warn"}); len(lines) != 2 {
+		t.Errorf("expected two lines at warn or above, got %v", lines)
+	}
+	since := start.Add(3 * time.Second).UnixMilli()
+	if lines := collectLogs(t, s, &pb.StreamToolLogsRequest{ToolName: "echo", SinceUnixMs: since}); len(lines) != 2 {
+		t.Errorf("expected two lines since the fourth, got %v", lines)
+	}
+	if lines := collectLogs(t, s, &pb.StreamToolLogsRequest{ToolName: "echo", Tail: 1}); len(lines) != 1 || lines[0].Line != "info" {
+		t.Errorf("expected only the last line, got %v", lines)
+	}
+
+	err := s.streamToolLogs(context.Background(), &pb.StreamToolLogsRequest{ToolName: "missing"}, nil)
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for an unknown tool, got %v", err)
+	}
+	err = s.streamToolLogs(context.Background(), &pb.StreamToolLogsRequest{ToolName: "echo", MinLevel: "loud"}, nil)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for an unknown level, got %v", err)
+	}
+}
+
+func TestStreamToolLogsFollows(t *testing.T) {
+	s, logs := newLoggingServer()
+	ctx, cancel := context.WithCancel(context.Background())
+	got := make(chan string, 1)
+	done := make(chan error)
+	go func() {
+		done <- s.streamToolLogs(ctx, &pb.StreamToolLogsRequest{ToolName: "echo", Follow: true}, func(l *pb.ToolLogLine) error {
+			got <- l.Line
+			return nil
+		})
+	}()
+
+	// Keep writing until the follower has subscribed and receives a line.
+	deadline := time.After(5 * time.Second)
+	for received := false; !received; {
+		logs.add(logLine{Time: time.Now(), Tool: "echo", Level: "info", Line: "hello"})
+		select {
+		case line := <-got:
+			if line != "hello" {
+				t.Fatalf("unexpected line %q", line)
+			}
+			received = true
+		case <-time.After(10 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("the follower received no line")
+		}
+	}
+	cancel()
+	for {
+		select {
+		case <-got: // Lines written before the follower stopped
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("streamToolLogs failed: %v", err)
+			}
+			return
+		}
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "echo.log")
+	f, err := openRotatingFile(path, 200, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := f.writeLine(logLine{Tool: "echo", Line: strings.Repeat("x", 50)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"echo.log", "echo.log.1", "echo.log.2"} {
+		info, err := os.Stat(filepath.Join(filepath.Dir(path), name))
+		if err != nil {
+			t.Fatalf("expected %s to exist: %v", name, err)
+		}
+		if info.Size() > 200 {
+			t.Errorf("expected %s to stay within the size limit, got %d bytes", name, info.Size())
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only two rotated files to be kept, got %v", err)
+	}
+}
+
+func TestToolLogsSSE(t *testing.T) {
+	s, logs := newLoggingServer()
+	logs.add(logLine{Time: time.Now(), Tool: "echo", PID: 7, Stream: "stderr", Level: "error", Line: "boom"})
+	logs.add(logLine{Time: time.Now(), Tool: "echo", PID: 7, Stream: "stdout", Level: "info", Line: "fine"})
+
+	handler := s.handleToolLogsSSE(grpcRuntime.NewServeMux())
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("GET", "/v1/tools/echo/logs/stream?level=error", nil), map[string]string{"name": "echo"})
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected an event stream, got %q", ct)
+	}
+	body := rec.Body.String()
+	if !strings.HasPrefix(body, "data: {") || !strings.Contains(body, `"line":"boom"`) || strings.Contains(body, "fine") {
+		t.Errorf("unexpected events: %q", body)
+	}
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("GET", "/v1/tools/echo/logs/stream?tail=x", nil), map[string]string{"name": "echo"})
+	if rec.Code != 400 {
+		t.Errorf("expected 400 for an invalid tail, got %d", rec.Code)
+	}
+}
+
+func TestToolOutputIsCaptured(t *testing.T) {
+	s, tool := newLazyCalculator(t, `{"command": ["calculator"], "lifecycle": "lazy"}`)
+	s.config.ToolLogs.Dir = t.TempDir()
+	calculate(t, s)
+	pid := int32(tool.replicas[0].cmd.Process.Pid)
+
+	var lines []*pb.ToolLogLine
+	for deadline := time.Now().Add(5 * time.Second); len(lines) == 0 && time.Now().Before(deadline); {
+		time.Sleep(50 * time.Millisecond)
+		lines = collectLogs(t, s, &pb.StreamToolLogsRequest{ToolName: "calculator"})
+	}
+	if len(lines) == 0 {
+		t.Fatal("expected the tool's output to be captured")
+	}
+	if lines[0].ToolName != filepath.Base(tool.dir) || lines[0].Pid != pid || lines[0].Stream != "stdout" {
+		t.Errorf("unexpected attribution: %v", lines[0])
+	}
+	if _, err := os.Stat(filepath.Join(s.config.ToolLogs.Dir, filepath.Base(tool.dir)+".log")); err != nil {
+		t.Errorf("expected the tool's log file: %v", err)
+	}
+}
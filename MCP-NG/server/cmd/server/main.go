@@ -13,15 +13,20 @@ import (
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"syscall"
 	"time"
 
 	pb "mcp-ng/server/pkg/mcp"
+	"mcp-ng/server/pkg/telemetry"
+	"mcp-ng/server/pkg/toolkit"
 
 	grpcRuntime "github.com/grpc-ecosystem/grpc-gateway/v2/runtime" // <-- ИЗМЕНЕНИЕ 1
 	"github.com/rs/cors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -36,39 +41,138 @@ var logger *slog.Logger
 
 // serverConfig holds the port configuration for the servers.
 type serverConfig struct {
-	GrpcPort int `json:"grpc_port"`
-	HttpPort int `json:"http_port"`
+	GrpcPort int              `json:"grpc_port"`
+	HttpPort int              `json:"http_port"`
+	Tracing  telemetry.Config `json:"tracing"`
+	// WorkflowsDir holds stored workflow definitions; relative paths are resolved against the
+	// project root. Defaults to MCP-NG/workflows.
+	WorkflowsDir string `json:"workflows_dir"`
+	// Agent configures the chat model behind RunAgent.
+	Agent agentConfig `json:"agent"`
+	// ToolTransport is how launched tools are reached: "tcp" (a free loopback port, the
+	// default) or "unix" (a Unix socket in a temporary directory).
+	ToolTransport string `json:"tool_transport"`
+	// ToolLogs configures the capture of the tools' stdout and stderr.
+	ToolLogs toolLogsConfig `json:"tool_logs"`
+	// ShutdownGraceSeconds is how long running calls may take to finish when the server shuts
+	// down, and how long a stopped tool may take to exit after SIGTERM before it is killed.
+	// Defaults to 10.
+	ShutdownGraceSeconds int `json:"shutdown_grace_seconds"`
+	// HumanInput configures where human-input tasks are stored and for how long.
+	HumanInput humanInputConfig `json:"human_input"`
+	// ApprovalPolicies hold calls of the matching tools back until an operator approves
+	// them, in addition to each tool's own requires_approval setting.
+	ApprovalPolicies []approvalPolicy `json:"approval_policies"`
+	// ToolCallTimeoutSeconds bounds each tool call, unless the tool sets its own
+	// call_timeout_seconds. Zero leaves only the caller's deadline.
+	ToolCallTimeoutSeconds int `json:"tool_call_timeout_seconds"`
 }
 
+// defaultShutdownGrace is used when shutdown_grace_seconds is not set.
+const defaultShutdownGrace = 10 * time.Second
+
+// shutdownGrace returns the configured grace period.
+func (c *serverConfig) shutdownGrace() time.Duration {
+	if c.ShutdownGraceSeconds <= 0 {
+		return defaultShutdownGrace
+	}
+	return time.Duration(c.ShutdownGraceSeconds) * time.Second
+}
+
+// Tool transports accepted in the server's config.json.
+const (
+	transportTCP  = "tcp"
+	transportUnix = "unix"
+)
+
 // toolClient holds the client connection and description for a tool.
 type toolClient struct {
-	client       pb.ToolClient
-	healthClient grpc_health_v1.HealthClient
-	description  *pb.ToolDescription
-	status       grpc_health_v1.HealthCheckResponse_ServingStatus
+	description *pb.ToolDescription
+	status      grpc_health_v1.HealthCheckResponse_ServingStatus // SERVING while any replica is
+	cache       resultCache                                      // nil when result caching is disabled for the tool
+	replicas    []*toolReplica                                   // Empty until the tool is started
+	next        int                                              // Where pickReplica starts looking
+
+	// Where and how the tool is run. Only set for discovered tools.
+	dir        string
+	config     toolConfig
+	configFile []byte
+	manifest   *toolManifest // nil when the tool has no manifest.json
+	// startOnCall marks a tool registered from its manifest whose process has not been
+	// started yet. Its first call starts it.
+	startOnCall bool
+	startMu     sync.Mutex // Serializes starting and stopping the tool process
+	starts      int        // Successful starts; all but the first are restarts
+
+	// Used to stop the tool when it has been idle for too long.
+	inflight int       // Calls currently running on the tool
+	lastUsed time.Time // When the last call finished, or the tool started
+
+	// Reported by GetToolStatus.
+	lastError string   // Why the last start failed; empty after a successful start
+	warnings  []string // E.g. sandbox settings that could not be applied
+
+	logs *toolLogs // Output of the tool's processes; created when the first one is launched
+}
+
+// available reports whether calls can be routed to the tool: it is healthy, or it has not
+// been started yet and will be on its first call. The caller must hold s.mu.
+func (t *toolClient) available() bool {
+	return t.status == grpc_health_v1.HealthCheckResponse_SERVING || t.startOnCall
 }
 
 // server is used to implement the mcp.MCPServer interface.
 type server struct {
 	pb.UnimplementedMCPServer
-	mu          sync.RWMutex
-	tools       map[string]*toolClient
-	toolCmds    []*exec.Cmd
-	humanInputs map[string]*pb.GetHumanInputResponse // In-memory store for human responses
-	shutdown    chan struct{}
+	config       *serverConfig
+	mu           sync.RWMutex
+	tools        map[string]*toolClient
+	toolCmds     []*exec.Cmd
+	humanInputs  humanInputStore // Tasks handed out by human-input tools and their responses
+	humanMu      sync.Mutex      // Serializes read-modify-write sequences on humanInputs
+	projectRoot  string
+	workflowsDir string
+	socketDir    string // Holds the tools' Unix sockets; created on first use
+	// discovered holds every tool found on disk that is not disabled, whether or not it
+	// started, in discovery order.
+	discovered []*toolClient
+	shutdown   chan struct{}
+	// calls counts running tool calls; once draining is set, no new ones are accepted.
+	calls    sync.WaitGroup
+	draining bool
+	// humanWatchers receive human-input task changes; guarded by humanMu.
+	humanWatchers map[*humanWatcher]struct{}
 }
 
 // newServer creates a new server instance. It accepts the project's root path
 // to reliably locate tool directories, regardless of where the binary is run from.
-func newServer(projectRoot string) *server {
+func newServer(projectRoot string, config *serverConfig) *server {
 	s := &server{
-		tools:       make(map[string]*toolClient),
-		toolCmds:    make([]*exec.Cmd, 0),
-		humanInputs: make(map[string]*pb.GetHumanInputResponse),
-		shutdown:    make(chan struct{}),
+		config:   config,
+		tools:    make(map[string]*toolClient),
+		toolCmds: make([]*exec.Cmd, 0),
+		shutdown: make(chan struct{}),
+
+		projectRoot:  projectRoot,
+		workflowsDir: filepath.Join(projectRoot, "MCP-NG", "workflows"),
 	}
-	s.discoverAndRunTools(projectRoot) // Pass the root path down
+	store, err := newHumanInputStore(projectRoot, &config.HumanInput)
+	if err != nil {
+		logger.Error("Failed to open the human input store", "store", config.HumanInput.Store, "error", err)
+		os.Exit(1)
+	}
+	s.humanInputs = store
+	s.failInterruptedApprovals()
+	if config.WorkflowsDir != "" {
+		s.workflowsDir = config.WorkflowsDir
+		if !filepath.IsAbs(s.workflowsDir) {
+			s.workflowsDir = filepath.Join(projectRoot, s.workflowsDir)
+		}
+	}
+	s.registerServerMetrics()
+	s.discoverAndRunTools()
 	s.startHealthChecks()
+	s.startHumanInputCleanup()
 	return s
 }
 
@@ -93,33 +197,68 @@ func loadConfig() *serverConfig {
 		config.HttpPort = 8002
 	}
 
+	switch config.ToolTransport {
+	case "", transportTCP, transportUnix:
+	default:
+		logger.Warn("Unknown tool_transport, using tcp", "tool_transport", config.ToolTransport)
+		config.ToolTransport = transportTCP
+	}
+	if config.ShutdownGraceSeconds < 0 {
+		logger.Warn("Negative shutdown_grace_seconds, using the default", "shutdown_grace_seconds", config.ShutdownGraceSeconds)
+		config.ShutdownGraceSeconds = 0
+	}
+	if config.ToolCallTimeoutSeconds < 0 {
+		logger.Warn("Negative tool_call_timeout_seconds, not bounding tool calls", "tool_call_timeout_seconds", config.ToolCallTimeoutSeconds)
+		config.ToolCallTimeoutSeconds = 0
+	}
+
 	logger.Info("Loaded server configuration", "grpc_port", config.GrpcPort, "http_port", config.HttpPort)
 	return config
 }
 
+// Tool lifecycles accepted in a tool's config.json.
+const (
+	lifecycleEager    = "eager"    // Started with the server (the default)
+	lifecycleLazy     = "lazy"     // Started on its first call; requires a manifest.json
+	lifecycleDisabled = "disabled" // Not started or listed at all
+)
+
 // toolConfig defines the structure of the config.json file for each tool.
 type toolConfig struct {
-	Port    int      `json:"port"`
-	Command []string `json:"command"`
+	Port      int          `json:"port"`
+	Command   []string     `json:"command"`
+	Cache     *cacheConfig `json:"cache"`
+	Lifecycle string       `json:"lifecycle"`
+	// IdleTimeoutSeconds stops the tool's process after this long without calls; the next
+	// call starts it again. Zero keeps the tool running.
+	IdleTimeoutSeconds int `json:"idle_timeout_seconds"`
+	// Replicas is the number of processes launched for the tool; defaults to 1.
+	Replicas int `json:"replicas"`
+	// Addresses lists remote replicas of the tool, as gRPC dial targets, that the server
+	// connects to without launching them.
+	Addresses []string `json:"addresses"`
+	// LoadBalancing spreads calls over healthy replicas: "round_robin" (the default) or
+	// "least_loaded".
+	LoadBalancing string `json:"load_balancing"`
+	// Sandbox restricts the processes launched for the tool. Linux only.
+	Sandbox *sandboxConfig `json:"sandbox"`
+	// RequiresApproval holds calls back until an operator approves them.
+	RequiresApproval *approvalConfig `json:"requires_approval"`
+	// CallTimeoutSeconds bounds each call of the tool, overriding the server's
+	// tool_call_timeout_seconds.
+	CallTimeoutSeconds int `json:"call_timeout_seconds"`
 }
 
 // discoverAndRunTools scans the filesystem for tools, launches them, and connects.
-// It uses the provided projectRoot to build absolute paths for reliability.
-func (s *server) discoverAndRunTools(projectRoot string) {
+// Tools with a manifest and the lazy lifecycle are only registered; they start on their first call.
+func (s *server) discoverAndRunTools() {
 	// Build tool directory paths relative to the provided project root for robustness.
 	toolDirs := []string{
-		filepath.Join(projectRoot, "MCP-NG/tools/go"),
-		filepath.Join(projectRoot, "MCP-NG/tools/python"),
+		filepath.Join(s.projectRoot, "MCP-NG/tools/go"),
+		filepath.Join(s.projectRoot, "MCP-NG/tools/python"),
 	}
 	logger.Info("Starting automatic tool discovery and launch...", "search_paths", toolDirs)
 
-	skipTools := map[string]bool{
-		"hybrid_search":     true,
-		"keyword_extractor": true,
-		"text_summarizer":   true,
-		"text_generator":    true,
-	}
-
 	for _, dir := range toolDirs {
 		// filepath.Walk will handle cases where a directory doesn't exist gracefully.
 		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
@@ -130,123 +269,33 @@ func (s *server) discoverAndRunTools(projectRoot string) {
 			}
 			if info.IsDir() && filepath.Dir(path) == dir {
 				toolName := filepath.Base(path)
-				if skipTools[toolName] {
-					logger.Warn("Skipping resource-intensive ML tool by default", "tool", toolName)
-					return nil
-				}
-				configPath := filepath.Join(path, "config.json")
-				if _, err := os.Stat(configPath); os.IsNotExist(err) {
-					logger.Warn("config.json not found for tool, skipping.", "tool", toolName)
-					return nil
-				}
-				configFile, err := os.ReadFile(configPath)
+				tool, err := loadTool(path)
 				if err != nil {
-					logger.Warn("Failed to read config.json for tool", "tool", toolName, "error", err)
+					logger.Warn("Failed to load tool, skipping.", "tool", toolName, "error", err)
 					return nil
 				}
-				var config toolConfig
-				if err := json.Unmarshal(configFile, &config); err != nil {
-					logger.Warn("Failed to parse config.json for tool", "tool", toolName, "error", err)
+				if tool.config.Lifecycle == lifecycleDisabled {
+					logger.Info("Tool is disabled in its config.json, skipping", "tool", toolName)
 					return nil
 				}
-				if len(config.Command) > 0 {
-					executable := config.Command[0]
-					args := config.Command[1:]
-
-					// Проверяем, является ли команда простым именем (без пути)
-					isSimpleCommand := !strings.ContainsAny(executable, `\/`)
-
-					// Если это простой вызов и не 'go' или 'python', строим абсолютный путь
-					if isSimpleCommand && executable != "go" && executable != "python" && executable != "python3" {
-						// Это наш скомпилированный Go-инструмент. Строим к нему абсолютный путь.
-						executable = filepath.Join(projectRoot, "bin", executable)
-						if runtime.GOOS == "windows" && !strings.HasSuffix(executable, ".exe") {
-							executable += ".exe"
-						}
-					}
-
-					// Специальная обработка для Python, чтобы всегда использовать venv
-					isPythonTool := strings.Contains(filepath.ToSlash(path), "/tools/python/")
-					if isPythonTool {
-						pythonExe := filepath.Join(projectRoot, ".venv", "Scripts", "python.exe")
-						// Для не-Windows систем путь будет другим
-						if runtime.GOOS != "windows" {
-							pythonExe = filepath.Join(projectRoot, ".venv", "bin", "python")
-						}
-
-						// Пересобираем команду: python.exe script.py
-						fullScriptPath := filepath.Join(path, config.Command[0])
-						args = []string{fullScriptPath}
-						executable = pythonExe
-					}
-
-					cmd := exec.Command(executable, args...)
-					cmd.Dir = path // Рабочая директория остается папкой инструмента, чтобы он нашел свой config.json
-					cmd.Stdout = os.Stdout
-					cmd.Stderr = os.Stderr
-					logger.Info("ATTEMPTING TO RUN", "executable", cmd.Path, "args", cmd.Args, "dir", cmd.Dir)
-					if err := cmd.Start(); err != nil {
-						logger.Error("Failed to start tool", "tool", toolName, "error", err)
-						return nil
+				s.mu.Lock()
+				s.discovered = append(s.discovered, tool)
+				s.mu.Unlock()
+				if tool.manifest != nil {
+					if missing := tool.manifest.missingSecrets(tool.configFile); len(missing) > 0 {
+						logger.Warn("Tool is missing required secrets in config.json", "tool", tool.manifest.Name, "secrets", missing)
 					}
-					s.toolCmds = append(s.toolCmds, cmd)
-					logger.Info("Started tool", "tool", toolName, "pid", cmd.Process.Pid)
 				}
-				addr := fmt.Sprintf("127.0.0.1:%d", config.Port)
-				var conn *grpc.ClientConn
-				var connErr error
-				var desc *pb.ToolDescription
-				var client pb.ToolClient
-				var healthClient grpc_health_v1.HealthClient
-
-				// Increased retries to handle slow tool startup, especially for tools compiled on the fly with 'go run'.
-				const maxRetries = 15
-				const retryDelay = 1 * time.Second
-
-				for i := 0; i < maxRetries; i++ {
-					time.Sleep(retryDelay)
-					conn, connErr = grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
-					if connErr != nil {
-						logger.Warn("Failed to create gRPC client for tool, retrying...", "tool", toolName, "attempt", i+1, "error", connErr)
-						continue
-					}
-					client = pb.NewToolClient(conn)
-					healthClient = grpc_health_v1.NewHealthClient(conn)
-					ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-					desc, connErr = client.GetDescription(ctx, &pb.GetDescriptionRequest{})
-					cancel()
-					if connErr == nil {
-						break
+				if tool.config.Lifecycle == lifecycleLazy {
+					if tool.manifest != nil {
+						s.registerFromManifest(tool)
+						return nil
 					}
-					logger.Warn("Failed to get description for tool, retrying...", "tool", toolName, "attempt", i+1, "error", connErr)
-					conn.Close()
-				}
-				if connErr != nil {
-					logger.Error("Failed to connect to tool after multiple retries, giving up.", "tool", toolName, "error", connErr)
-					return nil
+					logger.Warn("Lazy start requires a manifest.json, starting tool now", "tool", toolName)
 				}
-				registeredName := desc.Name
-				if registeredName == "" {
-					logger.Warn("Tool provided an empty name, skipping.", "tool", toolName)
-					return nil
+				if err := s.startTool(tool); err != nil {
+					logger.Error("Failed to start tool", "tool", toolName, "error", err)
 				}
-				initialStatus := grpc_health_v1.HealthCheckResponse_NOT_SERVING
-				resp, err := healthClient.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "mcp.Tool"})
-				if err != nil {
-					logger.Warn("Initial health check failed for tool", "tool", registeredName, "error", err)
-				} else {
-					initialStatus = resp.Status
-					logger.Info("Initial health check successful for tool", "tool", registeredName, "status", initialStatus)
-				}
-				s.mu.Lock()
-				s.tools[registeredName] = &toolClient{
-					client:       client,
-					healthClient: healthClient,
-					description:  desc,
-					status:       initialStatus,
-				}
-				s.mu.Unlock()
-				logger.Info("Successfully registered tool", "tool", registeredName)
 			}
 			return nil
 		})
@@ -256,6 +305,452 @@ func (s *server) discoverAndRunTools(projectRoot string) {
 	}
 }
 
+// loadTool reads the config.json and optional manifest.json of a tool directory.
+func loadTool(dir string) (*toolClient, error) {
+	configFile, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return nil, err
+	}
+	tool := &toolClient{dir: dir, configFile: configFile}
+	if err := json.Unmarshal(configFile, &tool.config); err != nil {
+		return nil, fmt.Errorf("failed to parse config.json: %w", err)
+	}
+	switch tool.config.Lifecycle {
+	case "", lifecycleEager, lifecycleLazy, lifecycleDisabled:
+	default:
+		return nil, fmt.Errorf("unknown lifecycle %q", tool.config.Lifecycle)
+	}
+	if tool.config.IdleTimeoutSeconds < 0 {
+		return nil, fmt.Errorf("idle_timeout_seconds must not be negative")
+	}
+	if tool.config.CallTimeoutSeconds < 0 {
+		return nil, fmt.Errorf("call_timeout_seconds must not be negative")
+	}
+	if tool.config.Replicas < 0 {
+		return nil, fmt.Errorf("replicas must not be negative")
+	}
+	if tool.config.Replicas > 1 && len(tool.config.Command) == 0 {
+		return nil, fmt.Errorf("replicas requires a command to launch")
+	}
+	switch tool.config.LoadBalancing {
+	case "", balanceRoundRobin, balanceLeastLoaded:
+	default:
+		return nil, fmt.Errorf("unknown load_balancing %q", tool.config.LoadBalancing)
+	}
+	if tool.config.Sandbox != nil {
+		if err := tool.config.Sandbox.validate(); err != nil {
+			return nil, err
+		}
+	}
+	if tool.manifest, err = loadManifest(dir); err != nil {
+		return nil, err
+	}
+	return tool, nil
+}
+
+// registerFromManifest lists a tool under its manifest description without starting it.
+func (s *server) registerFromManifest(tool *toolClient) {
+	name := tool.manifest.Name
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.tools[name]; exists {
+		logger.Warn("A tool with this name is already registered, skipping.", "tool", name, "dir", tool.dir)
+		return
+	}
+	tool.description = tool.manifest.desc
+	tool.status = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	tool.startOnCall = true
+	s.tools[name] = tool
+	logger.Info("Registered tool from its manifest, it will start on its first call", "tool", name)
+}
+
+// ensureStarted starts a tool registered from its manifest or stopped for being idle.
+// Concurrent first calls share one start; if it fails, the next call tries again.
+func (s *server) ensureStarted(tool *toolClient) error {
+	s.mu.RLock()
+	pending := tool.startOnCall
+	s.mu.RUnlock()
+	if !pending {
+		return nil
+	}
+
+	tool.startMu.Lock()
+	defer tool.startMu.Unlock()
+	s.mu.RLock()
+	pending = tool.startOnCall
+	s.mu.RUnlock()
+	if !pending {
+		return nil
+	}
+	logger.Info("Starting tool on its first call", "tool", tool.description.Name)
+	return s.startTool(tool)
+}
+
+// acquireTool starts the tool if needed, picks the replica for a call and counts the call as
+// running on it, so the tool is not stopped for being idle in the middle of the call. The
+// returned release must be called when the call is done. It fails if no replica is healthy.
+func (s *server) acquireTool(tool *toolClient) (replica *toolReplica, release func(), err error) {
+	for {
+		if err := s.ensureStarted(tool); err != nil {
+			return nil, nil, status.Errorf(codes.Unavailable, "Tool '%s' failed to start: %v", tool.description.Name, err)
+		}
+		s.mu.Lock()
+		if tool.startOnCall {
+			// Stopped for being idle since ensureStarted returned; start it again.
+			s.mu.Unlock()
+			continue
+		}
+		if s.draining {
+			s.mu.Unlock()
+			return nil, nil, status.Error(codes.Unavailable, "The server is shutting down.")
+		}
+		replica := tool.pickReplica()
+		if replica == nil {
+			s.mu.Unlock()
+			return nil, nil, status.Errorf(codes.NotFound, "Tool '%s' not found or is not healthy.", tool.description.Name)
+		}
+		tool.inflight++
+		replica.inflight++
+		s.calls.Add(1)
+		s.mu.Unlock()
+		return replica, func() {
+			s.mu.Lock()
+			tool.inflight--
+			replica.inflight--
+			tool.lastUsed = time.Now()
+			s.mu.Unlock()
+			s.calls.Done()
+		}, nil
+	}
+}
+
+// stopIdleTools stops the processes of tools with an idle timeout that have had no calls
+// for longer than it. They stay listed and start again on their next call.
+func (s *server) stopIdleTools(now time.Time) {
+	s.mu.RLock()
+	var idle []*toolClient
+	for _, tool := range s.tools {
+		timeout := time.Duration(tool.config.IdleTimeoutSeconds) * time.Second
+		if timeout > 0 && !tool.startOnCall && tool.inflight == 0 && now.Sub(tool.lastUsed) >= timeout {
+			idle = append(idle, tool)
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, tool := range idle {
+		s.stopIdleTool(tool, now)
+	}
+}
+
+// stopIdleTool stops one tool if it is still idle once its start lock is held.
+func (s *server) stopIdleTool(tool *toolClient, now time.Time) {
+	tool.startMu.Lock()
+	defer tool.startMu.Unlock()
+
+	s.mu.Lock()
+	timeout := time.Duration(tool.config.IdleTimeoutSeconds) * time.Second
+	if tool.startOnCall || tool.inflight > 0 || now.Sub(tool.lastUsed) < timeout {
+		s.mu.Unlock()
+		return
+	}
+	name := tool.description.Name
+	replicas := tool.replicas
+	tool.replicas = nil
+	tool.startOnCall = true
+	setToolHealthMetric(name, tool.status, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	tool.status = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	s.forgetCmds(replicas)
+	s.mu.Unlock()
+
+	logger.Info("Stopping idle tool, it will start again on its next call", "tool", name, "idle_timeout_seconds", tool.config.IdleTimeoutSeconds)
+	for _, r := range replicas {
+		r.stop(s.config.shutdownGrace())
+	}
+}
+
+// forgetCmds removes the processes of the given replicas from the ones killed on shutdown.
+// The caller must hold s.mu.
+func (s *server) forgetCmds(replicas []*toolReplica) {
+	for _, r := range replicas {
+		for i, c := range s.toolCmds {
+			if c == r.cmd {
+				s.toolCmds = append(s.toolCmds[:i], s.toolCmds[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// toolCommand builds the command that runs a tool. Simple executable names refer to the
+// compiled Go tools in the project's bin directory; Python tools run under the project's venv.
+func (s *server) toolCommand(tool *toolClient) *exec.Cmd {
+	executable := tool.config.Command[0]
+	args := tool.config.Command[1:]
+
+	// Проверяем, является ли команда простым именем (без пути)
+	isSimpleCommand := !strings.ContainsAny(executable, `\/`)
+
+	// The manifest's runtime wins; otherwise it follows from the tool's directory.
+	isPythonTool := strings.Contains(filepath.ToSlash(tool.dir), "/tools/python/")
+	if tool.manifest != nil && tool.manifest.Runtime != "" {
+		isPythonTool = tool.manifest.Runtime == runtimePython
+	}
+
+	// Если это простой вызов и не 'go' или 'python', строим абсолютный путь
+	if isSimpleCommand && executable != "go" && executable != "python" && executable != "python3" {
+		// Это наш скомпилированный Go-инструмент. Строим к нему абсолютный путь.
+		executable = filepath.Join(s.projectRoot, "bin", executable)
+		if runtime.GOOS == "windows" && !strings.HasSuffix(executable, ".exe") {
+			executable += ".exe"
+		}
+	}
+
+	// Специальная обработка для Python, чтобы всегда использовать venv
+	if isPythonTool {
+		pythonExe := filepath.Join(s.projectRoot, ".venv", "Scripts", "python.exe")
+		// Для не-Windows систем путь будет другим
+		if runtime.GOOS != "windows" {
+			pythonExe = filepath.Join(s.projectRoot, ".venv", "bin", "python")
+		}
+
+		// Пересобираем команду: python.exe script.py
+		fullScriptPath := filepath.Join(tool.dir, tool.config.Command[0])
+		args = []string{fullScriptPath}
+		executable = pythonExe
+	}
+
+	cmd := exec.Command(executable, args...)
+	cmd.Dir = tool.dir // Рабочая директория остается папкой инструмента, чтобы он нашел свой config.json
+	cmd.Env = append(os.Environ(), s.config.Tracing.Env()...)
+	return cmd
+}
+
+// toolAddress assigns a tool the server launches the address to listen on: a Unix socket in
+// the server's socket directory, or a free loopback port. The result is both passed to the
+// tool and dialed by the server.
+func (s *server) toolAddress(toolName string, sandbox *sandboxConfig) (string, error) {
+	// A tool in its own network namespace can only be reached through the filesystem.
+	if s.config.ToolTransport != transportUnix && !sandbox.isolatesNetwork() {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return "", err
+		}
+		defer lis.Close()
+		return lis.Addr().String(), nil
+	}
+
+	s.mu.Lock()
+	if s.socketDir == "" {
+		dir, err := os.MkdirTemp("", "mcp-ng-")
+		if err != nil {
+			s.mu.Unlock()
+			return "", err
+		}
+		s.socketDir = dir
+	}
+	dir := s.socketDir
+	s.mu.Unlock()
+	if sandbox != nil && sandbox.User != "" {
+		// The tool's user must be able to create its socket.
+		uid, gid, err := sandbox.lookupUser()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(dir, toolName)
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return "", err
+		}
+		if err := os.Chown(dir, uid, gid); err != nil {
+			return "", fmt.Errorf("failed to give the sandbox user the socket directory: %w", err)
+		}
+	}
+	path := filepath.Join(dir, toolName+".sock")
+	// A socket left behind by a previous process of the tool would make listening fail.
+	os.Remove(path)
+	return "unix://" + path, nil
+}
+
+// launchReplica starts one process of the tool at a newly assigned address, inside the
+// tool's sandbox if it has one.
+func (s *server) launchReplica(tool *toolClient, name string) (*toolReplica, error) {
+	sandbox := tool.config.Sandbox
+	addr, err := s.toolAddress(name, sandbox)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign an address: %w", err)
+	}
+	cmd := s.toolCommand(tool)
+	cmd.Env = append(cmd.Env, toolkit.AddressEnv+"="+addr)
+	if s.config.GrpcPort > 0 {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=127.0.0.1:%d", toolkit.ServerAddressEnv, s.config.GrpcPort))
+	}
+	logger.Info("ATTEMPTING TO RUN", "executable", cmd.Path, "args", cmd.Args, "dir", cmd.Dir)
+	r := &toolReplica{address: addr, cmd: cmd}
+	if sandbox != nil {
+		var writable []string
+		if path, ok := strings.CutPrefix(addr, "unix://"); ok {
+			writable = append(writable, filepath.Dir(path))
+		}
+		if r.sandbox, err = sandboxCommand(cmd, sandbox, name, writable); err != nil {
+			return nil, fmt.Errorf("failed to set up sandbox: %w", err)
+		}
+	}
+	setProcessGroup(cmd)
+	capture, err := s.toolLogsFor(tool).attach(cmd)
+	if err != nil {
+		r.sandbox.release()
+		return nil, fmt.Errorf("failed to capture output: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		capture.abort()
+		r.sandbox.release()
+		return nil, fmt.Errorf("failed to start process: %w", err)
+	}
+	capture.started(cmd.Process.Pid)
+	if err := r.sandbox.started(); err != nil {
+		r.stop(0)
+		return nil, err
+	}
+	return r, nil
+}
+
+// startTool launches the tool's replicas (if it has a command), connects to them and to its
+// remote addresses, and registers the tool under the name it reports. At least one replica
+// must answer; the others join once their health checks pass. A tool with a manifest must
+// report the manifest's name; other differences from the manifest are logged and the live
+// description is used.
+func (s *server) startTool(tool *toolClient) (err error) {
+	toolName := filepath.Base(tool.dir)
+	var replicas []*toolReplica
+	var warnings []string
+	defer func() {
+		// Replicas we could not register would otherwise be left running.
+		if err != nil {
+			for _, r := range replicas {
+				r.stop(0) // Not serving any calls yet
+			}
+		}
+		s.mu.Lock()
+		tool.lastError = ""
+		if err != nil {
+			tool.lastError = err.Error()
+		}
+		tool.warnings = warnings
+		s.mu.Unlock()
+	}()
+	if len(tool.config.Command) > 0 {
+		for i := 0; i < tool.config.replicaCount(); i++ {
+			r, err := s.launchReplica(tool, fmt.Sprintf("%s-%d", toolName, i))
+			if err != nil {
+				return err
+			}
+			if r.sandbox != nil {
+				for _, w := range r.sandbox.warnings {
+					logger.Warn("Sandbox setting not applied", "tool", toolName, "pid", r.cmd.Process.Pid, "warning", w)
+				}
+				if i == 0 {
+					warnings = append(warnings, r.sandbox.warnings...)
+				}
+			}
+			logger.Info("Started tool", "tool", toolName, "replica", i, "pid", r.cmd.Process.Pid)
+			replicas = append(replicas, r)
+		}
+	}
+	for _, addr := range tool.config.Addresses {
+		replicas = append(replicas, &toolReplica{address: addr})
+	}
+	if len(replicas) == 0 {
+		// Tools the server does not launch are expected on the port from their config.json.
+		replicas = append(replicas, &toolReplica{address: fmt.Sprintf("127.0.0.1:%d", tool.config.Port)})
+	}
+
+	descs := make([]*pb.ToolDescription, len(replicas))
+	errs := make([]error, len(replicas))
+	var wg sync.WaitGroup
+	for i, r := range replicas {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			descs[i], errs[i] = r.connect(toolName)
+		}()
+	}
+	wg.Wait()
+
+	var desc *pb.ToolDescription
+	for i, d := range descs {
+		switch {
+		case d == nil:
+			if replicas[i].conn == nil {
+				return errs[i]
+			}
+		case desc == nil:
+			desc = d
+		case d.Name != desc.Name:
+			return fmt.Errorf("replicas report different names: %q and %q", desc.Name, d.Name)
+		}
+	}
+	if desc == nil {
+		return errs[0]
+	}
+	registeredName := desc.Name
+	if registeredName == "" {
+		return fmt.Errorf("tool provided an empty name")
+	}
+	for i, r := range replicas {
+		if descs[i] == nil {
+			logger.Warn("Tool replica is not reachable, it will join once healthy", "tool", registeredName, "address", r.address, "error", errs[i])
+		}
+	}
+	if tool.manifest != nil {
+		var diffs []string
+		if desc, diffs, err = tool.manifest.describe(desc); err != nil {
+			return err
+		}
+		if len(diffs) > 0 {
+			logger.Warn("Tool does not match its manifest, using its live description", "tool", registeredName, "differences", diffs)
+		}
+	}
+	for i, r := range replicas {
+		r.status = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+		if descs[i] != nil {
+			r.check(registeredName)
+		}
+	}
+	// A tool restarted after being idle keeps its cache.
+	cache := tool.cache
+	if cache == nil {
+		if cache, err = newResultCache(registeredName, s.projectRoot, tool.config.Cache); err != nil {
+			logger.Warn("Failed to set up result cache for tool, caching disabled", "tool", registeredName, "error", err)
+		} else if cache != nil {
+			logger.Info("Result caching enabled for tool", "tool", registeredName, "backend", tool.config.Cache.Backend, "ttl_seconds", tool.config.Cache.TTLSeconds)
+		}
+	}
+	s.mu.Lock()
+	tool.replicas = replicas
+	tool.description = desc
+	tool.updateStatus()
+	tool.cache = cache
+	tool.startOnCall = false
+	tool.lastUsed = time.Now()
+	tool.starts++
+	restarted := tool.starts > 1
+	s.tools[registeredName] = tool
+	for _, r := range replicas {
+		if r.cmd != nil {
+			s.toolCmds = append(s.toolCmds, r.cmd)
+		}
+	}
+	initialStatus := tool.status
+	s.mu.Unlock()
+	setToolHealthMetric(registeredName, initialStatus, initialStatus)
+	if restarted {
+		mcpToolRestarts.WithLabelValues(registeredName).Inc()
+	} else {
+		mcpToolRestarts.WithLabelValues(registeredName) // Expose the series at zero.
+	}
+	logger.Info("Successfully registered tool", "tool", registeredName, "replicas", len(replicas), "status", initialStatus)
+	return nil
+}
+
 // startHealthChecks starts a goroutine to periodically check the health of all registered tools.
 func (s *server) startHealthChecks() {
 	go func() {
@@ -265,20 +760,8 @@ func (s *server) startHealthChecks() {
 		for {
 			select {
 			case <-ticker.C:
-				s.mu.Lock()
-				for name, tool := range s.tools {
-					resp, err := tool.healthClient.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: "mcp.Tool"})
-					if err != nil {
-						logger.Warn("Health check failed for tool", "tool", name, "error", err)
-						tool.status = grpc_health_v1.HealthCheckResponse_NOT_SERVING
-					} else {
-						if tool.status != resp.Status {
-							logger.Info("Health status changed for tool", "tool", name, "status", resp.Status)
-						}
-						tool.status = resp.Status
-					}
-				}
-				s.mu.Unlock()
+				s.checkTools()
+				s.stopIdleTools(time.Now())
 			case <-s.shutdown:
 				logger.Info("Stopping health checks.")
 				return
@@ -287,68 +770,278 @@ func (s *server) startHealthChecks() {
 	}()
 }
 
-// cleanup kills all the tool subprocesses during a graceful shutdown.
+// checkTools runs a health check against every replica of every running tool. Unhealthy
+// replicas stop receiving calls until a later check passes. The checks run in parallel and
+// without s.mu, so slow replicas do not hold up tool calls.
+func (s *server) checkTools() {
+	type replicaCheck struct {
+		name    string
+		tool    *toolClient
+		replica *toolReplica
+		status  grpc_health_v1.HealthCheckResponse_ServingStatus
+		err     error
+	}
+	var checks []*replicaCheck
+	s.mu.RLock()
+	for name, tool := range s.tools {
+		if tool.startOnCall {
+			continue // Not running yet
+		}
+		for _, r := range tool.replicas {
+			checks = append(checks, &replicaCheck{name: name, tool: tool, replica: r})
+		}
+	}
+	s.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.status, c.err = c.replica.probe()
+		}()
+	}
+	wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	checked := make(map[*toolClient]string)
+	for _, c := range checks {
+		if !slices.Contains(c.tool.replicas, c.replica) {
+			continue // Stopped while being checked
+		}
+		c.replica.record(c.name, c.status, c.err)
+		checked[c.tool] = c.name
+	}
+	for tool, name := range checked {
+		prev := tool.status
+		tool.updateStatus()
+		if tool.status != prev {
+			logger.Info("Health status changed for tool", "tool", name, "status", tool.status)
+		}
+		setToolHealthMetric(name, prev, tool.status)
+	}
+}
+
+// drain stops accepting tool calls and waits until the running ones have finished or ctx
+// is done.
+func (s *server) drain(ctx context.Context) {
+	s.mu.Lock()
+	s.draining = true
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.calls.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		logger.Warn("Tool calls still running after the shutdown grace period, stopping tools anyway")
+	}
+}
+
+// cleanup stops all the tool subprocesses during a graceful shutdown. Each tool gets the
+// grace period to exit after SIGTERM before its process group is killed.
 func (s *server) cleanup() {
 	logger.Info("Cleaning up tool subprocesses...")
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	for _, cmd := range s.toolCmds {
-		if cmd.Process != nil {
-			if err := cmd.Process.Kill(); err != nil {
-				logger.Error("Failed to kill process", "pid", cmd.Process.Pid, "error", err)
-			} else {
-				logger.Info("Killed process", "pid", cmd.Process.Pid)
-			}
+	cmds := s.toolCmds
+	s.toolCmds = nil
+	socketDir := s.socketDir
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, cmd := range cmds {
+		if cmd.Process == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(cmd *exec.Cmd) {
+			defer wg.Done()
+			stopProcess(cmd, s.config.shutdownGrace())
+			logger.Info("Stopped process", "pid", cmd.Process.Pid)
+		}(cmd)
+	}
+	wg.Wait()
+	if socketDir != "" {
+		os.RemoveAll(socketDir)
+	}
+}
+
+// stopProcess sends SIGTERM to a tool's process group, waits up to grace for the tool to
+// exit and then kills whatever is left of the group, including children that outlived the
+// tool. A zero grace kills it right away. It returns once the tool has exited.
+func stopProcess(cmd *exec.Cmd, grace time.Duration) {
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+	if grace > 0 {
+		if err := terminateProcessGroup(cmd); err != nil {
+			logger.Warn("Failed to send SIGTERM to tool", "pid", cmd.Process.Pid, "error", err)
+		}
+		timer := time.NewTimer(grace)
+		defer timer.Stop()
+		select {
+		case <-exited:
+		case <-timer.C:
+			logger.Warn("Tool did not exit within the grace period, killing it", "pid", cmd.Process.Pid, "grace", grace)
 		}
 	}
+	// Fails harmlessly once the whole group is gone.
+	killProcessGroup(cmd)
+	<-exited
 }
 
 // ListTools returns a list of available and healthy tools.
 func (s *server) ListTools(ctx context.Context, in *pb.ListToolsRequest) (*pb.ListToolsResponse, error) {
-	logger.Info("Received request to list tools")
+	logger.Info("Received request to list tools", "format", in.Format)
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	var toolDescriptions []*pb.ToolDescription
 	for name, t := range s.tools {
-		if t.status == grpc_health_v1.HealthCheckResponse_SERVING {
+		if t.available() {
 			toolDescriptions = append(toolDescriptions, t.description)
 		} else {
 			logger.Warn("Excluding unhealthy tool from list", "tool", name, "status", t.status)
 		}
 	}
 
-	return &pb.ListToolsResponse{Tools: toolDescriptions}, nil
+	if in.Format == "" {
+		return &pb.ListToolsResponse{Tools: toolDescriptions}, nil
+	}
+
+	tools, names, err := formatTools(toolDescriptions, in.Format)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	resp := &pb.ListToolsResponse{ToolNames: make(map[string]string)}
+	for _, t := range tools {
+		formatted, err := structpb.NewStruct(t)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to encode %s tool: %v", in.Format, err)
+		}
+		resp.FormattedTools = append(resp.FormattedTools, formatted)
+	}
+	for formatted, name := range names {
+		if formatted != name {
+			resp.ToolNames[formatted] = name
+		}
+	}
+	return resp, nil
+}
+
+// callTimeout bounds one call of the tool, or is zero if only the caller's deadline applies.
+func (s *server) callTimeout(tool *toolClient) time.Duration {
+	if tool.config.CallTimeoutSeconds > 0 {
+		return time.Duration(tool.config.CallTimeoutSeconds) * time.Second
+	}
+	return time.Duration(s.config.ToolCallTimeoutSeconds) * time.Second
 }
 
 // ExecuteTool runs a specific tool as part of a task.
 func (s *server) ExecuteTool(ctx context.Context, in *pb.ExecuteToolRequest) (*pb.ExecuteToolResponse, error) {
+	return s.executeTool(ctx, in, false)
+}
+
+// executeTool runs a call; approved skips the approval gate for calls an operator approved.
+func (s *server) executeTool(ctx context.Context, in *pb.ExecuteToolRequest, approved bool) (*pb.ExecuteToolResponse, error) {
 	logger.Info("Received request to execute tool", "tool", in.ToolName, "task_id", in.TaskId)
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("mcp.tool.name", in.ToolName),
+		attribute.String("mcp.task_id", in.TaskId),
+	)
 
 	s.mu.RLock()
 	tool, ok := s.tools[in.ToolName]
 	s.mu.RUnlock()
 
-	if !ok || tool.status != grpc_health_v1.HealthCheckResponse_SERVING {
+	// Unknown names are reported under a single label to keep metric cardinality bounded.
+	metricName := in.ToolName
+	if !ok {
+		metricName = "unknown"
+	}
+	finish := observeToolCall(metricName)
+
+	if !ok {
 		logger.Error("Attempt to run unavailable tool", "tool", in.ToolName, "task_id", in.TaskId)
-		return nil, status.Errorf(codes.NotFound, "Tool '%s' not found or is not healthy.", in.ToolName)
+		err := status.Errorf(codes.NotFound, "Tool '%s' not found or is not healthy.", in.ToolName)
+		finish(err)
+		return nil, err
+	}
+	if rules := s.approvalFor(tool, in); rules != nil && !approved {
+		resp, err := s.requestApproval(in, rules)
+		finish(err)
+		return resp, err
+	}
+	fromCache := func(result *structpb.Struct) *pb.ExecuteToolResponse {
+		logger.Info("Serving tool result from cache", "tool", in.ToolName, "task_id", in.TaskId)
+		finish(nil)
+		return &pb.ExecuteToolResponse{TaskId: in.TaskId, Result: result, Cached: true}
+	}
+	// Cache hits do not touch the tool's process, so they neither start it nor need it healthy.
+	s.mu.RLock()
+	cache := tool.cache
+	s.mu.RUnlock()
+	key, cached := lookupCache(cache, in)
+	if cached != nil {
+		return fromCache(cached), nil
+	}
+
+	replica, release, err := s.acquireTool(tool)
+	if err != nil {
+		logger.Error("Attempt to run unavailable tool", "tool", in.ToolName, "task_id", in.TaskId, "error", err)
+		finish(err)
+		return nil, err
+	}
+	defer release()
+
+	if cache == nil {
+		// The cache is set up when the tool first starts, which may have been just now.
+		s.mu.RLock()
+		cache = tool.cache
+		s.mu.RUnlock()
+		if key, cached = lookupCache(cache, in); cached != nil {
+			return fromCache(cached), nil
+		}
 	}
 
+	if timeout := s.callTimeout(tool); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 	// Call the tool's internal Run method to perform the work.
-	runResp, err := tool.client.Run(ctx, &pb.ToolRunRequest{
+	runResp, err := replica.client.Run(ctx, &pb.ToolRunRequest{
 		Name:      in.ToolName,
 		Arguments: in.Arguments,
 	})
 
 	if err != nil {
+		if status.Code(err) == codes.Unavailable {
+			s.ejectReplica(tool, replica)
+		}
 		logger.Error("gRPC call to tool failed", "tool", in.ToolName, "task_id", in.TaskId, "error", err)
-		return nil, status.Errorf(codes.Internal, "gRPC call to tool '%s' failed: %v", in.ToolName, err)
+		finish(err)
+		// Deadline and cancellation keep their codes so callers can tell a slow tool from a broken one.
+		code := codes.Internal
+		if c := status.Code(err); c == codes.DeadlineExceeded || c == codes.Canceled {
+			code = c
+		}
+		return nil, status.Errorf(code, "gRPC call to tool '%s' failed: %v", in.ToolName, err)
 	}
 
 	if runResp.Error != "" {
 		logger.Error("Tool returned an error", "tool", in.ToolName, "task_id", in.TaskId, "error", runResp.Error)
-		return nil, status.Errorf(codes.Aborted, "Tool '%s' returned an error: %s", in.ToolName, runResp.Error)
+		err := status.Errorf(codes.Aborted, "Tool '%s' returned an error: %s", in.ToolName, runResp.Error)
+		finish(err)
+		return nil, err
 	}
+	finish(nil)
 
 	// Handle the result format: convert the tool's `Value` output to a `Struct` for the API response.
 	resultStruct, ok := runResp.Result.GetKind().(*structpb.Value_StructValue)
@@ -358,6 +1051,10 @@ func (s *server) ExecuteTool(ctx context.Context, in *pb.ExecuteToolRequest) (*p
 			Fields: map[string]*structpb.Value{"result": runResp.Result},
 		}}
 	}
+	s.trackPendingHumanInput(in.ToolName, in.Arguments, resultStruct.StructValue)
+	if key != "" {
+		cache.Set(key, resultStruct.StructValue)
+	}
 
 	return &pb.ExecuteToolResponse{
 		TaskId: in.TaskId,
@@ -365,46 +1062,8 @@ func (s *server) ExecuteTool(ctx context.Context, in *pb.ExecuteToolRequest) (*p
 	}, nil
 }
 
-// ProvideHumanInput stores the response from a human for a given task.
-func (s *server) ProvideHumanInput(ctx context.Context, in *pb.ProvideHumanInputRequest) (*pb.ProvideHumanInputResponse, error) {
-	logger.Info("Received human input", "task_id", in.TaskId)
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	if in.TaskId == "" {
-		logger.Error("Received ProvideHumanInput request with empty task_id")
-		return nil, status.Error(codes.InvalidArgument, "task_id cannot be empty")
-	}
-
-	s.humanInputs[in.TaskId] = &pb.GetHumanInputResponse{
-		Status:   "completed",
-		Response: in.Response,
-	}
-
-	return &pb.ProvideHumanInputResponse{Status: "received"}, nil
-}
-
-// GetHumanInput retrieves the response for a task that was waiting for human input.
-func (s *server) GetHumanInput(ctx context.Context, in *pb.GetHumanInputRequest) (*pb.GetHumanInputResponse, error) {
-	logger.Info("Checking for human input", "task_id", in.TaskId)
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	if in.TaskId == "" {
-		logger.Error("Received GetHumanInput request with empty task_id")
-		return nil, status.Error(codes.InvalidArgument, "task_id cannot be empty")
-	}
-
-	if resp, ok := s.humanInputs[in.TaskId]; ok {
-		logger.Info("Found completed response for task", "task_id", in.TaskId)
-		return resp, nil
-	}
-
-	logger.Info("No response yet for task", "task_id", in.TaskId)
-	return &pb.GetHumanInputResponse{Status: "pending"}, nil
-}
-
 func main() {
+	maybeRunSandboxInit()
 	logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
 	config := loadConfig()
 
@@ -428,7 +1087,14 @@ func main() {
 	}
 	logger.Info("Determined project root", "path", projectRoot)
 
-	mcpServer := newServer(projectRoot)
+	shutdownTracing, err := telemetry.Setup(ctx, "mcp-server", config.Tracing)
+	if err != nil {
+		logger.Error("Failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+	logger.Info("Tracing configured", "exporter", config.Tracing.Exporter)
+
+	mcpServer := newServer(projectRoot, config)
 
 	var wg sync.WaitGroup
 
@@ -439,7 +1105,7 @@ func main() {
 		logger.Error("Failed to listen for gRPC", "address", grpcAddr, "error", err)
 		os.Exit(1)
 	}
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(telemetry.ServerOption())
 	pb.RegisterMCPServer(grpcServer, mcpServer)
 	reflection.Register(grpcServer)
 
@@ -460,6 +1126,7 @@ func main() {
 		ctx,
 		grpcAddr,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		telemetry.DialOption(),
 	)
 	if err != nil {
 		logger.Error("Failed to dial gRPC server for gateway", "address", grpcAddr, "error", err)
@@ -471,6 +1138,10 @@ func main() {
 		logger.Error("Failed to register gRPC-Gateway handler", "error", err)
 		os.Exit(1)
 	}
+	if err := mcpServer.registerToolRoutes(grpcGatewayMux); err != nil {
+		logger.Error("Failed to register per-tool REST routes", "error", err)
+		os.Exit(1)
+	}
 
 	corsHandler := cors.New(cors.Options{
 		AllowedOrigins: []string{"*"},
@@ -478,9 +1149,13 @@ func main() {
 		AllowedHeaders: []string{"Accept", "Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization"},
 	}).Handler(grpcGatewayMux)
 
+	httpMux := http.NewServeMux()
+	httpMux.Handle("/metrics", metricsHandler())
+	httpMux.Handle("/", telemetry.HTTPHandler(corsHandler, "mcp-gateway"))
+
 	httpServer := &http.Server{
 		Addr:    httpAddr,
-		Handler: corsHandler,
+		Handler: httpMux,
 	}
 
 	wg.Add(1)
@@ -501,14 +1176,35 @@ func main() {
 	logger.Info("Shutdown signal received, gracefully shutting down servers...")
 	close(mcpServer.shutdown)
 
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer shutdownCancel()
-	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+	// Running calls get the grace period to finish before the tools are stopped.
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), config.shutdownGrace())
+	defer drainCancel()
+	if err := httpServer.Shutdown(drainCtx); err != nil {
 		logger.Error("HTTP server shutdown error", "error", err)
 	}
-
-	grpcServer.GracefulStop()
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+	select {
+	case <-grpcStopped:
+	case <-drainCtx.Done():
+		logger.Warn("gRPC calls still running after the shutdown grace period, closing connections")
+		grpcServer.Stop()
+	}
+	mcpServer.drain(drainCtx)
 	mcpServer.cleanup()
+	if err := mcpServer.humanInputs.Close(); err != nil {
+		logger.Error("Failed to close the human input store", "error", err)
+	}
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Error("Tracing shutdown error", "error", err)
+	}
 
 	wg.Wait()
 	logger.Info("All servers stopped. Exiting.")
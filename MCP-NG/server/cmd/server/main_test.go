@@ -16,12 +16,14 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
 // TestMain runs before any other tests in this package.
 func TestMain(m *testing.M) {
+	maybeRunSandboxInit() // Sandboxed tools are launched through the test binary
 	logger = slog.New(slog.NewJSONHandler(io.Discard, nil))
 	os.Exit(m.Run())
 }
@@ -33,7 +35,7 @@ func startTestServer(t *testing.T) (string, func()) {
 		t.Fatalf("failed to listen: %v", err)
 	}
 	grpcServer := grpc.NewServer()
-	mcpServer := newServer("../../../..") // Provide path to project root
+	mcpServer := newServer("../../../..", &serverConfig{}) // Provide path to project root
 	pb.RegisterMCPServer(grpcServer, mcpServer)
 	addr := lis.Addr().String()
 	go func() {
@@ -112,6 +114,35 @@ func TestMCPServer(t *testing.T) {
 			}
 		})
 
+		// Test Case: Repeated call is served from the calculator's result cache
+		t.Run("ExecuteTool_Cached", func(t *testing.T) {
+			argsStruct, err := structpb.NewStruct(map[string]interface{}{"expression": "6 * 7"})
+			if err != nil {
+				t.Fatalf("failed to create arguments struct: %v", err)
+			}
+			req := &pb.ExecuteToolRequest{ToolName: "calculator", Arguments: argsStruct}
+			first, err := client.ExecuteTool(ctx, req)
+			if err != nil {
+				t.Fatalf("first ExecuteTool failed: %v", err)
+			}
+			second, err := client.ExecuteTool(ctx, req)
+			if err != nil {
+				t.Fatalf("second ExecuteTool failed: %v", err)
+			}
+			if !second.Cached || second.Result.Fields["result"].GetNumberValue() != first.Result.Fields["result"].GetNumberValue() {
+				t.Errorf("expected cached result %v, got cached=%v result=%v", first.Result, second.Cached, second.Result)
+			}
+
+			req.Cache = "bypass"
+			bypassed, err := client.ExecuteTool(ctx, req)
+			if err != nil {
+				t.Fatalf("bypass ExecuteTool failed: %v", err)
+			}
+			if bypassed.Cached {
+				t.Error("expected cache bypass to run the tool")
+			}
+		})
+
 		// Test Case: Tool not found
 		t.Run("ExecuteTool_NotFound", func(t *testing.T) {
 			req := &pb.ExecuteToolRequest{ToolName: "non_existent_tool"}
@@ -125,8 +156,20 @@ func TestMCPServer(t *testing.T) {
 	t.Run("HumanInputFlow", func(t *testing.T) {
 		taskID := uuid.New().String()
 		
+		// Test Case: Unknown tasks are rejected
+		t.Run("ProvideHumanInput_Unknown", func(t *testing.T) {
+			_, err := client.ProvideHumanInput(ctx, &pb.ProvideHumanInputRequest{TaskId: taskID, Response: structpb.NewBoolValue(true)})
+			if status.Code(err) != codes.NotFound {
+				t.Errorf("expected gRPC status code 'NotFound', but got '%s'", status.Code(err))
+			}
+		})
+
 		// Test Case: Pending
 		t.Run("GetHumanInput_Pending", func(t *testing.T) {
+			_, err := client.RegisterHumanInput(ctx, &pb.RegisterHumanInputRequest{TaskId: taskID, Prompt: "Proceed?", Creator: "test"})
+			if err != nil {
+				t.Fatalf("RegisterHumanInput failed: %v", err)
+			}
 			req := &pb.GetHumanInputRequest{TaskId: taskID}
 			res, err := client.GetHumanInput(ctx, req)
 			if err != nil {
@@ -159,4 +202,36 @@ func TestMCPServer(t *testing.T) {
 			}
 		})
 	})
-}
\ No newline at end of file
+}
+
+// fakeToolClient is an in-process pb.ToolClient for tests that do not need real tool processes.
+type fakeToolClient struct {
+	run func(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunResponse, error)
+}
+
+func (f *fakeToolClient) GetDescription(ctx context.Context, in *pb.GetDescriptionRequest, opts ...grpc.CallOption) (*pb.ToolDescription, error) {
+	return &pb.ToolDescription{}, nil
+}
+
+func (f *fakeToolClient) Run(ctx context.Context, in *pb.ToolRunRequest, opts ...grpc.CallOption) (*pb.ToolRunResponse, error) {
+	return f.run(ctx, in)
+}
+
+// newTestServerWithTools builds a server whose registry holds the given healthy fake tools,
+// without discovering or launching anything.
+func newTestServerWithTools(tools map[string]*fakeToolClient) *server {
+	s := &server{
+		config:             &serverConfig{},
+		tools:              make(map[string]*toolClient),
+		humanInputs:        newMemoryHumanInputStore(),
+		shutdown:           make(chan struct{}),
+	}
+	for name, client := range tools {
+		s.tools[name] = &toolClient{
+			description: &pb.ToolDescription{Name: name},
+			status:      grpc_health_v1.HealthCheckResponse_SERVING,
+			replicas:    []*toolReplica{{client: client, status: grpc_health_v1.HealthCheckResponse_SERVING}},
+		}
+	}
+	return s
+}
@@ -0,0 +1,175 @@
+// File: MCP-NG/server/cmd/server/manifest.go
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	pb "mcp-ng/server/pkg/mcp"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// manifestFile is the optional static description of a tool, next to its config.json.
+const manifestFile = "manifest.json"
+
+// Runtimes a manifest can declare. Without one, the runtime follows from the directory the
+// tool lives in.
+const (
+	runtimeGo     = "go"
+	runtimePython = "python"
+)
+
+// toolManifest describes a tool without running it, so the orchestrator can list the tool
+// and start it on its first call.
+type toolManifest struct {
+	Name        string          `json:"name"`
+	Version     string          `json:"version"`
+	Description string          `json:"description"`
+	Parameters  json.RawMessage `json:"parameters"` // Same shape as ToolParameters
+	Runtime     string          `json:"runtime"`
+	// Secrets lists the config.json keys, in dotted form (e.g. "tavily_api.api_key"), that
+	// must be set for the tool to work.
+	Secrets     []string               `json:"secrets"`
+	Annotations map[string]interface{} `json:"annotations"`
+
+	desc *pb.ToolDescription // Built from the fields above by loadManifest
+}
+
+// loadManifest reads and validates the manifest in a tool directory. It returns nil without
+// an error if the tool has no manifest.
+func loadManifest(dir string) (*toolManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m toolManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", manifestFile, err)
+	}
+	if m.Name == "" {
+		return nil, fmt.Errorf("%s must set a name", manifestFile)
+	}
+	if m.Runtime != "" && m.Runtime != runtimeGo && m.Runtime != runtimePython {
+		return nil, fmt.Errorf("unsupported runtime %q, expected %q or %q", m.Runtime, runtimeGo, runtimePython)
+	}
+
+	params := &pb.ToolParameters{}
+	if len(m.Parameters) > 0 {
+		if err := protojson.Unmarshal(m.Parameters, params); err != nil {
+			return nil, fmt.Errorf("invalid parameters in %s: %v", manifestFile, err)
+		}
+	}
+	for _, req := range params.Required {
+		if _, ok := params.Properties[req]; !ok {
+			return nil, fmt.Errorf("required parameter %q is not declared in %s", req, manifestFile)
+		}
+	}
+	m.desc = &pb.ToolDescription{
+		Name:        m.Name,
+		Description: m.Description,
+		Parameters:  params,
+		Version:     m.Version,
+	}
+	if len(m.Annotations) > 0 {
+		if m.desc.Annotations, err = structpb.NewStruct(m.Annotations); err != nil {
+			return nil, fmt.Errorf("invalid annotations in %s: %v", manifestFile, err)
+		}
+	}
+	return &m, nil
+}
+
+// missingSecrets returns the manifest's secrets that are absent or empty in the tool's
+// config.json.
+func (m *toolManifest) missingSecrets(configFile []byte) []string {
+	if len(m.Secrets) == 0 {
+		return nil
+	}
+	var config map[string]interface{}
+	if err := json.Unmarshal(configFile, &config); err != nil {
+		return m.Secrets
+	}
+	var missing []string
+	for _, key := range m.Secrets {
+		var value interface{} = config
+		for _, part := range strings.Split(key, ".") {
+			obj, _ := value.(map[string]interface{})
+			value = obj[part]
+		}
+		if s, ok := value.(string); value == nil || (ok && s == "") {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
+
+// describe combines the live description of a started tool with its manifest. The live
+// description wins, so a stale manifest cannot misdescribe the tool; the manifest adds the
+// version and annotations. Differences are returned so they can be reported. A tool that
+// reports a different name does not match its manifest at all and is rejected.
+func (m *toolManifest) describe(live *pb.ToolDescription) (*pb.ToolDescription, []string, error) {
+	if live.Name != m.Name {
+		return nil, nil, fmt.Errorf("tool reports name %q but its manifest declares %q", live.Name, m.Name)
+	}
+	var diffs []string
+	if live.Description != m.desc.Description {
+		diffs = append(diffs, "description")
+	}
+	liveParams, manifestParams := live.GetParameters(), m.desc.GetParameters()
+	names := make(map[string]bool)
+	for name := range liveParams.GetProperties() {
+		names[name] = true
+	}
+	for name := range manifestParams.GetProperties() {
+		names[name] = true
+	}
+	for name := range names {
+		lp, mp := liveParams.GetProperties()[name], manifestParams.GetProperties()[name]
+		switch {
+		case lp == nil:
+			diffs = append(diffs, fmt.Sprintf("parameter %s is not accepted by the tool", name))
+		case mp == nil:
+			diffs = append(diffs, fmt.Sprintf("parameter %s is missing from the manifest", name))
+		case lp.Type != mp.Type:
+			diffs = append(diffs, fmt.Sprintf("parameter %s has type %s, manifest says %s", name, lp.Type, mp.Type))
+		}
+	}
+	if !sameStrings(liveParams.GetRequired(), manifestParams.GetRequired()) {
+		diffs = append(diffs, "required parameters")
+	}
+	sort.Strings(diffs)
+
+	desc := &pb.ToolDescription{
+		Name:        live.Name,
+		Description: live.Description,
+		Parameters:  live.Parameters,
+		Version:     m.desc.Version,
+		Annotations: m.desc.Annotations,
+	}
+	return desc, diffs, nil
+}
+
+// sameStrings reports whether a and b hold the same strings, in any order.
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a, b = append([]string(nil), a...), append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,225 @@
+// File: MCP-NG/server/cmd/server/manifest_test.go
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	pb "mcp-ng/server/pkg/mcp"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const testManifest = `{
+  "name": "calculator",
+  "version": "1.2.0",
+  "description": "A tool that evaluates mathematical expressions. Supports basic arithmetic (+, -, *, /) and parentheses.",
+  "runtime": "go",
+  "parameters": {
+    "type": "object",
+    "properties": {"expression": {"type": "string", "description": "The expression."}},
+    "required": ["expression"]
+  },
+  "secrets": ["api.key"],
+  "annotations": {"readOnlyHint": true}
+}`
+
+// writeToolDir creates a tool directory with the given config.json and manifest.json.
+func writeToolDir(t *testing.T, config, manifest string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if manifest != "" {
+		if err := os.WriteFile(filepath.Join(dir, manifestFile), []byte(manifest), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadManifest(t *testing.T) {
+	m, err := loadManifest(writeToolDir(t, `{}`, testManifest))
+	if err != nil {
+		t.Fatalf("loadManifest failed: %v", err)
+	}
+	if m.desc.Name != "calculator" || m.desc.Version != "1.2.0" || m.desc.Parameters.Properties["expression"].Type != "string" {
+		t.Errorf("unexpected description: %v", m.desc)
+	}
+	if !m.desc.Annotations.Fields["readOnlyHint"].GetBoolValue() {
+		t.Errorf("expected annotations to be kept, got %v", m.desc.Annotations)
+	}
+
+	if m, err := loadManifest(t.TempDir()); m != nil || err != nil {
+		t.Errorf("expected no manifest and no error for a directory without one, got %v, %v", m, err)
+	}
+
+	invalid := map[string]string{
+		"no name":            `{"version": "1"}`,
+		"bad runtime":        `{"name": "x", "runtime": "ruby"}`,
+		"undeclared require": `{"name": "x", "parameters": {"required": ["a"]}}`,
+		"bad parameters":     `{"name": "x", "parameters": {"properties": []}}`,
+	}
+	for name, manifest := range invalid {
+		if _, err := loadManifest(writeToolDir(t, `{}`, manifest)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestManifestMissingSecrets(t *testing.T) {
+	m, err := loadManifest(writeToolDir(t, `{}`, testManifest))
+	if err != nil {
+		t.Fatalf("loadManifest failed: %v", err)
+	}
+	for config, want := range map[string]int{
+		`{"api": {"key": "secret"}}`: 0,
+		`{"api": {"key": ""}}`:       1,
+		`{"api": {}}`:                1,
+		`{}`:                         1,
+	} {
+		if got := m.missingSecrets([]byte(config)); len(got) != want {
+			t.Errorf("%s: expected %d missing secrets, got %v", config, want, got)
+		}
+	}
+}
+
+func TestManifestDescribe(t *testing.T) {
+	m, err := loadManifest(writeToolDir(t, `{}`, testManifest))
+	if err != nil {
+		t.Fatalf("loadManifest failed: %v", err)
+	}
+
+	live := &pb.ToolDescription{
+		Name:        "calculator",
+		Description: m.desc.Description,
+		Parameters: &pb.ToolParameters{
+			Type:       "object",
+			Properties: map[string]*pb.ToolParameter{"expression": {Type: "number"}, "precision": {Type: "integer"}},
+			Required:   []string{"expression"},
+		},
+	}
+	desc, diffs, err := m.describe(live)
+	if err != nil {
+		t.Fatalf("describe failed: %v", err)
+	}
+	if len(diffs) != 2 || !strings.Contains(diffs[0], "has type number") || !strings.Contains(diffs[1], "missing from the manifest") {
+		t.Errorf("unexpected differences: %v", diffs)
+	}
+	if desc.Parameters != live.Parameters || desc.Version != "1.2.0" || desc.Annotations == nil {
+		t.Errorf("expected the live schema with the manifest's version and annotations, got %v", desc)
+	}
+
+	if _, _, err := m.describe(&pb.ToolDescription{Name: "calc"}); err == nil {
+		t.Error("expected a name mismatch to be rejected")
+	}
+}
+
+// newLazyCalculator registers the calculator from a manifest with the given config.json,
+// without starting it.
+func newLazyCalculator(t *testing.T, config string) (*server, *toolClient) {
+	t.Helper()
+	projectRoot, err := filepath.Abs("../../../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(projectRoot, "bin", "calculator")); err != nil {
+		t.Skip("calculator binary not built")
+	}
+	dir := writeToolDir(t, config, testManifest)
+	s := newTestServerWithTools(nil)
+	s.projectRoot = projectRoot
+	t.Cleanup(s.cleanup)
+	tool, err := loadTool(dir)
+	if err != nil {
+		t.Fatalf("loadTool failed: %v", err)
+	}
+	s.registerFromManifest(tool)
+	return s, tool
+}
+
+// calculate runs the calculator through the server and checks the result.
+func calculate(t *testing.T, s *server) {
+	t.Helper()
+	args, _ := structpb.NewStruct(map[string]interface{}{"expression": "3 * 3"})
+	resp, err := s.ExecuteTool(context.Background(), &pb.ExecuteToolRequest{ToolName: "calculator", Arguments: args})
+	if err != nil {
+		t.Fatalf("ExecuteTool failed: %v", err)
+	}
+	if resp.Result.Fields["result"].GetNumberValue() != 9 {
+		t.Errorf("unexpected result: %v", resp.Result)
+	}
+}
+
+func TestLazyToolStartsOnFirstCall(t *testing.T) {
+	s, tool := newLazyCalculator(t, `{"command": ["calculator"], "lifecycle": "lazy"}`)
+
+	list, err := s.ListTools(context.Background(), &pb.ListToolsRequest{})
+	if err != nil || len(list.Tools) != 1 || list.Tools[0].Version != "1.2.0" {
+		t.Fatalf("expected the lazy tool to be listed from its manifest, got %v, %v", list, err)
+	}
+	if len(s.toolCmds) != 0 {
+		t.Fatal("expected the lazy tool not to be started by registration")
+	}
+
+	calculate(t, s)
+	if tool.startOnCall || len(s.toolCmds) != 1 {
+		t.Error("expected the first call to start the tool")
+	}
+}
+
+func TestIdleToolStopsAndStartsAgain(t *testing.T) {
+	s, tool := newLazyCalculator(t, `{"command": ["calculator"], "lifecycle": "lazy", "idle_timeout_seconds": 60}`)
+	calculate(t, s)
+
+	s.stopIdleTools(time.Now())
+	if tool.startOnCall || len(s.toolCmds) != 1 {
+		t.Fatal("expected a recently used tool to keep running")
+	}
+
+	s.stopIdleTools(time.Now().Add(time.Minute))
+	if !tool.startOnCall || len(s.toolCmds) != 0 {
+		t.Fatal("expected the idle tool to be stopped")
+	}
+	list, err := s.ListTools(context.Background(), &pb.ListToolsRequest{})
+	if err != nil || len(list.Tools) != 1 {
+		t.Fatalf("expected the stopped tool to stay listed, got %v, %v", list, err)
+	}
+
+	restarts := testutil.ToFloat64(mcpToolRestarts.WithLabelValues("calculator"))
+	calculate(t, s)
+	if tool.startOnCall || len(s.toolCmds) != 1 {
+		t.Error("expected the next call to start the tool again")
+	}
+	if got := testutil.ToFloat64(mcpToolRestarts.WithLabelValues("calculator")); got != restarts+1 {
+		t.Errorf("expected the restart to be counted, got %v after %v", got, restarts)
+	}
+}
+
+func TestToolOverUnixSocket(t *testing.T) {
+	s, _ := newLazyCalculator(t, `{"command": ["calculator"], "lifecycle": "lazy"}`)
+	s.config.ToolTransport = transportUnix
+	calculate(t, s)
+	// Sockets are named after the tool's directory.
+	if sockets, _ := filepath.Glob(filepath.Join(s.socketDir, "*.sock")); len(sockets) != 1 {
+		t.Errorf("expected the tool to listen on a socket in %s, found %v", s.socketDir, sockets)
+	}
+}
+
+func TestDisabledAndInvalidLifecycles(t *testing.T) {
+	tool, err := loadTool(writeToolDir(t, `{"lifecycle": "disabled"}`, ""))
+	if err != nil || tool.config.Lifecycle != lifecycleDisabled {
+		t.Errorf("expected a disabled tool to load, got %v, %v", tool, err)
+	}
+	for _, config := range []string{`{"lifecycle": "sometimes"}`, `{"idle_timeout_seconds": -1}`} {
+		if _, err := loadTool(writeToolDir(t, config, "")); err == nil {
+			t.Errorf("%s: expected an error", config)
+		}
+	}
+}
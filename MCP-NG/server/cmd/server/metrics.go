@@ -0,0 +1,160 @@
+// File: MCP-NG/server/cmd/server/metrics.go
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// Error classes used for the `class` label of mcpToolErrors.
+const (
+	errClassNotFound  = "not_found"
+	errClassTransport = "transport"
+	errClassTool      = "tool_error"
+	errClassTimeout   = "timeout"
+	errClassCanceled  = "canceled"
+)
+
+// metricsRegistry holds every orchestrator metric. A dedicated registry (rather than the
+// global default) keeps the exposition limited to what MCP-NG itself reports plus the
+// standard Go and process collectors.
+var metricsRegistry = prometheus.NewRegistry()
+
+var (
+	mcpToolCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mcp_tool_calls_total",
+		Help: "Total number of ExecuteTool calls, by tool and outcome.",
+	}, []string{"tool", "outcome"})
+
+	mcpToolCallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mcp_tool_call_duration_seconds",
+		Help:    "Latency of ExecuteTool calls, by tool.",
+		Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"tool"})
+
+	mcpToolErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mcp_tool_errors_total",
+		Help: "Total number of failed ExecuteTool calls, by tool and error class.",
+	}, []string{"tool", "class"})
+
+	mcpToolInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mcp_tool_calls_in_flight",
+		Help: "Number of ExecuteTool calls currently being served, by tool.",
+	}, []string{"tool"})
+
+	mcpToolHealth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mcp_tool_health_status",
+		Help: "Health of each registered tool: 1 if SERVING, 0 otherwise.",
+	}, []string{"tool"})
+
+	mcpToolHealthTransitions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mcp_tool_health_transitions_total",
+		Help: "Number of times a tool's health status changed.",
+	}, []string{"tool"})
+
+	mcpToolRestarts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "mcp_tool_restarts_total",
+		Help: "Number of times a tool process was started again after its first launch.",
+	}, []string{"tool"})
+)
+
+func init() {
+	metricsRegistry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		mcpToolCalls,
+		mcpToolCallDuration,
+		mcpToolErrors,
+		mcpToolInFlight,
+		mcpToolHealth,
+		mcpToolHealthTransitions,
+		mcpToolRestarts,
+	)
+}
+
+// registerServerMetrics exposes gauges whose values are read from the server's state on scrape.
+func (s *server) registerServerMetrics() {
+	pending := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "mcp_human_input_pending",
+		Help: "Number of human-input tasks waiting for an operator response.",
+	}, func() float64 {
+		pending, err := s.humanInputs.List(humanStatusPending)
+		if err != nil {
+			logger.Warn("Failed to count pending human input tasks", "error", err)
+		}
+		return float64(len(pending))
+	})
+	// Only the first server in a process (the real one) owns this gauge; tests may create more.
+	if err := metricsRegistry.Register(pending); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if !errors.As(err, &are) {
+			logger.Warn("Failed to register human input metric", "error", err)
+		}
+	}
+}
+
+// metricsHandler serves the orchestrator metrics in the Prometheus exposition format.
+func metricsHandler() http.Handler {
+	return promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{})
+}
+
+// observeToolCall starts measuring an ExecuteTool call. The returned function must be
+// called exactly once with the call's final error (nil on success).
+func observeToolCall(tool string) func(error) {
+	start := time.Now()
+	inFlight := mcpToolInFlight.WithLabelValues(tool)
+	inFlight.Inc()
+	return func(err error) {
+		inFlight.Dec()
+		mcpToolCallDuration.WithLabelValues(tool).Observe(time.Since(start).Seconds())
+		if err == nil {
+			mcpToolCalls.WithLabelValues(tool, "ok").Inc()
+			return
+		}
+		mcpToolCalls.WithLabelValues(tool, "error").Inc()
+		mcpToolErrors.WithLabelValues(tool, classifyToolError(err)).Inc()
+	}
+}
+
+// classifyToolError maps an ExecuteTool error onto a small, stable set of classes.
+func classifyToolError(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return errClassTimeout
+	case errors.Is(err, context.Canceled):
+		return errClassCanceled
+	}
+	switch status.Code(err) {
+	case codes.NotFound:
+		return errClassNotFound
+	case codes.Aborted:
+		return errClassTool
+	case codes.DeadlineExceeded:
+		return errClassTimeout
+	case codes.Canceled:
+		return errClassCanceled
+	default:
+		return errClassTransport
+	}
+}
+
+// setToolHealthMetric records a tool's current health and counts status changes.
+func setToolHealthMetric(tool string, prev, curr grpc_health_v1.HealthCheckResponse_ServingStatus) {
+	if curr == grpc_health_v1.HealthCheckResponse_SERVING {
+		mcpToolHealth.WithLabelValues(tool).Set(1)
+	} else {
+		mcpToolHealth.WithLabelValues(tool).Set(0)
+	}
+	if prev != curr {
+		mcpToolHealthTransitions.WithLabelValues(tool).Inc()
+	}
+}
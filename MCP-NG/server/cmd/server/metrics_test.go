@@ -0,0 +1,70 @@
+// File: MCP-NG/server/cmd/server/metrics_test.go
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestClassifyToolError(t *testing.T) {
+	cases := map[string]error{
+		errClassNotFound:  status.Error(codes.NotFound, "missing"),
+		errClassTool:      status.Error(codes.Aborted, "tool failed"),
+		errClassTimeout:   status.Error(codes.DeadlineExceeded, "slow"),
+		errClassCanceled:  context.Canceled,
+		errClassTransport: errors.New("connection refused"),
+	}
+	for want, err := range cases {
+		if got := classifyToolError(err); got != want {
+			t.Errorf("classifyToolError(%v) = %q, want %q", err, got, want)
+		}
+	}
+}
+
+func TestObserveToolCall(t *testing.T) {
+	const tool = "metrics_test_tool"
+
+	finish := observeToolCall(tool)
+	if got := testutil.ToFloat64(mcpToolInFlight.WithLabelValues(tool)); got != 1 {
+		t.Errorf("in-flight during call = %v, want 1", got)
+	}
+	finish(nil)
+	observeToolCall(tool)(status.Error(codes.Aborted, "boom"))
+
+	if got := testutil.ToFloat64(mcpToolInFlight.WithLabelValues(tool)); got != 0 {
+		t.Errorf("in-flight after calls = %v, want 0", got)
+	}
+	if got := testutil.ToFloat64(mcpToolCalls.WithLabelValues(tool, "ok")); got != 1 {
+		t.Errorf("ok calls = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(mcpToolErrors.WithLabelValues(tool, errClassTool)); got != 1 {
+		t.Errorf("tool errors = %v, want 1", got)
+	}
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	setToolHealthMetric("metrics_test_health", grpc_health_v1.HealthCheckResponse_SERVING, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+
+	rec := httptest.NewRecorder()
+	metricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+
+	for _, want := range []string{
+		`mcp_tool_health_status{tool="metrics_test_health"} 0`,
+		`mcp_tool_health_transitions_total{tool="metrics_test_health"} 1`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("/metrics output is missing %q", want)
+		}
+	}
+}
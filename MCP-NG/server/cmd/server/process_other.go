@@ -0,0 +1,20 @@
+// File: MCP-NG/server/cmd/server/process_other.go
+//go:build !unix
+
+package main
+
+import "os/exec"
+
+// setProcessGroup does nothing; process groups only exist on Unix.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// terminateProcessGroup kills the tool: there is no portable way to ask a process to exit,
+// so it gets no grace period.
+func terminateProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+// killProcessGroup kills the tool. Its children are not reached.
+func killProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
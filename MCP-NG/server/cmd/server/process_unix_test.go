@@ -0,0 +1,118 @@
+// File: MCP-NG/server/cmd/server/process_unix_test.go
+//go:build unix
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	pb "mcp-ng/server/pkg/mcp"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// alive reports whether the process exists and has not exited; an exited child reparented
+// to an init that does not reap it stays a zombie.
+func alive(pid int) bool {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return false
+	}
+	fields := strings.Fields(string(data))
+	return len(fields) > 2 && fields[2] != "Z"
+}
+
+func TestStopProcessTerminatesGracefully(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "trap 'exit 0' TERM; while true; do sleep 0.1; done")
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond) // Let the shell install its trap
+	start := time.Now()
+	stopProcess(cmd, 10*time.Second)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the process to exit on SIGTERM, took %v", elapsed)
+	}
+	if !cmd.ProcessState.Success() {
+		t.Errorf("expected a clean exit, got %v", cmd.ProcessState)
+	}
+}
+
+func TestStopProcessKillsGroupAfterGrace(t *testing.T) {
+	// Both the shell and its child ignore SIGTERM.
+	cmd := exec.Command("sh", "-c", "trap '' TERM; sleep 60 & echo $!; wait")
+	setProcessGroup(cmd)
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	line, err := bufio.NewReader(out).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, _ := strconv.Atoi(strings.TrimSpace(line))
+
+	start := time.Now()
+	stopProcess(cmd, 300*time.Millisecond)
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+		t.Errorf("expected the grace period to pass first, took %v", elapsed)
+	}
+	for deadline := time.Now().Add(2 * time.Second); alive(child) && time.Now().Before(deadline); {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if alive(child) {
+		t.Errorf("expected the tool's child %d to be killed with its process group", child)
+	}
+}
+
+func TestDrainWaitsForRunningCalls(t *testing.T) {
+	release := make(chan struct{})
+	running := make(chan struct{})
+	s := newTestServerWithTools(map[string]*fakeToolClient{"slow": {run: func(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunResponse, error) {
+		close(running)
+		<-release
+		return &pb.ToolRunResponse{Result: structpb.NewStringValue("done")}, nil
+	}}})
+	go s.ExecuteTool(context.Background(), &pb.ExecuteToolRequest{ToolName: "slow"})
+	<-running
+
+	drained := make(chan struct{})
+	go func() {
+		s.drain(context.Background())
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		t.Fatal("expected drain to wait for the running call")
+	case <-time.After(100 * time.Millisecond):
+	}
+	if _, err := s.ExecuteTool(context.Background(), &pb.ExecuteToolRequest{ToolName: "slow"}); status.Code(err) != codes.Unavailable {
+		t.Errorf("expected new calls to be refused while draining, got %v", err)
+	}
+	close(release)
+	select {
+	case <-drained:
+	case <-time.After(5 * time.Second):
+		t.Fatal("drain did not return after the call finished")
+	}
+
+	// A call that outlives the grace period does not hold the shutdown up.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	s.calls.Add(1)
+	defer s.calls.Done()
+	s.drain(ctx)
+}
@@ -0,0 +1,182 @@
+// File: MCP-NG/server/cmd/server/replicas.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"time"
+
+	pb "mcp-ng/server/pkg/mcp"
+	"mcp-ng/server/pkg/telemetry"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Load-balancing policies accepted in a tool's config.json.
+const (
+	balanceRoundRobin  = "round_robin"  // Healthy replicas take turns (the default)
+	balanceLeastLoaded = "least_loaded" // The healthy replica with the fewest running calls
+)
+
+// healthCheckTimeout bounds a single health check, so an unreachable remote replica cannot
+// stall the checks of every other tool.
+const healthCheckTimeout = 2 * time.Second
+
+// toolReplica is one process or remote endpoint serving a tool. Calls are only routed to
+// replicas whose last health check reported SERVING.
+type toolReplica struct {
+	address      string          // Dial target
+	cmd          *exec.Cmd       // nil for replicas the server did not launch
+	sandbox      *sandboxProcess // nil unless the tool is sandboxed
+	conn         *grpc.ClientConn
+	client       pb.ToolClient
+	healthClient grpc_health_v1.HealthClient
+	status       grpc_health_v1.HealthCheckResponse_ServingStatus
+	inflight     int // Calls currently running on the replica
+}
+
+// connect dials the replica and fetches the tool's description, retrying while a freshly
+// launched process starts up. When every attempt fails, the replica keeps its last connection
+// so later health checks can admit it once it comes up.
+func (r *toolReplica) connect(toolName string) (*pb.ToolDescription, error) {
+	// Increased retries to handle slow tool startup, especially for tools compiled on the fly with 'go run'.
+	const maxRetries = 15
+	const retryDelay = 1 * time.Second
+
+	var err error
+	for i := 0; i < maxRetries; i++ {
+		if r.conn != nil {
+			r.conn.Close()
+			r.conn = nil
+		}
+		time.Sleep(retryDelay)
+		conn, connErr := grpc.NewClient(r.address, grpc.WithTransportCredentials(insecure.NewCredentials()), telemetry.DialOption())
+		if connErr != nil {
+			err = connErr
+			logger.Warn("Failed to create gRPC client for tool, retrying...", "tool", toolName, "address", r.address, "attempt", i+1, "error", err)
+			continue
+		}
+		r.conn = conn
+		r.client = pb.NewToolClient(conn)
+		r.healthClient = grpc_health_v1.NewHealthClient(conn)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		desc, descErr := r.client.GetDescription(ctx, &pb.GetDescriptionRequest{})
+		cancel()
+		if descErr == nil {
+			return desc, nil
+		}
+		err = descErr
+		logger.Warn("Failed to get description for tool, retrying...", "tool", toolName, "address", r.address, "attempt", i+1, "error", err)
+	}
+	return nil, fmt.Errorf("failed to connect to tool after multiple retries: %w", err)
+}
+
+// check runs a health check against the replica and records its status.
+func (r *toolReplica) check(toolName string) {
+	status, err := r.probe()
+	r.record(toolName, status, err)
+}
+
+// probe runs a health check against the replica and returns its status without recording
+// it, so it can run without s.mu.
+func (r *toolReplica) probe() (grpc_health_v1.HealthCheckResponse_ServingStatus, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+	resp, err := r.healthClient.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: "mcp.Tool"})
+	if err != nil {
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING, err
+	}
+	return resp.Status, nil
+}
+
+// record sets the replica's status from a health check, ejecting it if the check failed. The
+// caller must hold s.mu once the replica is registered.
+func (r *toolReplica) record(toolName string, status grpc_health_v1.HealthCheckResponse_ServingStatus, err error) {
+	if err != nil {
+		if r.status == grpc_health_v1.HealthCheckResponse_SERVING {
+			logger.Warn("Health check failed for tool replica, ejecting it", "tool", toolName, "address", r.address, "error", err)
+		}
+		r.status = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+		return
+	}
+	if r.status != status {
+		logger.Info("Health status changed for tool replica", "tool", toolName, "address", r.address, "status", status)
+	}
+	r.status = status
+}
+
+// stop closes the connection to the replica and ends its process, if the server launched it,
+// giving it grace to exit after SIGTERM.
+func (r *toolReplica) stop(grace time.Duration) {
+	if r.conn != nil {
+		r.conn.Close()
+	}
+	if r.cmd != nil && r.cmd.Process != nil {
+		// Wait so the address is free before the tool is started again.
+		stopProcess(r.cmd, grace)
+	}
+	r.sandbox.release()
+}
+
+// replicaCount is the number of processes the server launches for the tool.
+func (c toolConfig) replicaCount() int {
+	if c.Replicas == 0 {
+		return 1
+	}
+	return c.Replicas
+}
+
+// updateStatus derives the tool's status from its replicas: it is SERVING while any replica
+// is. The caller must hold s.mu.
+func (t *toolClient) updateStatus() {
+	t.status = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	for _, r := range t.replicas {
+		if r.status == grpc_health_v1.HealthCheckResponse_SERVING {
+			t.status = grpc_health_v1.HealthCheckResponse_SERVING
+			return
+		}
+	}
+}
+
+// pickReplica chooses the healthy replica for the next call according to the tool's
+// load-balancing policy, or returns nil when none is healthy. The caller must hold s.mu for
+// writing.
+func (t *toolClient) pickReplica() *toolReplica {
+	var picked *toolReplica
+	n := len(t.replicas)
+	for i := 0; i < n; i++ {
+		idx := (t.next + i) % n
+		r := t.replicas[idx]
+		if r.status != grpc_health_v1.HealthCheckResponse_SERVING {
+			continue
+		}
+		if t.config.LoadBalancing != balanceLeastLoaded {
+			t.next = idx + 1
+			return r
+		}
+		if picked == nil || r.inflight < picked.inflight {
+			picked = r
+		}
+	}
+	// Rotate the starting point so least-loaded ties are spread as well.
+	t.next++
+	return picked
+}
+
+// ejectReplica takes a replica out of rotation after a call to it failed at the transport
+// level. The next successful health check brings it back.
+func (s *server) ejectReplica(tool *toolClient, r *toolReplica) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.status != grpc_health_v1.HealthCheckResponse_SERVING {
+		return
+	}
+	logger.Warn("Tool replica is unreachable, ejecting it until its next health check", "tool", tool.description.Name, "address", r.address)
+	r.status = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	prev := tool.status
+	tool.updateStatus()
+	setToolHealthMetric(tool.description.Name, prev, tool.status)
+}
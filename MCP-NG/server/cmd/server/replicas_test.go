@@ -0,0 +1,142 @@
+// File: MCP-NG/server/cmd/server/replicas_test.go
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "mcp-ng/server/pkg/mcp"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	serving    = grpc_health_v1.HealthCheckResponse_SERVING
+	notServing = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+)
+
+func TestPickReplicaRoundRobin(t *testing.T) {
+	a, b, c := &toolReplica{status: serving}, &toolReplica{status: notServing}, &toolReplica{status: serving}
+	tool := &toolClient{replicas: []*toolReplica{a, b, c}}
+
+	want := []*toolReplica{a, c, a, c}
+	for i, w := range want {
+		if got := tool.pickReplica(); got != w {
+			t.Errorf("call %d: expected replica %p, got %p", i, w, got)
+		}
+	}
+
+	a.status, c.status = notServing, notServing
+	if got := tool.pickReplica(); got != nil {
+		t.Errorf("expected no replica while none is healthy, got %p", got)
+	}
+}
+
+func TestPickReplicaLeastLoaded(t *testing.T) {
+	a := &toolReplica{status: serving, inflight: 3}
+	b := &toolReplica{status: serving, inflight: 1}
+	c := &toolReplica{status: notServing}
+	tool := &toolClient{replicas: []*toolReplica{a, b, c}, config: toolConfig{LoadBalancing: balanceLeastLoaded}}
+
+	if got := tool.pickReplica(); got != b {
+		t.Errorf("expected the least loaded healthy replica, got %p", got)
+	}
+	b.inflight = 5
+	if got := tool.pickReplica(); got != a {
+		t.Errorf("expected the least loaded healthy replica, got %p", got)
+	}
+}
+
+func TestExecuteToolEjectsUnreachableReplica(t *testing.T) {
+	down := &fakeToolClient{run: func(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunResponse, error) {
+		return nil, status.Error(codes.Unavailable, "connection refused")
+	}}
+	up := &fakeToolClient{run: func(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunResponse, error) {
+		return &pb.ToolRunResponse{Result: structpb.NewStringValue("ok")}, nil
+	}}
+	s := newTestServerWithTools(map[string]*fakeToolClient{"echo": down})
+	tool := s.tools["echo"]
+	tool.replicas = append(tool.replicas, &toolReplica{client: up, status: serving})
+
+	if _, err := s.ExecuteTool(context.Background(), &pb.ExecuteToolRequest{ToolName: "echo"}); err == nil {
+		t.Fatal("expected the call to the unreachable replica to fail")
+	}
+	if tool.replicas[0].status != notServing || tool.status != serving {
+		t.Fatalf("expected only the unreachable replica to be ejected, got replica %v, tool %v", tool.replicas[0].status, tool.status)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := s.ExecuteTool(context.Background(), &pb.ExecuteToolRequest{ToolName: "echo"}); err != nil {
+			t.Fatalf("call %d: expected the healthy replica to serve, got %v", i, err)
+		}
+	}
+}
+
+func TestToolReplicasAreLaunched(t *testing.T) {
+	s, tool := newLazyCalculator(t, `{"command": ["calculator"], "lifecycle": "lazy", "replicas": 2}`)
+	calculate(t, s)
+	if len(tool.replicas) != 2 || len(s.toolCmds) != 2 {
+		t.Fatalf("expected two launched replicas, got %d replicas and %d processes", len(tool.replicas), len(s.toolCmds))
+	}
+	for _, r := range tool.replicas {
+		if r.status != serving {
+			t.Errorf("expected replica %s to be healthy, got %v", r.address, r.status)
+		}
+	}
+	calculate(t, s) // Served by the second replica
+
+	// A remote replica of the same tool, reached through its address.
+	remote, _ := newLazyCalculator(t, `{"lifecycle": "lazy", "addresses": ["`+tool.replicas[0].address+`"]}`)
+	calculate(t, remote)
+	if len(remote.toolCmds) != 0 {
+		t.Error("expected remote replicas not to be launched")
+	}
+}
+
+// slowHealthClient answers health checks with status once release is closed.
+type slowHealthClient struct {
+	grpc_health_v1.HealthClient
+	started chan struct{}
+	release chan struct{}
+	status  grpc_health_v1.HealthCheckResponse_ServingStatus
+}
+
+func (c *slowHealthClient) Check(ctx context.Context, in *grpc_health_v1.HealthCheckRequest, opts ...grpc.CallOption) (*grpc_health_v1.HealthCheckResponse, error) {
+	c.started <- struct{}{}
+	<-c.release
+	return &grpc_health_v1.HealthCheckResponse{Status: c.status}, nil
+}
+
+func TestCheckToolsDoesNotHoldTheLock(t *testing.T) {
+	s := newTestServerWithTools(map[string]*fakeToolClient{"echo": {}})
+	tool := s.tools["echo"]
+	health := &slowHealthClient{started: make(chan struct{}), release: make(chan struct{}), status: notServing}
+	tool.replicas[0].healthClient = health
+
+	done := make(chan struct{})
+	go func() {
+		s.checkTools()
+		close(done)
+	}()
+	<-health.started
+	locked := make(chan struct{})
+	go func() {
+		s.mu.Lock()
+		s.mu.Unlock()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+	case <-time.After(time.Second):
+		t.Fatal("expected the server lock to be free while a health check runs")
+	}
+	close(health.release)
+	<-done
+	if tool.replicas[0].status != notServing || tool.status != notServing {
+		t.Errorf("expected the check's result to be recorded, got replica %v, tool %v", tool.replicas[0].status, tool.status)
+	}
+}
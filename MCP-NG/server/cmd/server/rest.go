@@ -0,0 +1,264 @@
+// File: MCP-NG/server/cmd/server/rest.go
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+
+	pb "mcp-ng/server/pkg/mcp"
+
+	grpcRuntime "github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// registerToolRoutes mounts the typed per-tool endpoints and the OpenAPI document on the
+// gateway mux, next to the generated routes. Both read the live registry on every request,
+// so tools appear and disappear as they are registered or become unhealthy.
+func (s *server) registerToolRoutes(mux *grpcRuntime.ServeMux) error {
+	if err := mux.HandlePath(http.MethodPost, "/v1/tools/{name}", s.handleToolREST(mux)); err != nil {
+		return err
+	}
+	if err := mux.HandlePath(http.MethodGet, "/v1/tools/{name}/logs/stream", s.handleToolLogsSSE(mux)); err != nil {
+		return err
+	}
+	return mux.HandlePath(http.MethodGet, "/v1/openapi.json", s.handleOpenAPI)
+}
+
+// handleToolREST runs a tool with the JSON request body as its arguments. Optional task_id
+// and cache query parameters mirror the fields of ExecuteToolRequest.
+func (s *server) handleToolREST(mux *grpcRuntime.ServeMux) grpcRuntime.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+		_, marshaler := grpcRuntime.MarshalerForRequest(mux, r)
+		name := pathParams["name"]
+
+		args, err := decodeToolArguments(r.Body)
+		if err == nil {
+			err = s.validateToolArguments(name, args)
+		}
+		if err != nil {
+			grpcRuntime.HTTPError(r.Context(), mux, marshaler, w, r, err)
+			return
+		}
+		argsStruct, err := structpb.NewStruct(args)
+		if err != nil {
+			grpcRuntime.HTTPError(r.Context(), mux, marshaler, w, r, status.Errorf(codes.InvalidArgument, "invalid arguments: %v", err))
+			return
+		}
+
+		resp, err := s.ExecuteTool(r.Context(), &pb.ExecuteToolRequest{
+			TaskId:    r.URL.Query().Get("task_id"),
+			ToolName:  name,
+			Arguments: argsStruct,
+			Cache:     r.URL.Query().Get("cache"),
+		})
+		if err != nil {
+			grpcRuntime.HTTPError(r.Context(), mux, marshaler, w, r, err)
+			return
+		}
+		grpcRuntime.ForwardResponseMessage(r.Context(), mux, marshaler, w, r, resp)
+	}
+}
+
+// decodeToolArguments reads a JSON object from the request body. An empty body means no arguments.
+func decodeToolArguments(body io.Reader) (map[string]interface{}, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to read request body: %v", err)
+	}
+	args := map[string]interface{}{}
+	if len(data) == 0 {
+		return args, nil
+	}
+	if err := json.Unmarshal(data, &args); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "request body must be a JSON object of tool arguments: %v", err)
+	}
+	return args, nil
+}
+
+// validateToolArguments checks arguments against the tool's declared parameters: required
+// parameters must be present and declared parameters must have the declared JSON type.
+// Undeclared arguments are passed through. Unknown tools are left to ExecuteTool to report.
+func (s *server) validateToolArguments(name string, args map[string]interface{}) error {
+	s.mu.RLock()
+	tool, ok := s.tools[name]
+	s.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	params := tool.description.GetParameters()
+	for _, req := range params.GetRequired() {
+		if _, ok := args[req]; !ok {
+			return status.Errorf(codes.InvalidArgument, "missing required argument %q", req)
+		}
+	}
+	for key, value := range args {
+		p, ok := params.GetProperties()[key]
+		if !ok || p.Type == "" || value == nil {
+			continue
+		}
+		if !matchesJSONType(value, p.Type) {
+			return status.Errorf(codes.InvalidArgument, "argument %q must be of type %s", key, p.Type)
+		}
+	}
+	return nil
+}
+
+// matchesJSONType reports whether a decoded JSON value has the given JSON Schema type.
+func matchesJSONType(v interface{}, jsonType string) bool {
+	switch jsonType {
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "array":
+		_, ok := v.([]interface{})
+		return ok
+	case "object":
+		// Several tools declare "object" for values that may also be arrays, since a
+		// protobuf Struct field can carry either.
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			return true
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// handleOpenAPI serves an OpenAPI 3.1 document describing the per-tool endpoints of the
+// currently healthy tools.
+func (s *server) handleOpenAPI(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	s.mu.RLock()
+	var descs []*pb.ToolDescription
+	for _, tool := range s.tools {
+		if tool.available() {
+			descs = append(descs, tool.description)
+		}
+	}
+	s.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(openAPIDocument(descs)); err != nil {
+		logger.Error("Failed to write OpenAPI document", "error", err)
+	}
+}
+
+// openAPIDocument builds the OpenAPI 3.1 document for the given tools. Request bodies use
+// each tool's parameters as JSON Schema; responses follow the gateway's JSON encoding of
+// ExecuteToolResponse and its error format.
+func openAPIDocument(descs []*pb.ToolDescription) map[string]interface{} {
+	sort.Slice(descs, func(i, j int) bool { return descs[i].Name < descs[j].Name })
+	// OpenAI's name rules also make valid, unique operation IDs.
+	_, operationIDs, _ := formatTools(descs, toolFormatOpenAI)
+	byName := make(map[string]string, len(operationIDs))
+	for id, name := range operationIDs {
+		byName[name] = id
+	}
+
+	errorResponse := func(description string) map[string]interface{} {
+		return map[string]interface{}{
+			"description": description,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": map[string]interface{}{"$ref": "#/components/schemas/Status"}},
+			},
+		}
+	}
+	queryParam := func(name, description string) map[string]interface{} {
+		return map[string]interface{}{"name": name, "in": "query", "required": false, "description": description, "schema": map[string]interface{}{"type": "string"}}
+	}
+
+	paths := make(map[string]interface{}, len(descs))
+	for _, desc := range descs {
+		paths["/v1/tools/"+url.PathEscape(desc.Name)] = map[string]interface{}{
+			"post": map[string]interface{}{
+				"operationId": byName[desc.Name],
+				"summary":     fmt.Sprintf("Run the %s tool", desc.Name),
+				"description": desc.Description,
+				"tags":        []interface{}{"tools"},
+				"parameters": []interface{}{
+					queryParam("task_id", "Task ID passed to the tool and returned in the response."),
+					queryParam("cache", `Set to "bypass" to skip the result cache.`),
+				},
+				"requestBody": map[string]interface{}{
+					"required": true,
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{"schema": toolSchema(desc.Parameters, toolFormatMCP)},
+					},
+				},
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "The tool ran successfully, or the call is waiting for an operator's approval and has not run.",
+						"content": map[string]interface{}{
+							"application/json": map[string]interface{}{"schema": map[string]interface{}{"$ref": "#/components/schemas/ExecuteToolResponse"}},
+						},
+					},
+					"400":     errorResponse("The arguments do not match the tool's parameters."),
+					"404":     errorResponse("The tool is not registered or not healthy."),
+					"409":     errorResponse("The tool returned an error."),
+					"default": errorResponse("Unexpected error."),
+				},
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.1.0",
+		"info": map[string]interface{}{
+			"title":       "MCP-NG Tools",
+			"version":     "1.0.0",
+			"description": "Typed REST endpoints for the tools currently registered with the MCP-NG orchestrator.",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": map[string]interface{}{
+				"ExecuteToolResponse": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"taskId": map[string]interface{}{"type": "string"},
+						"result": map[string]interface{}{
+							"anyOf": []interface{}{
+								map[string]interface{}{"type": "object", "description": "The tool's result."},
+								map[string]interface{}{"$ref": "#/components/schemas/ApprovalPending"},
+							},
+						},
+						"cached": map[string]interface{}{"type": "boolean"},
+					},
+				},
+				"ApprovalPending": map[string]interface{}{
+					"type":        "object",
+					"description": "Returned instead of the tool's result when the call needs an operator's approval. Wait on the human-input task for the outcome.",
+					"required":    []interface{}{"status", "task_id", "tool_name"},
+					"properties": map[string]interface{}{
+						"status":    map[string]interface{}{"type": "string", "const": "waiting_for_human"},
+						"task_id":   map[string]interface{}{"type": "string", "description": "ID of the approval task."},
+						"tool_name": map[string]interface{}{"type": "string"},
+					},
+				},
+				"Status": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"code":    map[string]interface{}{"type": "integer", "description": "gRPC status code."},
+						"message": map[string]interface{}{"type": "string"},
+						"details": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "object"}},
+					},
+				},
+			},
+		},
+	}
+}
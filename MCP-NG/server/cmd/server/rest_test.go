@@ -0,0 +1,124 @@
+// File: MCP-NG/server/cmd/server/rest_test.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pb "mcp-ng/server/pkg/mcp"
+
+	grpcRuntime "github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func newRESTTestServer(t *testing.T) (*server, *httptest.Server) {
+	t.Helper()
+	s := newTestServerWithTools(map[string]*fakeToolClient{
+		"calculator": {run: func(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunResponse, error) {
+			return &pb.ToolRunResponse{Result: structpb.NewStringValue(in.Arguments.Fields["expression"].GetStringValue())}, nil
+		}},
+	})
+	s.tools["calculator"].description = &pb.ToolDescription{
+		Name:        "calculator",
+		Description: "Evaluates an expression.",
+		Parameters: &pb.ToolParameters{
+			Type:       "object",
+			Properties: map[string]*pb.ToolParameter{"expression": {Type: "string"}},
+			Required:   []string{"expression"},
+		},
+	}
+
+	mux := grpcRuntime.NewServeMux()
+	if err := s.registerToolRoutes(mux); err != nil {
+		t.Fatalf("registerToolRoutes failed: %v", err)
+	}
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return s, srv
+}
+
+func TestToolRESTEndpoint(t *testing.T) {
+	_, srv := newRESTTestServer(t)
+
+	tests := []struct {
+		name, path, body string
+		wantStatus       int
+	}{
+		{"success", "/v1/tools/calculator?task_id=t1", `{"expression": "1 + 1"}`, http.StatusOK},
+		{"missing required", "/v1/tools/calculator", `{}`, http.StatusBadRequest},
+		{"wrong type", "/v1/tools/calculator", `{"expression": 2}`, http.StatusBadRequest},
+		{"not an object", "/v1/tools/calculator", `[1]`, http.StatusBadRequest},
+		{"unknown tool", "/v1/tools/missing", `{}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(srv.URL+tt.path, "application/json", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var body struct {
+				TaskID string                 `json:"taskId"`
+				Result map[string]interface{} `json:"result"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if body.TaskID != "t1" || body.Result["result"] != "1 + 1" {
+				t.Errorf("unexpected response: %+v", body)
+			}
+		})
+	}
+}
+
+func TestOpenAPIDocumentTracksRegistry(t *testing.T) {
+	s, srv := newRESTTestServer(t)
+
+	fetch := func() map[string]interface{} {
+		resp, err := http.Get(srv.URL + "/v1/openapi.json")
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		var doc map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+			t.Fatalf("failed to decode document: %v", err)
+		}
+		return doc
+	}
+
+	doc := fetch()
+	if doc["openapi"] != "3.1.0" {
+		t.Errorf("unexpected openapi version: %v", doc["openapi"])
+	}
+	op, ok := doc["paths"].(map[string]interface{})["/v1/tools/calculator"].(map[string]interface{})["post"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected a calculator operation, got %v", doc["paths"])
+	}
+	schema := op["requestBody"].(map[string]interface{})["content"].(map[string]interface{})["application/json"].(map[string]interface{})["schema"].(map[string]interface{})
+	if _, ok := schema["properties"].(map[string]interface{})["expression"]; !ok || op["operationId"] != "calculator" {
+		t.Errorf("unexpected operation: %v", op)
+	}
+	// A gated call answers with an approval task instead of the tool's result.
+	pending, ok := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})["ApprovalPending"].(map[string]interface{})
+	if !ok || pending["properties"].(map[string]interface{})["status"].(map[string]interface{})["const"] != "waiting_for_human" {
+		t.Errorf("expected the approval response to be described, got %v", doc["components"])
+	}
+
+	s.mu.Lock()
+	delete(s.tools, "calculator")
+	s.mu.Unlock()
+	if paths := fetch()["paths"].(map[string]interface{}); len(paths) != 0 {
+		t.Errorf("expected removed tool to disappear from the document, got %v", paths)
+	}
+}
@@ -0,0 +1,153 @@
+// File: MCP-NG/server/cmd/server/sandbox.go
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+)
+
+// sandboxSpecEnv carries the sandbox settings from the server to the sandbox init process,
+// which applies them and then executes the tool. See sandbox_linux.go.
+const sandboxSpecEnv = "MCP_SANDBOX_SPEC"
+
+// sandboxConfig restricts a tool process the server launches. It is only supported on Linux.
+// Settings that cannot be applied make the tool fail to start, except the cgroup limits,
+// which are skipped with a warning when cgroup v2 is not available.
+type sandboxConfig struct {
+	// User runs the tool as this user, given as a name or numeric UID. Setting it requires the
+	// server to run as root.
+	User string `json:"user"`
+	// NoNewPrivileges keeps the tool and its children from gaining privileges, e.g. through
+	// setuid binaries.
+	NoNewPrivileges bool `json:"no_new_privileges"`
+	// IsolateNetwork runs the tool in its own network namespace without any interfaces. The
+	// server then reaches the tool over a Unix socket.
+	IsolateNetwork bool `json:"isolate_network"`
+
+	// Resource limits (setrlimit) of the tool process. Zero means unlimited.
+	CPUSeconds        uint64 `json:"cpu_seconds"`
+	AddressSpaceBytes uint64 `json:"address_space_bytes"`
+	MaxOpenFiles      uint64 `json:"max_open_files"`
+
+	// cgroup v2 limits for the tool and its children. Zero means unlimited.
+	MemoryMaxBytes int64   `json:"memory_max_bytes"`
+	CPUMax         float64 `json:"cpu_max"` // In CPUs, e.g. 0.5
+	PidsMax        int64   `json:"pids_max"`
+
+	// The filesystem view of the tool, set up in its own mount namespace. Read-only paths are
+	// applied first, then writable paths, so a writable path can be inside a read-only one.
+	// Hidden paths are covered by an empty directory.
+	ReadOnlyPaths []string `json:"read_only_paths"`
+	WritablePaths []string `json:"writable_paths"`
+	HiddenPaths   []string `json:"hidden_paths"`
+}
+
+// validate checks the settings that can be checked without starting the tool.
+func (c *sandboxConfig) validate() error {
+	if c.MemoryMaxBytes < 0 || c.CPUMax < 0 || c.PidsMax < 0 {
+		return fmt.Errorf("sandbox limits must not be negative")
+	}
+	for _, paths := range [][]string{c.ReadOnlyPaths, c.WritablePaths, c.HiddenPaths} {
+		for _, p := range paths {
+			if !strings.HasPrefix(p, "/") {
+				return fmt.Errorf("sandbox path %q must be absolute", p)
+			}
+		}
+	}
+	if c.User != "" {
+		if _, _, err := c.lookupUser(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isolatesNetwork reports whether the tool runs in its own network namespace. It is safe to
+// call on a nil config.
+func (c *sandboxConfig) isolatesNetwork() bool {
+	return c != nil && c.IsolateNetwork
+}
+
+// mountsFilesystem reports whether the tool needs its own mount namespace.
+func (c *sandboxConfig) mountsFilesystem() bool {
+	return len(c.ReadOnlyPaths) > 0 || len(c.WritablePaths) > 0 || len(c.HiddenPaths) > 0
+}
+
+// hasCgroupLimits reports whether any cgroup v2 limit is set.
+func (c *sandboxConfig) hasCgroupLimits() bool {
+	return c.MemoryMaxBytes > 0 || c.CPUMax > 0 || c.PidsMax > 0
+}
+
+// lookupUser resolves User to a UID and its primary GID. A numeric UID without an account
+// runs with the GID of the same number.
+func (c *sandboxConfig) lookupUser() (uid, gid int, err error) {
+	u, err := user.Lookup(c.User)
+	if err != nil {
+		u, err = user.LookupId(c.User)
+	}
+	if err != nil {
+		id, convErr := strconv.Atoi(c.User)
+		if convErr != nil || id < 0 {
+			return 0, 0, fmt.Errorf("unknown sandbox user %q", c.User)
+		}
+		return id, id, nil
+	}
+	if uid, err = strconv.Atoi(u.Uid); err != nil {
+		return 0, 0, fmt.Errorf("sandbox user %q has a non-numeric UID", c.User)
+	}
+	if gid, err = strconv.Atoi(u.Gid); err != nil {
+		return 0, 0, fmt.Errorf("sandbox user %q has a non-numeric GID", c.User)
+	}
+	return uid, gid, nil
+}
+
+// sandboxProcess tracks the sandbox of one launched tool process.
+type sandboxProcess struct {
+	// The sandbox init process writes its error to statusW, which it inherits as fd 3. The
+	// pipe is closed without data once the tool itself has been executed.
+	statusR, statusW *os.File
+	cgroup           string   // The tool's cgroup directory, if one was created
+	cgroupFD         *os.File // Open until the process has been created in the cgroup
+	warnings         []string // Settings that could not be applied
+}
+
+// started waits until the sandbox is set up and the tool executed, and returns the error
+// of the sandbox init process if it failed. It must be called once, after cmd.Start.
+func (p *sandboxProcess) started() error {
+	if p == nil {
+		return nil
+	}
+	if p.cgroupFD != nil {
+		p.cgroupFD.Close()
+	}
+	p.statusW.Close() // Our copy; the read below ends when the child's copy is closed
+	defer p.statusR.Close()
+	msg, err := io.ReadAll(p.statusR)
+	if err != nil {
+		return fmt.Errorf("failed to read sandbox status: %w", err)
+	}
+	if len(msg) > 0 {
+		return fmt.Errorf("sandbox: %s", msg)
+	}
+	return nil
+}
+
+// release removes what the sandbox left behind once the tool process has exited, or when
+// it could not be started.
+func (p *sandboxProcess) release() {
+	if p == nil {
+		return
+	}
+	for _, f := range []*os.File{p.statusR, p.statusW, p.cgroupFD} {
+		if f != nil {
+			f.Close()
+		}
+	}
+	if p.cgroup != "" {
+		os.Remove(p.cgroup)
+	}
+}
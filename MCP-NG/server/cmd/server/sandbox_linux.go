@@ -0,0 +1,276 @@
+// File: MCP-NG/server/cmd/server/sandbox_linux.go
+//go:build linux
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// The tool's sandbox is set up by re-executing the server binary as a small init process
+// in the tool's new namespaces. It applies the settings in sandboxSpecEnv that the kernel
+// only allows a process to apply to itself (mounts, rlimits, credentials, no_new_privs) and
+// then executes the tool in its place. Errors are written to statusFD for the server.
+const statusFD = 3
+
+// cgroupRoot is where the unified (v2) cgroup hierarchy is mounted.
+const cgroupRoot = "/sys/fs/cgroup"
+
+// prSetNoNewPrivs is PR_SET_NO_NEW_PRIVS from <linux/prctl.h>.
+const prSetNoNewPrivs = 38
+
+// sandboxSpec is what the sandbox init process needs to know, passed in sandboxSpecEnv.
+type sandboxSpec struct {
+	Path string   `json:"path"` // The tool executable
+	Args []string `json:"args"` // Including argv[0]
+
+	UID             int  `json:"uid"` // -1 keeps the server's credentials
+	GID             int  `json:"gid"`
+	NoNewPrivileges bool `json:"no_new_privileges"`
+
+	CPUSeconds        uint64 `json:"cpu_seconds"`
+	AddressSpaceBytes uint64 `json:"address_space_bytes"`
+	MaxOpenFiles      uint64 `json:"max_open_files"`
+
+	ReadOnlyPaths []string `json:"read_only_paths"`
+	WritablePaths []string `json:"writable_paths"`
+	HiddenPaths   []string `json:"hidden_paths"`
+}
+
+// sandboxCommand rewrites cmd so the tool runs in the sandbox described by cfg. Paths in
+// writable stay writable whatever the filesystem settings are; the server uses this for the
+// tool's socket directory. The returned process must be released once the tool has exited.
+func sandboxCommand(cmd *exec.Cmd, cfg *sandboxConfig, name string, writable []string) (*sandboxProcess, error) {
+	self, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate the server executable: %w", err)
+	}
+	spec := sandboxSpec{
+		Path:              cmd.Path,
+		Args:              cmd.Args,
+		UID:               -1,
+		GID:               -1,
+		NoNewPrivileges:   cfg.NoNewPrivileges,
+		CPUSeconds:        cfg.CPUSeconds,
+		AddressSpaceBytes: cfg.AddressSpaceBytes,
+		MaxOpenFiles:      cfg.MaxOpenFiles,
+		ReadOnlyPaths:     cfg.ReadOnlyPaths,
+		HiddenPaths:       cfg.HiddenPaths,
+		WritablePaths:     cfg.WritablePaths,
+	}
+	if cfg.User != "" {
+		if spec.UID, spec.GID, err = cfg.lookupUser(); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.mountsFilesystem() {
+		spec.WritablePaths = append(append([]string(nil), cfg.WritablePaths...), writable...)
+	}
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &sandboxProcess{}
+	if p.statusR, p.statusW, err = os.Pipe(); err != nil {
+		return nil, err
+	}
+	cmd.Path = self
+	cmd.Args = []string{name + " (sandbox)"}
+	cmd.Env = append(cmd.Env, sandboxSpecEnv+"="+string(data))
+	cmd.ExtraFiles = []*os.File{p.statusW} // Becomes statusFD
+	attr := &syscall.SysProcAttr{}
+	if cfg.IsolateNetwork {
+		attr.Cloneflags |= syscall.CLONE_NEWNET
+	}
+	if cfg.mountsFilesystem() {
+		attr.Cloneflags |= syscall.CLONE_NEWNS
+	}
+	cmd.SysProcAttr = attr
+
+	if cfg.hasCgroupLimits() {
+		dir, err := createCgroup(name, cfg)
+		if err != nil {
+			p.warnings = append(p.warnings, fmt.Sprintf("cgroup limits not applied: %v", err))
+		} else {
+			fd, err := os.Open(dir)
+			if err != nil {
+				os.Remove(dir)
+				p.warnings = append(p.warnings, fmt.Sprintf("cgroup limits not applied: %v", err))
+			} else {
+				// The process is created directly in the cgroup, so no part of it runs
+				// without the limits.
+				attr.UseCgroupFD = true
+				attr.CgroupFD = int(fd.Fd())
+				p.cgroup, p.cgroupFD = dir, fd
+			}
+		}
+	}
+	return p, nil
+}
+
+// createCgroup creates a cgroup for one tool process next to the server's own cgroup and
+// writes the configured limits.
+func createCgroup(name string, cfg *sandboxConfig) (string, error) {
+	if _, err := os.Stat(filepath.Join(cgroupRoot, "cgroup.controllers")); err != nil {
+		return "", fmt.Errorf("cgroup v2 is not available")
+	}
+	own, err := ownCgroup()
+	if err != nil {
+		return "", err
+	}
+	parent := filepath.Join(cgroupRoot, filepath.Dir(own))
+	if filepath.Dir(own) == "/" {
+		parent = filepath.Join(cgroupRoot, own)
+	}
+	// Delegating the controllers fails if they already are, which is fine; writing the
+	// limits below reports a controller that is really missing.
+	os.WriteFile(filepath.Join(parent, "cgroup.subtree_control"), []byte("+memory +cpu +pids"), 0)
+
+	dir, err := os.MkdirTemp(parent, "mcp-ng-"+name+"-")
+	if err != nil {
+		return "", err
+	}
+	limits := map[string]string{}
+	if cfg.MemoryMaxBytes > 0 {
+		limits["memory.max"] = strconv.FormatInt(cfg.MemoryMaxBytes, 10)
+	}
+	if cfg.CPUMax > 0 {
+		const period = 100000
+		limits["cpu.max"] = fmt.Sprintf("%d %d", int64(cfg.CPUMax*period), period)
+	}
+	if cfg.PidsMax > 0 {
+		limits["pids.max"] = strconv.FormatInt(cfg.PidsMax, 10)
+	}
+	for file, value := range limits {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(value), 0); err != nil {
+			os.Remove(dir)
+			return "", fmt.Errorf("failed to set %s: %w", file, err)
+		}
+	}
+	return dir, nil
+}
+
+// ownCgroup returns the server's cgroup path in the unified hierarchy.
+func ownCgroup() (string, error) {
+	f, err := os.Open("/proc/self/cgroup")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if path, ok := strings.CutPrefix(scanner.Text(), "0::"); ok {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("the server is not in a cgroup v2 hierarchy")
+}
+
+// maybeRunSandboxInit turns the process into the sandbox init process when it was started
+// as one. It does not return in that case.
+func maybeRunSandboxInit() {
+	data, ok := os.LookupEnv(sandboxSpecEnv)
+	if !ok {
+		return
+	}
+	status := os.NewFile(statusFD, "sandbox-status")
+	err := runSandboxInit(data)
+	// runSandboxInit only returns if executing the tool failed.
+	fmt.Fprint(status, err)
+	os.Exit(1)
+}
+
+func runSandboxInit(data string) error {
+	// no_new_privs is per thread; keep the thread that ends up executing the tool.
+	runtime.LockOSThread()
+	syscall.CloseOnExec(statusFD)
+	os.Unsetenv(sandboxSpecEnv)
+
+	var spec sandboxSpec
+	if err := json.Unmarshal([]byte(data), &spec); err != nil {
+		return fmt.Errorf("invalid spec: %v", err)
+	}
+	if err := setupMounts(&spec); err != nil {
+		return err
+	}
+	rlimits := []struct {
+		resource int
+		value    uint64
+		name     string
+	}{
+		{syscall.RLIMIT_CPU, spec.CPUSeconds, "cpu_seconds"},
+		{syscall.RLIMIT_AS, spec.AddressSpaceBytes, "address_space_bytes"},
+		{syscall.RLIMIT_NOFILE, spec.MaxOpenFiles, "max_open_files"},
+	}
+	for _, l := range rlimits {
+		if l.value == 0 {
+			continue
+		}
+		if err := syscall.Setrlimit(l.resource, &syscall.Rlimit{Cur: l.value, Max: l.value}); err != nil {
+			return fmt.Errorf("failed to set %s: %v", l.name, err)
+		}
+	}
+	if spec.UID >= 0 {
+		if err := syscall.Setgroups(nil); err != nil {
+			return fmt.Errorf("failed to drop supplementary groups: %v", err)
+		}
+		if err := syscall.Setgid(spec.GID); err != nil {
+			return fmt.Errorf("failed to switch to GID %d: %v", spec.GID, err)
+		}
+		if err := syscall.Setuid(spec.UID); err != nil {
+			return fmt.Errorf("failed to switch to UID %d: %v", spec.UID, err)
+		}
+	}
+	if spec.NoNewPrivileges {
+		if _, _, errno := syscall.RawSyscall6(syscall.SYS_PRCTL, prSetNoNewPrivs, 1, 0, 0, 0, 0); errno != 0 {
+			return fmt.Errorf("failed to set no_new_privs: %v", errno)
+		}
+	}
+	if err := syscall.Exec(spec.Path, spec.Args, os.Environ()); err != nil {
+		return fmt.Errorf("failed to execute %s: %v", spec.Path, err)
+	}
+	return nil
+}
+
+// setupMounts builds the tool's filesystem view in its private mount namespace.
+func setupMounts(spec *sandboxSpec) error {
+	if len(spec.ReadOnlyPaths)+len(spec.WritablePaths)+len(spec.HiddenPaths) == 0 {
+		return nil
+	}
+	// Keep the changes below from propagating to the server's mount namespace.
+	if err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("failed to make mounts private: %v", err)
+	}
+	bind := func(path string, flags uintptr) error {
+		if err := syscall.Mount(path, path, "", syscall.MS_BIND|syscall.MS_REC, ""); err != nil {
+			return err
+		}
+		return syscall.Mount(path, path, "", syscall.MS_BIND|syscall.MS_REMOUNT|flags, "")
+	}
+	for _, path := range spec.ReadOnlyPaths {
+		if err := bind(path, syscall.MS_RDONLY); err != nil {
+			return fmt.Errorf("failed to make %s read-only: %v", path, err)
+		}
+	}
+	for _, path := range spec.WritablePaths {
+		if err := bind(path, 0); err != nil {
+			return fmt.Errorf("failed to make %s writable: %v", path, err)
+		}
+	}
+	for _, path := range spec.HiddenPaths {
+		if err := syscall.Mount("tmpfs", path, "tmpfs", syscall.MS_RDONLY|syscall.MS_NOSUID|syscall.MS_NODEV, "size=0"); err != nil {
+			return fmt.Errorf("failed to hide %s: %v", path, err)
+		}
+	}
+	return nil
+}
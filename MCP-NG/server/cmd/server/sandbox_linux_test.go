@@ -0,0 +1,70 @@
+// File: MCP-NG/server/cmd/server/sandbox_linux_test.go
+//go:build linux
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	pb "mcp-ng/server/pkg/mcp"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSandboxedToolRuns(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("namespaces require root")
+	}
+	hidden := t.TempDir()
+	if err := os.WriteFile(hidden+"/secret", []byte("x"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	s, tool := newLazyCalculator(t, fmt.Sprintf(`{"command": ["calculator"], "lifecycle": "lazy", "sandbox": {
+		"no_new_privileges": true, "isolate_network": true, "max_open_files": 256,
+		"read_only_paths": ["/"], "hidden_paths": [%q]}}`, hidden))
+	calculate(t, s)
+
+	r := tool.replicas[0]
+	if !strings.HasPrefix(r.address, "unix://") {
+		t.Errorf("expected a network-isolated tool to be reached over a Unix socket, got %s", r.address)
+	}
+	pid := r.cmd.Process.Pid
+	procStatus, _ := os.ReadFile(fmt.Sprintf("/proc/%d/status", pid))
+	if !strings.Contains(string(procStatus), "NoNewPrivs:\t1") {
+		t.Error("expected no_new_privs to be set")
+	}
+	limits, _ := os.ReadFile(fmt.Sprintf("/proc/%d/limits", pid))
+	if !strings.Contains(string(limits), "Max open files            256") {
+		t.Errorf("expected the open files limit to be applied, got:\n%s", limits)
+	}
+	toolNet, _ := os.Readlink(fmt.Sprintf("/proc/%d/ns/net", pid))
+	ownNet, _ := os.Readlink("/proc/self/ns/net")
+	if toolNet == ownNet {
+		t.Error("expected the tool to run in its own network namespace")
+	}
+	// The mounts are private to the tool.
+	if _, err := os.Stat(hidden + "/secret"); err != nil {
+		t.Errorf("expected hiding a path in the tool not to affect the server: %v", err)
+	}
+}
+
+func TestSandboxFailureIsReported(t *testing.T) {
+	s, _ := newLazyCalculator(t, `{"command": ["calculator"], "lifecycle": "lazy", "sandbox": {"read_only_paths": ["/nonexistent-mcp-ng"]}}`)
+	_, err := s.ExecuteTool(context.Background(), &pb.ExecuteToolRequest{ToolName: "calculator"})
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected UNAVAILABLE, got %v", err)
+	}
+
+	resp, err := s.GetToolStatus(context.Background(), &pb.GetToolStatusRequest{})
+	if err != nil || len(resp.Tools) != 1 {
+		t.Fatalf("unexpected status: %v, %v", resp, err)
+	}
+	if st := resp.Tools[0]; st.Status != toolStatusFailed || st.Error == "" || st.Name != "calculator" {
+		t.Errorf("expected the failed start to be reported, got %v", st)
+	}
+}
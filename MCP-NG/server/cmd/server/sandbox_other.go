@@ -0,0 +1,17 @@
+// File: MCP-NG/server/cmd/server/sandbox_other.go
+//go:build !linux
+
+package main
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// sandboxCommand fails: sandboxing needs Linux namespaces, rlimits and cgroups.
+func sandboxCommand(cmd *exec.Cmd, cfg *sandboxConfig, name string, writable []string) (*sandboxProcess, error) {
+	return nil, errors.New("sandboxing is only supported on Linux")
+}
+
+// maybeRunSandboxInit does nothing; the sandbox init process only exists on Linux.
+func maybeRunSandboxInit() {}
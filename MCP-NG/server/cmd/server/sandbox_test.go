@@ -0,0 +1,31 @@
+// File: MCP-NG/server/cmd/server/sandbox_test.go
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSandboxConfigValidate(t *testing.T) {
+	valid := `{"user": "0", "cpu_seconds": 10, "memory_max_bytes": 1048576, "cpu_max": 0.5, "read_only_paths": ["/"]}`
+	invalid := []string{
+		`{"memory_max_bytes": -1}`,
+		`{"read_only_paths": ["relative"]}`,
+		`{"user": "no-such-user-mcp-ng"}`,
+	}
+	check := func(config string) error {
+		var c sandboxConfig
+		if err := json.Unmarshal([]byte(config), &c); err != nil {
+			t.Fatal(err)
+		}
+		return c.validate()
+	}
+	if err := check(valid); err != nil {
+		t.Errorf("expected a valid config, got %v", err)
+	}
+	for _, config := range invalid {
+		if check(config) == nil {
+			t.Errorf("%s: expected an error", config)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// File: MCP-NG/server/cmd/server/toolstatus.go
+package main
+
+import (
+	"context"
+	"path/filepath"
+
+	pb "mcp-ng/server/pkg/mcp"
+
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Tool statuses reported in ToolStatus.status.
+const (
+	toolStatusServing    = "serving"
+	toolStatusNotServing = "not_serving"
+	toolStatusStopped    = "stopped"
+	toolStatusFailed     = "failed"
+)
+
+// GetToolStatus reports every discovered tool, including tools that are not running or
+// failed to start, followed by registered tools the server did not discover.
+func (s *server) GetToolStatus(ctx context.Context, in *pb.GetToolStatusRequest) (*pb.GetToolStatusResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	resp := &pb.GetToolStatusResponse{}
+	seen := make(map[*toolClient]bool)
+	for _, tool := range s.discovered {
+		seen[tool] = true
+		resp.Tools = append(resp.Tools, tool.statusReport())
+	}
+	for _, tool := range s.tools {
+		if !seen[tool] {
+			resp.Tools = append(resp.Tools, tool.statusReport())
+		}
+	}
+	return resp, nil
+}
+
+// statusReport describes the tool for GetToolStatus. The caller must hold s.mu.
+func (t *toolClient) statusReport() *pb.ToolStatus {
+	report := &pb.ToolStatus{
+		Error:    t.lastError,
+		Warnings: t.warnings,
+	}
+	if t.dir != "" {
+		report.Dir = filepath.Base(t.dir)
+	}
+	report.Name = report.Dir
+	if t.description != nil {
+		report.Name = t.description.Name
+	}
+	switch {
+	case t.lastError != "" && len(t.replicas) == 0:
+		report.Status = toolStatusFailed
+	case t.startOnCall:
+		report.Status = toolStatusStopped
+	case t.status == grpc_health_v1.HealthCheckResponse_SERVING:
+		report.Status = toolStatusServing
+	default:
+		report.Status = toolStatusNotServing
+	}
+	for _, r := range t.replicas {
+		replica := &pb.ToolReplicaStatus{Address: r.address, Status: toolStatusNotServing}
+		if r.status == grpc_health_v1.HealthCheckResponse_SERVING {
+			replica.Status = toolStatusServing
+		}
+		if r.cmd != nil && r.cmd.Process != nil {
+			replica.Pid = int32(r.cmd.Process.Pid)
+		}
+		report.Replicas = append(report.Replicas, replica)
+	}
+	return report
+}
@@ -0,0 +1,592 @@
+// File: MCP-NG/server/cmd/server/workflow.go
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	pb "mcp-ng/server/pkg/mcp"
+
+	"github.com/google/uuid"
+	"go.yaml.in/yaml/v3"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Step statuses reported in WorkflowStepResult.status.
+const (
+	stepSucceeded = "succeeded"
+	stepFailed    = "failed"
+	stepSkipped   = "skipped"
+	stepCanceled  = "canceled"
+)
+
+// Failure policies for a step's on_error field.
+const (
+	onErrorFail     = "fail"
+	onErrorContinue = "continue"
+)
+
+// workflowDefinition is a workflow document. JSON is a subset of YAML, so both formats are
+// decoded with the YAML parser.
+type workflowDefinition struct {
+	Name        string                 `yaml:"name"`
+	Description string                 `yaml:"description"`
+	TimeoutMs   int64                  `yaml:"timeout_ms"` // 0 means only the caller's deadline applies
+	Inputs      map[string]interface{} `yaml:"inputs"`     // Default values for the request inputs
+	Steps       []*workflowStep        `yaml:"steps"`
+	Outputs     map[string]interface{} `yaml:"outputs"` // Rendered after all steps succeeded
+}
+
+// workflowStep is a single tool call in a workflow. String values in arguments, if and
+// for_each are Go templates evaluated against the workflow inputs and earlier step results.
+type workflowStep struct {
+	ID           string                 `yaml:"id"`
+	Tool         string                 `yaml:"tool"`
+	Arguments    map[string]interface{} `yaml:"arguments"`
+	DependsOn    []string               `yaml:"depends_on"` // Steps referenced in templates are added implicitly
+	If           string                 `yaml:"if"`         // The step is skipped unless this renders to a truthy value
+	ForEach      string                 `yaml:"for_each"`   // Must render to an array; the tool runs once per item
+	OnError      string                 `yaml:"on_error"`   // "fail" (default) or "continue"
+	Retries      int                    `yaml:"retries"`
+	RetryDelayMs int64                  `yaml:"retry_delay_ms"`
+	TimeoutMs    int64                  `yaml:"timeout_ms"` // Per attempt
+}
+
+var (
+	stepIDPattern  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	stepRefPattern = regexp.MustCompile(`\.steps\.([A-Za-z_][A-Za-z0-9_]*)`)
+	// wholeExprPattern matches a string consisting of a single template action, whose value
+	// keeps its type (object, array, number...) instead of being rendered as text.
+	wholeExprPattern = regexp.MustCompile(`^\s*\{\{-?\s*(.*?)\s*-?\}\}\s*$`)
+)
+
+// parseWorkflow decodes and validates a YAML or JSON workflow definition.
+func parseWorkflow(data []byte) (*workflowDefinition, error) {
+	var def workflowDefinition
+	if err := yaml.Unmarshal(data, &def); err != nil {
+		return nil, fmt.Errorf("failed to parse workflow definition: %w", err)
+	}
+	if err := def.validate(); err != nil {
+		return nil, err
+	}
+	return &def, nil
+}
+
+// validate checks step IDs and policies, resolves implicit dependencies and rejects cycles.
+func (def *workflowDefinition) validate() error {
+	if len(def.Steps) == 0 {
+		return errors.New("workflow has no steps")
+	}
+	steps := make(map[string]*workflowStep, len(def.Steps))
+	for _, step := range def.Steps {
+		if !stepIDPattern.MatchString(step.ID) {
+			return fmt.Errorf("invalid step id %q: must be a letter or underscore followed by letters, digits or underscores", step.ID)
+		}
+		if _, dup := steps[step.ID]; dup {
+			return fmt.Errorf("duplicate step id %q", step.ID)
+		}
+		if step.Tool == "" {
+			return fmt.Errorf("step %q: tool is required", step.ID)
+		}
+		switch step.OnError {
+		case "":
+			step.OnError = onErrorFail
+		case onErrorFail, onErrorContinue:
+		default:
+			return fmt.Errorf("step %q: unsupported on_error %q", step.ID, step.OnError)
+		}
+		steps[step.ID] = step
+	}
+
+	for _, step := range def.Steps {
+		deps := make(map[string]bool)
+		for _, dep := range step.DependsOn {
+			deps[dep] = true
+		}
+		for _, ref := range step.references() {
+			deps[ref] = true
+		}
+		step.DependsOn = step.DependsOn[:0]
+		for dep := range deps {
+			if _, ok := steps[dep]; !ok {
+				return fmt.Errorf("step %q depends on unknown step %q", step.ID, dep)
+			}
+			if dep == step.ID {
+				return fmt.Errorf("step %q depends on itself", step.ID)
+			}
+			step.DependsOn = append(step.DependsOn, dep)
+		}
+	}
+
+	// Kahn's algorithm: if some steps never become ready, they form a cycle.
+	indegree := make(map[string]int, len(def.Steps))
+	dependents := make(map[string][]string)
+	for _, step := range def.Steps {
+		indegree[step.ID] = len(step.DependsOn)
+		for _, dep := range step.DependsOn {
+			dependents[dep] = append(dependents[dep], step.ID)
+		}
+	}
+	var ready []string
+	for id, n := range indegree {
+		if n == 0 {
+			ready = append(ready, id)
+		}
+	}
+	visited := 0
+	for len(ready) > 0 {
+		id := ready[0]
+		ready = ready[1:]
+		visited++
+		for _, next := range dependents[id] {
+			if indegree[next]--; indegree[next] == 0 {
+				ready = append(ready, next)
+			}
+		}
+	}
+	if visited != len(def.Steps) {
+		return errors.New("workflow steps contain a dependency cycle")
+	}
+	return nil
+}
+
+// references returns the IDs of the steps whose results the step's templates use.
+func (step *workflowStep) references() []string {
+	var refs []string
+	collect := func(s string) {
+		for _, m := range stepRefPattern.FindAllStringSubmatch(s, -1) {
+			refs = append(refs, m[1])
+		}
+	}
+	collect(step.If)
+	collect(step.ForEach)
+	walkStrings(step.Arguments, collect)
+	return refs
+}
+
+// walkStrings calls fn for every string nested in v.
+func walkStrings(v interface{}, fn func(string)) {
+	switch v := v.(type) {
+	case string:
+		fn(v)
+	case map[string]interface{}:
+		for _, item := range v {
+			walkStrings(item, fn)
+		}
+	case []interface{}:
+		for _, item := range v {
+			walkStrings(item, fn)
+		}
+	}
+}
+
+var templateFuncs = template.FuncMap{
+	"toJSON": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// renderTemplate evaluates a template string. A string made of a single action keeps the
+// type of its value, so "{{ .steps.search.result.items }}" yields an array; anything else is
+// rendered as text. Strings without actions are returned unchanged.
+func renderTemplate(text string, data map[string]interface{}) (interface{}, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	if m := wholeExprPattern.FindStringSubmatch(text); m != nil && !strings.Contains(m[1], "{{") {
+		out, err := executeTemplate("{{ toJSON ("+m[1]+") }}", data)
+		if err != nil {
+			return nil, err
+		}
+		var value interface{}
+		if err := json.Unmarshal([]byte(out), &value); err != nil {
+			return nil, fmt.Errorf("failed to decode template value: %w", err)
+		}
+		return value, nil
+	}
+	return executeTemplate(text, data)
+}
+
+func executeTemplate(text string, data map[string]interface{}) (string, error) {
+	tmpl, err := template.New("").Option("missingkey=error").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid template %q: %w", text, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template %q: %w", text, err)
+	}
+	return buf.String(), nil
+}
+
+// renderValue renders every template string nested in v.
+func renderValue(v interface{}, data map[string]interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case string:
+		return renderTemplate(v, data)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			rendered, err := renderValue(item, data)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = rendered
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			rendered, err := renderValue(item, data)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = rendered
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// isTruthy reports whether a rendered condition holds. nil, false, zero, empty strings,
+// "false" and empty collections are false.
+func isTruthy(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		s := strings.TrimSpace(v)
+		return s != "" && s != "false" && s != "0"
+	case []interface{}:
+		return len(v) > 0
+	case map[string]interface{}:
+		return len(v) > 0
+	default:
+		return true
+	}
+}
+
+// workflowRun holds the state of one workflow execution.
+type workflowRun struct {
+	s       *server
+	def     *workflowDefinition
+	taskID  string
+	inputs  map[string]interface{}
+	results map[string]*pb.WorkflowStepResult
+	values  map[string]interface{} // Step results as plain values, for templates
+	done    map[string]chan struct{}
+
+	mu     sync.Mutex
+	err    error // First error that failed the workflow
+	cancel context.CancelFunc
+}
+
+// RunWorkflow runs an inline or stored workflow definition to completion. Invalid definitions
+// are rejected; failures while running are reported in the response.
+func (s *server) RunWorkflow(ctx context.Context, in *pb.RunWorkflowRequest) (*pb.RunWorkflowResponse, error) {
+	logger.Info("Received request to run workflow", "workflow", in.WorkflowName, "task_id", in.TaskId)
+
+	var data []byte
+	switch {
+	case in.Definition != "" && in.WorkflowName != "":
+		return nil, status.Error(codes.InvalidArgument, "only one of definition or workflow_name can be set")
+	case in.Definition != "":
+		data = []byte(in.Definition)
+	case in.WorkflowName != "":
+		var err error
+		if data, err = s.loadWorkflow(in.WorkflowName); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, status.Error(codes.InvalidArgument, "either definition or workflow_name is required")
+	}
+
+	def, err := parseWorkflow(data)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if def.Name == "" {
+		def.Name = in.WorkflowName
+	}
+
+	taskID := in.TaskId
+	if taskID == "" {
+		taskID = uuid.NewString()
+	}
+	inputs := make(map[string]interface{}, len(def.Inputs))
+	for k, v := range def.Inputs {
+		inputs[k] = v
+	}
+	for k, v := range in.Inputs.AsMap() {
+		inputs[k] = v
+	}
+
+	if def.TimeoutMs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(def.TimeoutMs)*time.Millisecond)
+		defer cancel()
+	}
+
+	resp := newWorkflowRun(s, def, taskID, inputs).execute(ctx)
+	logger.Info("Workflow finished", "workflow", def.Name, "task_id", taskID, "status", resp.Status)
+	return resp, nil
+}
+
+// loadWorkflow reads a stored workflow by name from the workflows directory.
+func (s *server) loadWorkflow(name string) ([]byte, error) {
+	if name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid workflow name %q", name)
+	}
+	for _, ext := range []string{".yaml", ".yml", ".json"} {
+		data, err := os.ReadFile(filepath.Join(s.workflowsDir, name+ext))
+		if err == nil {
+			return data, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, status.Errorf(codes.Internal, "failed to read workflow %q: %v", name, err)
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "workflow %q not found", name)
+}
+
+func newWorkflowRun(s *server, def *workflowDefinition, taskID string, inputs map[string]interface{}) *workflowRun {
+	r := &workflowRun{
+		s:       s,
+		def:     def,
+		taskID:  taskID,
+		inputs:  inputs,
+		results: make(map[string]*pb.WorkflowStepResult, len(def.Steps)),
+		values:  make(map[string]interface{}, len(def.Steps)),
+		done:    make(map[string]chan struct{}, len(def.Steps)),
+	}
+	for _, step := range def.Steps {
+		r.results[step.ID] = &pb.WorkflowStepResult{Id: step.ID, ToolName: step.Tool}
+		r.done[step.ID] = make(chan struct{})
+	}
+	return r
+}
+
+// execute starts every step in its own goroutine. A step waits for its dependencies, so
+// independent branches of the DAG run in parallel.
+func (r *workflowRun) execute(ctx context.Context) *pb.RunWorkflowResponse {
+	ctx, r.cancel = context.WithCancel(ctx)
+	defer r.cancel()
+
+	var wg sync.WaitGroup
+	for _, step := range r.def.Steps {
+		wg.Add(1)
+		go func(step *workflowStep) {
+			defer wg.Done()
+			defer close(r.done[step.ID])
+			r.runStep(ctx, step)
+		}(step)
+	}
+	wg.Wait()
+
+	resp := &pb.RunWorkflowResponse{TaskId: r.taskID, WorkflowName: r.def.Name, Status: stepSucceeded}
+	for _, step := range r.def.Steps {
+		resp.Steps = append(resp.Steps, r.results[step.ID])
+	}
+	if r.err == nil && ctx.Err() != nil {
+		r.err = fmt.Errorf("workflow canceled: %w", ctx.Err())
+	}
+	if r.err == nil {
+		outputs, err := r.renderOutputs()
+		if err == nil {
+			resp.Outputs = outputs
+		}
+		r.err = err
+	}
+	if r.err != nil {
+		resp.Status = stepFailed
+		resp.Error = r.err.Error()
+	}
+	return resp
+}
+
+// runStep waits for the step's dependencies and runs it unless the workflow has already failed.
+func (r *workflowRun) runStep(ctx context.Context, step *workflowStep) {
+	result := r.results[step.ID]
+	for _, dep := range step.DependsOn {
+		select {
+		case <-r.done[dep]:
+		case <-ctx.Done():
+		}
+	}
+	if ctx.Err() != nil {
+		result.Status = stepCanceled
+		return
+	}
+
+	start := time.Now()
+	data := r.templateData(step.DependsOn)
+	value, err := r.evaluateStep(ctx, step, data, result)
+	result.DurationMs = time.Since(start).Milliseconds()
+
+	state := map[string]interface{}{"status": result.Status, "result": value, "error": ""}
+	switch {
+	case err != nil && ctx.Err() != nil:
+		// The workflow failed elsewhere or ran out of time while this step was running.
+		result.Status = stepCanceled
+		result.Error = err.Error()
+	case err != nil:
+		result.Status = stepFailed
+		result.Error = err.Error()
+		state["status"], state["error"] = stepFailed, result.Error
+		if step.OnError == onErrorFail {
+			r.fail(fmt.Errorf("step %q failed: %w", step.ID, err))
+		}
+	}
+	if value != nil {
+		if v, err := structpb.NewValue(value); err == nil {
+			result.Result = v
+		}
+	}
+
+	r.mu.Lock()
+	r.values[step.ID] = state
+	r.mu.Unlock()
+	logger.Info("Workflow step finished", "task_id", r.taskID, "step", step.ID, "tool", step.Tool, "status", result.Status)
+}
+
+// evaluateStep checks the step's condition, expands for_each and calls the tool.
+func (r *workflowRun) evaluateStep(ctx context.Context, step *workflowStep, data map[string]interface{}, result *pb.WorkflowStepResult) (interface{}, error) {
+	if step.If != "" {
+		cond, err := renderTemplate(step.If, data)
+		if err != nil {
+			return nil, fmt.Errorf("if: %w", err)
+		}
+		if !isTruthy(cond) {
+			result.Status = stepSkipped
+			return nil, nil
+		}
+	}
+
+	if step.ForEach == "" {
+		value, err := r.callTool(ctx, step, data, r.taskID+"/"+step.ID, result)
+		if err == nil {
+			result.Status = stepSucceeded
+		}
+		return value, err
+	}
+
+	rendered, err := renderTemplate(step.ForEach, data)
+	if err != nil {
+		return nil, fmt.Errorf("for_each: %w", err)
+	}
+	items, ok := rendered.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("for_each must evaluate to an array, got %T", rendered)
+	}
+	values := make([]interface{}, len(items))
+	for i, item := range items {
+		data["item"], data["index"] = item, i
+		value, err := r.callTool(ctx, step, data, fmt.Sprintf("%s/%s/%d", r.taskID, step.ID, i), result)
+		if err != nil {
+			return values, fmt.Errorf("item %d: %w", i, err)
+		}
+		values[i] = value
+	}
+	result.Status = stepSucceeded
+	return values, nil
+}
+
+// callTool renders the step's arguments and executes the tool through the regular dispatch
+// path, retrying failed attempts as configured.
+func (r *workflowRun) callTool(ctx context.Context, step *workflowStep, data map[string]interface{}, taskID string, result *pb.WorkflowStepResult) (interface{}, error) {
+	rendered, err := renderValue(step.Arguments, data)
+	if err != nil {
+		return nil, fmt.Errorf("arguments: %w", err)
+	}
+	args, _ := rendered.(map[string]interface{})
+	argsStruct, err := structpb.NewStruct(args)
+	if err != nil {
+		return nil, fmt.Errorf("arguments: %w", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		result.Attempts++
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if step.TimeoutMs > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, time.Duration(step.TimeoutMs)*time.Millisecond)
+		}
+		resp, err := r.s.ExecuteTool(attemptCtx, &pb.ExecuteToolRequest{TaskId: taskID, ToolName: step.Tool, Arguments: argsStruct})
+		cancel()
+		if approvalID := r.s.gatedBy(resp); approvalID != "" {
+			// Retrying would only ask for approval again.
+			return nil, fmt.Errorf("tool '%s' needs an operator's approval (human input task '%s'); workflows cannot wait for it", step.Tool, approvalID)
+		}
+		if err == nil {
+			return resp.Result.AsMap(), nil
+		}
+		if attempt >= step.Retries || ctx.Err() != nil {
+			return nil, errors.New(status.Convert(err).Message())
+		}
+		logger.Warn("Workflow step attempt failed, retrying", "task_id", taskID, "step", step.ID, "attempt", attempt+1, "error", err)
+		select {
+		case <-time.After(time.Duration(step.RetryDelayMs) * time.Millisecond):
+		case <-ctx.Done():
+			return nil, errors.New(status.Convert(err).Message())
+		}
+	}
+}
+
+// templateData builds the data visible to a step's templates: the inputs and the state of the
+// steps it depends on, which are finished by the time it runs.
+func (r *workflowRun) templateData(deps []string) map[string]interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	steps := make(map[string]interface{}, len(deps))
+	for _, dep := range deps {
+		steps[dep] = r.values[dep]
+	}
+	return map[string]interface{}{"inputs": r.inputs, "steps": steps}
+}
+
+// renderOutputs renders the workflow's outputs section against the results of all steps.
+func (r *workflowRun) renderOutputs() (*structpb.Struct, error) {
+	if len(r.def.Outputs) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, 0, len(r.def.Steps))
+	for _, step := range r.def.Steps {
+		ids = append(ids, step.ID)
+	}
+	rendered, err := renderValue(r.def.Outputs, r.templateData(ids))
+	if err != nil {
+		return nil, fmt.Errorf("outputs: %w", err)
+	}
+	outputs, err := structpb.NewStruct(rendered.(map[string]interface{}))
+	if err != nil {
+		return nil, fmt.Errorf("outputs: %w", err)
+	}
+	return outputs, nil
+}
+
+// fail records the first fatal error and cancels the steps that have not finished.
+func (r *workflowRun) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = err
+		r.cancel()
+	}
+}
@@ -0,0 +1,255 @@
+// File: MCP-NG/server/cmd/server/workflow_test.go
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	pb "mcp-ng/server/pkg/mcp"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestParseWorkflowValidation(t *testing.T) {
+	tests := map[string]string{
+		"no steps":     `name: empty`,
+		"bad id":       `steps: [{id: "a-b", tool: echo}]`,
+		"duplicate id": `steps: [{id: a, tool: echo}, {id: a, tool: echo}]`,
+		"missing tool": `steps: [{id: a}]`,
+		"unknown dep":  `steps: [{id: a, tool: echo, depends_on: [b]}]`,
+		"bad on_error": `steps: [{id: a, tool: echo, on_error: ignore}]`,
+		"cycle": `
+steps:
+  - {id: a, tool: echo, arguments: {text: "{{ .steps.b.result }}"}}
+  - {id: b, tool: echo, depends_on: [a]}`,
+	}
+	for name, def := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseWorkflow([]byte(def)); err == nil {
+				t.Errorf("expected definition to be rejected")
+			}
+		})
+	}
+}
+
+func TestRenderTemplateKeepsTypes(t *testing.T) {
+	data := map[string]interface{}{
+		"inputs": map[string]interface{}{"topic": "go", "urls": []interface{}{"a", "b"}},
+	}
+	got, err := renderTemplate("{{ .inputs.urls }}", data)
+	if err != nil {
+		t.Fatalf("renderTemplate failed: %v", err)
+	}
+	if list, ok := got.([]interface{}); !ok || len(list) != 2 {
+		t.Errorf("expected a two-item array, got %#v", got)
+	}
+
+	got, err = renderTemplate("Report on {{ .inputs.topic }} ({{ len .inputs.urls }} sources)", data)
+	if err != nil {
+		t.Fatalf("renderTemplate failed: %v", err)
+	}
+	if got != "Report on go (2 sources)" {
+		t.Errorf("unexpected text: %q", got)
+	}
+
+	if _, err := renderTemplate("{{ .inputs.missing }}", data); err == nil {
+		t.Error("expected an error for a missing key")
+	}
+}
+
+const researchWorkflow = `
+name: research
+inputs:
+  topic: golang
+steps:
+  - id: search
+    tool: search
+    arguments:
+      query: "{{ .inputs.topic }}"
+  - id: fetch
+    tool: fetch
+    for_each: "{{ .steps.search.result.urls }}"
+    arguments:
+      url: "{{ .item }}"
+  - id: alert
+    tool: notify
+    if: "{{ eq (len .steps.search.result.urls) 0 }}"
+    arguments:
+      message: nothing found
+  - id: save
+    tool: write
+    depends_on: [alert]
+    arguments:
+      content: "{{ range .steps.fetch.result }}{{ .body }};{{ end }}"
+outputs:
+  pages: "{{ len .steps.fetch.result }}"
+  saved: "{{ .steps.save.result.written }}"
+`
+
+func TestRunWorkflow(t *testing.T) {
+	var mu sync.Mutex
+	var notified bool
+	s := newTestServerWithTools(map[string]*fakeToolClient{
+		"search": {run: func(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunResponse, error) {
+			if q := in.Arguments.Fields["query"].GetStringValue(); q != "mcp" {
+				t.Errorf("expected input override 'mcp', got %q", q)
+			}
+			v, _ := structpb.NewValue(map[string]interface{}{"urls": []interface{}{"u1", "u2"}})
+			return &pb.ToolRunResponse{Result: v}, nil
+		}},
+		"fetch": {run: func(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunResponse, error) {
+			v, _ := structpb.NewValue(map[string]interface{}{"body": "page-" + in.Arguments.Fields["url"].GetStringValue()})
+			return &pb.ToolRunResponse{Result: v}, nil
+		}},
+		"notify": {run: func(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunResponse, error) {
+			mu.Lock()
+			notified = true
+			mu.Unlock()
+			return &pb.ToolRunResponse{Result: structpb.NewStringValue("ok")}, nil
+		}},
+		"write": {run: func(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunResponse, error) {
+			if c := in.Arguments.Fields["content"].GetStringValue(); c != "page-u1;page-u2;" {
+				t.Errorf("unexpected content: %q", c)
+			}
+			v, _ := structpb.NewValue(map[string]interface{}{"written": true})
+			return &pb.ToolRunResponse{Result: v}, nil
+		}},
+	})
+
+	inputs := mustStruct(t, map[string]interface{}{"topic": "mcp"})
+	resp, err := s.RunWorkflow(context.Background(), &pb.RunWorkflowRequest{Definition: researchWorkflow, Inputs: inputs})
+	if err != nil {
+		t.Fatalf("RunWorkflow failed: %v", err)
+	}
+	if resp.Status != stepSucceeded {
+		t.Fatalf("expected workflow to succeed, got %s: %s", resp.Status, resp.Error)
+	}
+	if notified {
+		t.Error("expected the conditional step to be skipped")
+	}
+
+	want := map[string]string{"search": stepSucceeded, "fetch": stepSucceeded, "alert": stepSkipped, "save": stepSucceeded}
+	for _, step := range resp.Steps {
+		if step.Status != want[step.Id] {
+			t.Errorf("step %s: expected %s, got %s (%s)", step.Id, want[step.Id], step.Status, step.Error)
+		}
+	}
+	if n := len(resp.Steps[1].Result.GetListValue().GetValues()); n != 2 {
+		t.Errorf("expected two fetch results, got %d", n)
+	}
+	if resp.Outputs.Fields["pages"].GetNumberValue() != 2 || !resp.Outputs.Fields["saved"].GetBoolValue() {
+		t.Errorf("unexpected outputs: %v", resp.Outputs)
+	}
+}
+
+func TestRunWorkflowFailureHandling(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	s := newTestServerWithTools(map[string]*fakeToolClient{
+		"flaky": {run: func(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunResponse, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			attempts++
+			if attempts < 3 {
+				return &pb.ToolRunResponse{Error: "temporary"}, nil
+			}
+			return &pb.ToolRunResponse{Result: structpb.NewStringValue("ok")}, nil
+		}},
+		"broken": {run: func(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunResponse, error) {
+			return &pb.ToolRunResponse{Error: "boom"}, nil
+		}},
+		"echo": {run: func(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunResponse, error) {
+			return &pb.ToolRunResponse{Result: structpb.NewStringValue(in.Arguments.Fields["text"].GetStringValue())}, nil
+		}},
+	})
+
+	def := `
+steps:
+  - {id: retry, tool: flaky, retries: 2}
+  - {id: optional, tool: broken, on_error: continue}
+  - id: report
+    tool: echo
+    arguments: {text: "{{ .steps.optional.status }}"}
+  - {id: required, tool: broken, depends_on: [report]}
+  - {id: after, tool: echo, depends_on: [required]}
+`
+	resp, err := s.RunWorkflow(context.Background(), &pb.RunWorkflowRequest{Definition: def})
+	if err != nil {
+		t.Fatalf("RunWorkflow failed: %v", err)
+	}
+	if resp.Status != stepFailed || !strings.Contains(resp.Error, `step "required" failed`) {
+		t.Errorf("expected failure from the required step, got %s: %s", resp.Status, resp.Error)
+	}
+
+	steps := make(map[string]*pb.WorkflowStepResult)
+	for _, step := range resp.Steps {
+		steps[step.Id] = step
+	}
+	if steps["retry"].Status != stepSucceeded || steps["retry"].Attempts != 3 {
+		t.Errorf("expected retry to succeed on attempt 3, got %s after %d", steps["retry"].Status, steps["retry"].Attempts)
+	}
+	if steps["optional"].Status != stepFailed {
+		t.Errorf("expected optional step to fail, got %s", steps["optional"].Status)
+	}
+	if got := steps["report"].Result.GetStructValue().Fields["result"].GetStringValue(); got != stepFailed {
+		t.Errorf("expected dependent step to see the failed status, got %q", got)
+	}
+	if steps["after"].Status != stepCanceled {
+		t.Errorf("expected step after a fatal failure to be canceled, got %s", steps["after"].Status)
+	}
+}
+
+func TestRunWorkflowFailsGatedStep(t *testing.T) {
+	s := newTestServerWithTools(map[string]*fakeToolClient{
+		"file_writer": {run: func(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunResponse, error) {
+			return &pb.ToolRunResponse{Result: structpb.NewStringValue("ok")}, nil
+		}},
+	})
+	s.tools["file_writer"].config.RequiresApproval = &approvalConfig{enabled: true}
+	def := `
+steps:
+  - {id: write, tool: file_writer, retries: 2}
+  - {id: after, tool: file_writer, depends_on: [write]}
+`
+	resp, err := s.RunWorkflow(context.Background(), &pb.RunWorkflowRequest{Definition: def})
+	if err != nil {
+		t.Fatalf("RunWorkflow failed: %v", err)
+	}
+	if resp.Status != stepFailed || !strings.Contains(resp.Error, "approval") {
+		t.Errorf("expected the gated step to fail the workflow, got %s: %s", resp.Status, resp.Error)
+	}
+	if step := resp.Steps[0]; step.Status != stepFailed || step.Attempts != 1 {
+		t.Errorf("expected the gated step to fail without retrying, got %s after %d attempts", step.Status, step.Attempts)
+	}
+}
+
+func TestRunStoredWorkflow(t *testing.T) {
+	s := newTestServerWithTools(map[string]*fakeToolClient{
+		"echo": {run: func(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunResponse, error) {
+			return &pb.ToolRunResponse{Result: structpb.NewStringValue("hi")}, nil
+		}},
+	})
+	s.workflowsDir = t.TempDir()
+	def := `{"steps": [{"id": "greet", "tool": "echo"}]}`
+	if err := os.WriteFile(filepath.Join(s.workflowsDir, "greet.json"), []byte(def), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := s.RunWorkflow(context.Background(), &pb.RunWorkflowRequest{WorkflowName: "greet"})
+	if err != nil || resp.Status != stepSucceeded || resp.WorkflowName != "greet" {
+		t.Fatalf("expected stored workflow to succeed, got %v, %v", resp, err)
+	}
+
+	for name, want := range map[string]codes.Code{"missing": codes.NotFound, "../greet": codes.InvalidArgument} {
+		_, err := s.RunWorkflow(context.Background(), &pb.RunWorkflowRequest{WorkflowName: name})
+		if status.Code(err) != want {
+			t.Errorf("workflow %q: expected %s, got %v", name, want, err)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+// Package humanform describes the structured forms a human operator fills in to answer a
+// human-input task, and validates the answers. A form is carried from the tool that asks
+// the question, through the message broker to the operator's client, and to the
+// orchestrator, which checks every response against it before accepting it.
+//
+// Each form type implies a JSON Schema for its answer; an optional schema in the form
+// narrows it further:
+//
+//	approval       {"approved": true, "comment": "..."}
+//	single_choice  "<option value>"
+//	multi_choice   ["<option value>", ...]
+//	number         42
+//	text           "..."
+//	json           any value matching the form's schema
+package humanform
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Form types.
+const (
+	TypeApproval     = "approval"
+	TypeSingleChoice = "single_choice"
+	TypeMultiChoice  = "multi_choice"
+	TypeNumber       = "number"
+	TypeText         = "text"
+	TypeJSON         = "json"
+)
+
+// Form is a form definition as JSON-encoded in tool arguments, broker messages and the
+// orchestrator's task store.
+type Form struct {
+	Type  string `json:"type"`
+	Title string `json:"title,omitempty"`
+	// Options are the choices of single_choice and multi_choice forms.
+	Options []Option `json:"options,omitempty"`
+	// MinSelections and MaxSelections bound a multi_choice answer; 0 means no bound.
+	MinSelections int `json:"min_selections,omitempty"`
+	MaxSelections int `json:"max_selections,omitempty"`
+	// Min and Max bound a number answer; Integer rejects fractions.
+	Min     *float64 `json:"min,omitempty"`
+	Max     *float64 `json:"max,omitempty"`
+	Integer bool     `json:"integer,omitempty"`
+	// MaxLength bounds a text answer in characters; 0 means no bound. Multiline is a
+	// rendering hint.
+	MaxLength int  `json:"max_length,omitempty"`
+	Multiline bool `json:"multiline,omitempty"`
+	// Schema is a JSON Schema the answer must also match. It is required for json forms.
+	Schema map[string]interface{} `json:"schema,omitempty"`
+
+	schema *schema // Compiled by Parse or Validate
+}
+
+// Option is one choice of a choice form.
+type Option struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"` // Shown instead of the value, if set
+}
+
+// Parse decodes and validates a form from its JSON-decoded representation, such as a tool
+// argument or a protobuf Struct's AsMap.
+func Parse(raw map[string]interface{}) (*Form, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid form: %v", err)
+	}
+	f := &Form{}
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("invalid form: %v", err)
+	}
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Map returns the form's JSON representation, e.g. for a protobuf Struct.
+func (f *Form) Map() map[string]interface{} {
+	data, _ := json.Marshal(f)
+	var m map[string]interface{}
+	json.Unmarshal(data, &m)
+	return m
+}
+
+// Validate checks the form definition itself.
+func (f *Form) Validate() error {
+	switch f.Type {
+	case TypeSingleChoice, TypeMultiChoice:
+		if len(f.Options) == 0 {
+			return fmt.Errorf("invalid form: a %s form needs options", f.Type)
+		}
+		seen := make(map[string]bool, len(f.Options))
+		for _, o := range f.Options {
+			if o.Value == "" {
+				return fmt.Errorf("invalid form: option values cannot be empty")
+			}
+			if seen[o.Value] {
+				return fmt.Errorf("invalid form: duplicate option %q", o.Value)
+			}
+			seen[o.Value] = true
+		}
+		if f.MinSelections < 0 || f.MaxSelections < 0 || f.MaxSelections > 0 && f.MinSelections > f.MaxSelections {
+			return fmt.Errorf("invalid form: min_selections and max_selections must be a range")
+		}
+	case TypeNumber:
+		if f.Min != nil && f.Max != nil && *f.Min > *f.Max {
+			return fmt.Errorf("invalid form: min is greater than max")
+		}
+	case TypeText:
+		if f.MaxLength < 0 {
+			return fmt.Errorf("invalid form: max_length cannot be negative")
+		}
+	case TypeApproval:
+	case TypeJSON:
+		if f.Schema == nil {
+			return fmt.Errorf("invalid form: a json form needs a schema")
+		}
+	default:
+		return fmt.Errorf("invalid form: unknown type %q", f.Type)
+	}
+	s, err := compileSchema(f.Schema)
+	if err != nil {
+		return fmt.Errorf("invalid form schema: %v", err)
+	}
+	f.schema = s
+	return nil
+}
+
+// JSONSchema returns the schema implied by the form's type, for clients that render or
+// validate answers themselves. The form's own schema applies on top of it.
+func (f *Form) JSONSchema() map[string]interface{} {
+	switch f.Type {
+	case TypeApproval:
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"approved": map[string]interface{}{"type": "boolean"},
+				"comment":  map[string]interface{}{"type": "string"},
+			},
+			"required":             []interface{}{"approved"},
+			"additionalProperties": false,
+		}
+	case TypeSingleChoice:
+		return map[string]interface{}{"type": "string", "enum": f.optionValues()}
+	case TypeMultiChoice:
+		s := map[string]interface{}{
+			"type":        "array",
+			"items":       map[string]interface{}{"type": "string", "enum": f.optionValues()},
+			"uniqueItems": true,
+		}
+		if f.MinSelections > 0 {
+			s["minItems"] = f.MinSelections
+		}
+		if f.MaxSelections > 0 {
+			s["maxItems"] = f.MaxSelections
+		}
+		return s
+	case TypeNumber:
+		s := map[string]interface{}{"type": "number"}
+		if f.Integer {
+			s["type"] = "integer"
+		}
+		if f.Min != nil {
+			s["minimum"] = *f.Min
+		}
+		if f.Max != nil {
+			s["maximum"] = *f.Max
+		}
+		return s
+	case TypeText:
+		s := map[string]interface{}{"type": "string"}
+		if f.MaxLength > 0 {
+			s["maxLength"] = f.MaxLength
+		}
+		return s
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+func (f *Form) optionValues() []interface{} {
+	values := make([]interface{}, len(f.Options))
+	for i, o := range f.Options {
+		values[i] = o.Value
+	}
+	return values
+}
+
+// Check validates an answer, as a JSON-decoded value, against the form.
+func (f *Form) Check(answer interface{}) error {
+	if f.schema == nil {
+		if err := f.Validate(); err != nil {
+			return err
+		}
+	}
+	implied, err := compileSchema(f.JSONSchema())
+	if err != nil {
+		return err
+	}
+	if err := implied.validate(answer, ""); err != nil {
+		return err
+	}
+	return f.schema.validate(answer, "")
+}
@@ -0,0 +1,115 @@
+package humanform
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func decode(t *testing.T, s string) interface{} {
+	t.Helper()
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatalf("bad test JSON %s: %v", s, err)
+	}
+	return v
+}
+
+func parse(t *testing.T, s string) *Form {
+	t.Helper()
+	f, err := Parse(decode(t, s).(map[string]interface{}))
+	if err != nil {
+		t.Fatalf("Parse(%s) failed: %v", s, err)
+	}
+	return f
+}
+
+func TestParseRejectsInvalidForms(t *testing.T) {
+	tests := map[string]string{
+		"unknown type":       `{"type": "slider"}`,
+		"choice without":     `{"type": "single_choice"}`,
+		"duplicate option":   `{"type": "multi_choice", "options": [{"value": "a"}, {"value": "a"}]}`,
+		"empty option":       `{"type": "single_choice", "options": [{"label": "A"}]}`,
+		"selection range":    `{"type": "multi_choice", "options": [{"value": "a"}], "min_selections": 2, "max_selections": 1}`,
+		"number range":       `{"type": "number", "min": 5, "max": 1}`,
+		"json without":       `{"type": "json"}`,
+		"bad schema type":    `{"type": "json", "schema": {"type": "thing"}}`,
+		"bad schema pattern": `{"type": "text", "schema": {"pattern": "("}}`,
+		"wrong field type":   `{"type": "number", "min": "low"}`,
+	}
+	for name, form := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Parse(decode(t, form).(map[string]interface{})); err == nil {
+				t.Errorf("expected %s to be rejected", form)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		form, answer string
+		wantErr      string
+	}{
+		{`{"type": "approval"}`, `{"approved": true}`, ""},
+		{`{"type": "approval"}`, `{"approved": false, "comment": "Too risky"}`, ""},
+		{`{"type": "approval"}`, `true`, "must be of type object"},
+		{`{"type": "approval"}`, `{"comment": "?"}`, `missing "approved"`},
+		{`{"type": "approval"}`, `{"approved": true, "extra": 1}`, `unexpected property "extra"`},
+
+		{`{"type": "single_choice", "options": [{"value": "a"}, {"value": "b"}]}`, `"b"`, ""},
+		{`{"type": "single_choice", "options": [{"value": "a"}, {"value": "b"}]}`, `"c"`, "must be one of"},
+
+		{`{"type": "multi_choice", "options": [{"value": "a"}, {"value": "b"}, {"value": "c"}], "max_selections": 2}`, `["a", "c"]`, ""},
+		{`{"type": "multi_choice", "options": [{"value": "a"}, {"value": "b"}, {"value": "c"}], "max_selections": 2}`, `["a", "b", "c"]`, "at most 2 items"},
+		{`{"type": "multi_choice", "options": [{"value": "a"}, {"value": "b"}], "min_selections": 1}`, `[]`, "at least 1 items"},
+		{`{"type": "multi_choice", "options": [{"value": "a"}, {"value": "b"}]}`, `["a", "a"]`, "duplicates"},
+		{`{"type": "multi_choice", "options": [{"value": "a"}, {"value": "b"}]}`, `["x"]`, "answer[0] must be one of"},
+
+		{`{"type": "number", "min": 1, "max": 10}`, `2.5`, ""},
+		{`{"type": "number", "min": 1, "max": 10}`, `11`, "at most 10"},
+		{`{"type": "number", "min": 1, "max": 10, "integer": true}`, `2.5`, "of type integer"},
+		{`{"type": "number"}`, `"7"`, "of type number"},
+
+		{`{"type": "text", "max_length": 5}`, `"héllo"`, ""},
+		{`{"type": "text", "max_length": 5}`, `"too long"`, "at most 5 characters"},
+		{`{"type": "text", "schema": {"pattern": "^[A-Z]+-[0-9]+$"}}`, `"OPS-12"`, ""},
+		{`{"type": "text", "schema": {"pattern": "^[A-Z]+-[0-9]+$"}}`, `"ops"`, "must match"},
+
+		{`{"type": "json", "schema": {"type": "object", "required": ["region"], "properties": {"region": {"enum": ["eu", "us"]}, "replicas": {"type": "integer", "minimum": 1}}}}`, `{"region": "eu", "replicas": 3}`, ""},
+		{`{"type": "json", "schema": {"type": "object", "required": ["region"], "properties": {"region": {"enum": ["eu", "us"]}, "replicas": {"type": "integer", "minimum": 1}}}}`, `{"region": "eu", "replicas": 0}`, "answer.replicas must be at least 1"},
+		{`{"type": "json", "schema": {"oneOf": [{"type": "string"}, {"type": "number"}]}}`, `true`, "exactly one"},
+		{`{"type": "json", "schema": {"anyOf": [{"const": "yes"}, {"const": "no"}]}}`, `"no"`, ""},
+	}
+	for _, tt := range tests {
+		err := parse(t, tt.form).Check(decode(t, tt.answer))
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("form %s, answer %s: unexpected error %v", tt.form, tt.answer, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("form %s, answer %s: expected an error containing %q, got %v", tt.form, tt.answer, tt.wantErr, err)
+		}
+	}
+}
+
+func TestFormSurvivesJSON(t *testing.T) {
+	f := parse(t, `{"type": "number", "min": 0, "max": 3, "schema": {"multipleOf": 1, "not": {"const": 2}}}`)
+	data, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded Form
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	// A form decoded from storage is compiled on first use.
+	if err := decoded.Check(2.0); err == nil || !strings.Contains(err.Error(), "must not match") {
+		t.Errorf("expected the stored schema to apply, got %v", err)
+	}
+	if err := decoded.Check(0.0); err != nil {
+		t.Errorf("expected a min of 0 to survive the round trip, got %v", err)
+	}
+	if m := f.Map(); m["type"] != TypeNumber || m["max"] != 3.0 {
+		t.Errorf("unexpected map: %v", m)
+	}
+}
@@ -0,0 +1,368 @@
+package humanform
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// schema is a compiled JSON Schema. It supports the keywords forms need: type, enum, const,
+// the numeric, string and array bounds, pattern, items, uniqueItems, properties, required,
+// additionalProperties, allOf, anyOf, oneOf and not. Other keywords are ignored.
+type schema struct {
+	types            []string
+	enum             []interface{}
+	constant         interface{}
+	hasConst         bool
+	minimum, maximum *float64
+	exclMin, exclMax *float64
+	minLength        *int
+	maxLength        *int
+	pattern          *regexp.Regexp
+	items            *schema
+	minItems         *int
+	maxItems         *int
+	uniqueItems      bool
+	properties       map[string]*schema
+	required         []string
+	additional       *schema // nil allows any additional property
+	noAdditional     bool
+	allOf            []*schema
+	anyOf            []*schema
+	oneOf            []*schema
+	not              *schema
+}
+
+// compileSchema checks a JSON-decoded schema and compiles it. A nil schema accepts
+// everything.
+func compileSchema(raw map[string]interface{}) (*schema, error) {
+	s := &schema{}
+	var err error
+	for key, v := range raw {
+		switch key {
+		case "type":
+			switch t := v.(type) {
+			case string:
+				s.types = []string{t}
+			case []interface{}:
+				for _, e := range t {
+					name, ok := e.(string)
+					if !ok {
+						return nil, fmt.Errorf("type must be a string or a list of strings")
+					}
+					s.types = append(s.types, name)
+				}
+			default:
+				return nil, fmt.Errorf("type must be a string or a list of strings")
+			}
+			for _, t := range s.types {
+				switch t {
+				case "null", "boolean", "object", "array", "number", "integer", "string":
+				default:
+					return nil, fmt.Errorf("unknown type %q", t)
+				}
+			}
+		case "enum":
+			list, ok := v.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("enum must be a list")
+			}
+			s.enum = list
+		case "const":
+			s.constant, s.hasConst = v, true
+		case "minimum":
+			s.minimum, err = schemaNumber(key, v)
+		case "maximum":
+			s.maximum, err = schemaNumber(key, v)
+		case "exclusiveMinimum":
+			s.exclMin, err = schemaNumber(key, v)
+		case "exclusiveMaximum":
+			s.exclMax, err = schemaNumber(key, v)
+		case "minLength":
+			s.minLength, err = schemaCount(key, v)
+		case "maxLength":
+			s.maxLength, err = schemaCount(key, v)
+		case "minItems":
+			s.minItems, err = schemaCount(key, v)
+		case "maxItems":
+			s.maxItems, err = schemaCount(key, v)
+		case "pattern":
+			p, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("pattern must be a string")
+			}
+			if s.pattern, err = regexp.Compile(p); err != nil {
+				return nil, fmt.Errorf("invalid pattern: %v", err)
+			}
+		case "uniqueItems":
+			s.uniqueItems, _ = v.(bool)
+		case "items":
+			s.items, err = compileSubschema(key, v)
+		case "properties":
+			props, ok := v.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("properties must be an object")
+			}
+			s.properties = make(map[string]*schema, len(props))
+			for name, p := range props {
+				if s.properties[name], err = compileSubschema(key+"."+name, p); err != nil {
+					return nil, err
+				}
+			}
+		case "required":
+			list, ok := v.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("required must be a list of strings")
+			}
+			for _, e := range list {
+				name, ok := e.(string)
+				if !ok {
+					return nil, fmt.Errorf("required must be a list of strings")
+				}
+				s.required = append(s.required, name)
+			}
+		case "additionalProperties":
+			if b, ok := v.(bool); ok {
+				s.noAdditional = !b
+			} else {
+				s.additional, err = compileSubschema(key, v)
+			}
+		case "allOf", "anyOf", "oneOf":
+			list, ok := v.([]interface{})
+			if !ok || len(list) == 0 {
+				return nil, fmt.Errorf("%s must be a non-empty list of schemas", key)
+			}
+			var subs []*schema
+			for _, e := range list {
+				sub, err := compileSubschema(key, e)
+				if err != nil {
+					return nil, err
+				}
+				subs = append(subs, sub)
+			}
+			switch key {
+			case "allOf":
+				s.allOf = subs
+			case "anyOf":
+				s.anyOf = subs
+			default:
+				s.oneOf = subs
+			}
+		case "not":
+			s.not, err = compileSubschema(key, v)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func compileSubschema(key string, v interface{}) (*schema, error) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be a schema object", key)
+	}
+	return compileSchema(m)
+}
+
+func schemaNumber(key string, v interface{}) (*float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return &n, nil
+	case int:
+		f := float64(n)
+		return &f, nil
+	}
+	return nil, fmt.Errorf("%s must be a number", key)
+}
+
+func schemaCount(key string, v interface{}) (*int, error) {
+	f, err := schemaNumber(key, v)
+	if err != nil || *f < 0 || *f != math.Trunc(*f) {
+		return nil, fmt.Errorf("%s must be a non-negative integer", key)
+	}
+	n := int(*f)
+	return &n, nil
+}
+
+// validate checks a JSON-decoded value; path locates it in the answer for error messages.
+func (s *schema) validate(v interface{}, path string) error {
+	where := path
+	if where == "" {
+		where = "answer"
+	}
+	if len(s.types) > 0 && !matchesAnyType(v, s.types) {
+		return fmt.Errorf("%s must be of type %s", where, strings.Join(s.types, " or "))
+	}
+	if s.enum != nil {
+		found := false
+		for _, e := range s.enum {
+			if jsonEqual(v, e) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s must be one of %s", where, jsonString(s.enum))
+		}
+	}
+	if s.hasConst && !jsonEqual(v, s.constant) {
+		return fmt.Errorf("%s must be %s", where, jsonString(s.constant))
+	}
+
+	switch v := v.(type) {
+	case float64:
+		if s.minimum != nil && v < *s.minimum {
+			return fmt.Errorf("%s must be at least %v", where, *s.minimum)
+		}
+		if s.maximum != nil && v > *s.maximum {
+			return fmt.Errorf("%s must be at most %v", where, *s.maximum)
+		}
+		if s.exclMin != nil && v <= *s.exclMin {
+			return fmt.Errorf("%s must be greater than %v", where, *s.exclMin)
+		}
+		if s.exclMax != nil && v >= *s.exclMax {
+			return fmt.Errorf("%s must be less than %v", where, *s.exclMax)
+		}
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.minLength != nil && n < *s.minLength {
+			return fmt.Errorf("%s must be at least %d characters long", where, *s.minLength)
+		}
+		if s.maxLength != nil && n > *s.maxLength {
+			return fmt.Errorf("%s must be at most %d characters long", where, *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			return fmt.Errorf("%s must match %q", where, s.pattern.String())
+		}
+	case []interface{}:
+		if s.minItems != nil && len(v) < *s.minItems {
+			return fmt.Errorf("%s must have at least %d items", where, *s.minItems)
+		}
+		if s.maxItems != nil && len(v) > *s.maxItems {
+			return fmt.Errorf("%s must have at most %d items", where, *s.maxItems)
+		}
+		for i, e := range v {
+			if s.uniqueItems {
+				for _, prev := range v[:i] {
+					if jsonEqual(e, prev) {
+						return fmt.Errorf("%s must not contain duplicates", where)
+					}
+				}
+			}
+			if s.items != nil {
+				if err := s.items.validate(e, fmt.Sprintf("%s[%d]", where, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.required {
+			if _, ok := v[name]; !ok {
+				return fmt.Errorf("%s is missing %q", where, name)
+			}
+		}
+		// Sorted so the first error reported does not depend on map order.
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			sub, declared := s.properties[k]
+			switch {
+			case declared:
+			case s.noAdditional:
+				return fmt.Errorf("%s has unexpected property %q", where, k)
+			case s.additional != nil:
+				sub = s.additional
+			default:
+				continue
+			}
+			if err := sub.validate(v[k], where+"."+k); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, sub := range s.allOf {
+		if err := sub.validate(v, path); err != nil {
+			return err
+		}
+	}
+	if s.anyOf != nil && countMatches(s.anyOf, v, path) == 0 {
+		return fmt.Errorf("%s must match at least one of the allowed schemas", where)
+	}
+	if s.oneOf != nil && countMatches(s.oneOf, v, path) != 1 {
+		return fmt.Errorf("%s must match exactly one of the allowed schemas", where)
+	}
+	if s.not != nil && s.not.validate(v, path) == nil {
+		return fmt.Errorf("%s matches a schema it must not match", where)
+	}
+	return nil
+}
+
+func countMatches(schemas []*schema, v interface{}, path string) int {
+	n := 0
+	for _, sub := range schemas {
+		if sub.validate(v, path) == nil {
+			n++
+		}
+	}
+	return n
+}
+
+func matchesAnyType(v interface{}, types []string) bool {
+	for _, t := range types {
+		if matchesType(v, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesType reports whether a JSON-decoded value has the given JSON Schema type.
+func matchesType(v interface{}, t string) bool {
+	switch v := v.(type) {
+	case nil:
+		return t == "null"
+	case bool:
+		return t == "boolean"
+	case float64:
+		return t == "number" || t == "integer" && v == math.Trunc(v)
+	case string:
+		return t == "string"
+	case []interface{}:
+		return t == "array"
+	case map[string]interface{}:
+		return t == "object"
+	}
+	return false
+}
+
+// jsonEqual compares JSON-decoded values; numbers given as Go ints in schemas built in code
+// compare equal to the float64 of decoded answers.
+func jsonEqual(a, b interface{}) bool {
+	return reflect.DeepEqual(normalize(a), normalize(b))
+}
+
+func normalize(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out interface{}
+	json.Unmarshal(data, &out)
+	return out
+}
+
+func jsonString(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
@@ -24,7 +24,10 @@ const (
 )
 
 type ListToolsRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Output format: "" for native ToolDescriptions, or "mcp", "openai", "anthropic" or "gemini"
+	// to render the tools in that vendor's function-calling schema.
+	Format        string `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -59,9 +62,21 @@ func (*ListToolsRequest) Descriptor() ([]byte, []int) {
 	return file_mcp_proto_rawDescGZIP(), []int{0}
 }
 
+func (x *ListToolsRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
 type ListToolsResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Tools         []*ToolDescription     `protobuf:"bytes,1,rep,name=tools,proto3" json:"tools,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Tools []*ToolDescription     `protobuf:"bytes,1,rep,name=tools,proto3" json:"tools,omitempty"` // Set when no format was requested.
+	// Set when a format was requested. The list can be used as-is as the "tools" array of a
+	// request to that vendor's API.
+	FormattedTools []*structpb.Struct `protobuf:"bytes,2,rep,name=formatted_tools,json=formattedTools,proto3" json:"formatted_tools,omitempty"`
+	// Formatted name -> registry name, for tools whose names had to be sanitized for the format.
+	ToolNames     map[string]string `protobuf:"bytes,3,rep,name=tool_names,json=toolNames,proto3" json:"tool_names,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -103,6 +118,20 @@ func (x *ListToolsResponse) GetTools() []*ToolDescription {
 	return nil
 }
 
+func (x *ListToolsResponse) GetFormattedTools() []*structpb.Struct {
+	if x != nil {
+		return x.FormattedTools
+	}
+	return nil
+}
+
+func (x *ListToolsResponse) GetToolNames() map[string]string {
+	if x != nil {
+		return x.ToolNames
+	}
+	return nil
+}
+
 type GetDescriptionRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -140,10 +169,13 @@ func (*GetDescriptionRequest) Descriptor() ([]byte, []int) {
 }
 
 type ToolDescription struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
-	Parameters    *ToolParameters        `protobuf:"bytes,3,opt,name=parameters,proto3" json:"parameters,omitempty"`
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Name        string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Parameters  *ToolParameters        `protobuf:"bytes,3,opt,name=parameters,proto3" json:"parameters,omitempty"`
+	// Set by the orchestrator from the tool's manifest, if it has one.
+	Version       string           `protobuf:"bytes,4,opt,name=version,proto3" json:"version,omitempty"`
+	Annotations   *structpb.Struct `protobuf:"bytes,5,opt,name=annotations,proto3" json:"annotations,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -199,29 +231,40 @@ func (x *ToolDescription) GetParameters() *ToolParameters {
 	return nil
 }
 
-type ToolParameters struct {
-	state         protoimpl.MessageState    `protogen:"open.v1"`
-	Type          string                    `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // Typically "object"
-	Properties    map[string]*ToolParameter `protobuf:"bytes,2,rep,name=properties,proto3" json:"properties,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	Required      []string                  `protobuf:"bytes,3,rep,name=required,proto3" json:"required,omitempty"`
+func (x *ToolDescription) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *ToolDescription) GetAnnotations() *structpb.Struct {
+	if x != nil {
+		return x.Annotations
+	}
+	return nil
+}
+
+type GetToolStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ToolParameters) Reset() {
-	*x = ToolParameters{}
+func (x *GetToolStatusRequest) Reset() {
+	*x = GetToolStatusRequest{}
 	mi := &file_mcp_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ToolParameters) String() string {
+func (x *GetToolStatusRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ToolParameters) ProtoMessage() {}
+func (*GetToolStatusRequest) ProtoMessage() {}
 
-func (x *ToolParameters) ProtoReflect() protoreflect.Message {
+func (x *GetToolStatusRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_mcp_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -233,54 +276,34 @@ func (x *ToolParameters) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ToolParameters.ProtoReflect.Descriptor instead.
-func (*ToolParameters) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetToolStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetToolStatusRequest) Descriptor() ([]byte, []int) {
 	return file_mcp_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *ToolParameters) GetType() string {
-	if x != nil {
-		return x.Type
-	}
-	return ""
-}
-
-func (x *ToolParameters) GetProperties() map[string]*ToolParameter {
-	if x != nil {
-		return x.Properties
-	}
-	return nil
-}
-
-func (x *ToolParameters) GetRequired() []string {
-	if x != nil {
-		return x.Required
-	}
-	return nil
-}
-
-type ToolParameter struct {
+type ToolReplicaStatus struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // JSON schema types: "string", "number", etc.
-	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Address       string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"` // "serving" or "not_serving"
+	Pid           int32                  `protobuf:"varint,3,opt,name=pid,proto3" json:"pid,omitempty"`      // Set for replicas the server launched.
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ToolParameter) Reset() {
-	*x = ToolParameter{}
+func (x *ToolReplicaStatus) Reset() {
+	*x = ToolReplicaStatus{}
 	mi := &file_mcp_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ToolParameter) String() string {
+func (x *ToolReplicaStatus) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ToolParameter) ProtoMessage() {}
+func (*ToolReplicaStatus) ProtoMessage() {}
 
-func (x *ToolParameter) ProtoReflect() protoreflect.Message {
+func (x *ToolReplicaStatus) ProtoReflect() protoreflect.Message {
 	mi := &file_mcp_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -292,48 +315,60 @@ func (x *ToolParameter) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ToolParameter.ProtoReflect.Descriptor instead.
-func (*ToolParameter) Descriptor() ([]byte, []int) {
+// Deprecated: Use ToolReplicaStatus.ProtoReflect.Descriptor instead.
+func (*ToolReplicaStatus) Descriptor() ([]byte, []int) {
 	return file_mcp_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *ToolParameter) GetType() string {
+func (x *ToolReplicaStatus) GetAddress() string {
 	if x != nil {
-		return x.Type
+		return x.Address
 	}
 	return ""
 }
 
-func (x *ToolParameter) GetDescription() string {
+func (x *ToolReplicaStatus) GetStatus() string {
 	if x != nil {
-		return x.Description
+		return x.Status
 	}
 	return ""
 }
 
-// Internal request from MCP to a specific Tool.
-type ToolRunRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Arguments     *structpb.Struct       `protobuf:"bytes,2,opt,name=arguments,proto3" json:"arguments,omitempty"`
+func (x *ToolReplicaStatus) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+type ToolStatus struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // The registered name, or the directory name for a tool that never started.
+	Dir   string                 `protobuf:"bytes,2,opt,name=dir,proto3" json:"dir,omitempty"`   // The tool's directory name.
+	// "serving", "not_serving", "stopped" (not started yet, or stopped for being idle) or
+	// "failed" (the last start failed).
+	Status        string               `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string               `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`       // Why the last start failed.
+	Warnings      []string             `protobuf:"bytes,5,rep,name=warnings,proto3" json:"warnings,omitempty"` // Sandbox settings that could not be applied, and similar.
+	Replicas      []*ToolReplicaStatus `protobuf:"bytes,6,rep,name=replicas,proto3" json:"replicas,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ToolRunRequest) Reset() {
-	*x = ToolRunRequest{}
+func (x *ToolStatus) Reset() {
+	*x = ToolStatus{}
 	mi := &file_mcp_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ToolRunRequest) String() string {
+func (x *ToolStatus) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ToolRunRequest) ProtoMessage() {}
+func (*ToolStatus) ProtoMessage() {}
 
-func (x *ToolRunRequest) ProtoReflect() protoreflect.Message {
+func (x *ToolStatus) ProtoReflect() protoreflect.Message {
 	mi := &file_mcp_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -345,48 +380,74 @@ func (x *ToolRunRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ToolRunRequest.ProtoReflect.Descriptor instead.
-func (*ToolRunRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ToolStatus.ProtoReflect.Descriptor instead.
+func (*ToolStatus) Descriptor() ([]byte, []int) {
 	return file_mcp_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *ToolRunRequest) GetName() string {
+func (x *ToolStatus) GetName() string {
 	if x != nil {
 		return x.Name
 	}
 	return ""
 }
 
-func (x *ToolRunRequest) GetArguments() *structpb.Struct {
+func (x *ToolStatus) GetDir() string {
 	if x != nil {
-		return x.Arguments
+		return x.Dir
+	}
+	return ""
+}
+
+func (x *ToolStatus) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ToolStatus) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ToolStatus) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
 	}
 	return nil
 }
 
-// Internal response from a specific Tool to MCP.
-type ToolRunResponse struct {
+func (x *ToolStatus) GetReplicas() []*ToolReplicaStatus {
+	if x != nil {
+		return x.Replicas
+	}
+	return nil
+}
+
+type GetToolStatusResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Result        *structpb.Value        `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
-	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Tools         []*ToolStatus          `protobuf:"bytes,1,rep,name=tools,proto3" json:"tools,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ToolRunResponse) Reset() {
-	*x = ToolRunResponse{}
+func (x *GetToolStatusResponse) Reset() {
+	*x = GetToolStatusResponse{}
 	mi := &file_mcp_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ToolRunResponse) String() string {
+func (x *GetToolStatusResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ToolRunResponse) ProtoMessage() {}
+func (*GetToolStatusResponse) ProtoMessage() {}
 
-func (x *ToolRunResponse) ProtoReflect() protoreflect.Message {
+func (x *GetToolStatusResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_mcp_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -398,49 +459,44 @@ func (x *ToolRunResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ToolRunResponse.ProtoReflect.Descriptor instead.
-func (*ToolRunResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetToolStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetToolStatusResponse) Descriptor() ([]byte, []int) {
 	return file_mcp_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *ToolRunResponse) GetResult() *structpb.Value {
+func (x *GetToolStatusResponse) GetTools() []*ToolStatus {
 	if x != nil {
-		return x.Result
+		return x.Tools
 	}
 	return nil
 }
 
-func (x *ToolRunResponse) GetError() string {
-	if x != nil {
-		return x.Error
-	}
-	return ""
-}
-
-// Request from an external client to the MCP to execute a tool.
-type ExecuteToolRequest struct {
+type StreamToolLogsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
-	ToolName      string                 `protobuf:"bytes,2,opt,name=tool_name,json=toolName,proto3" json:"tool_name,omitempty"`
-	Arguments     *structpb.Struct       `protobuf:"bytes,3,opt,name=arguments,proto3" json:"arguments,omitempty"`
+	ToolName      string                 `protobuf:"bytes,1,opt,name=tool_name,json=toolName,proto3" json:"tool_name,omitempty"`             // The registered name or the directory name of the tool.
+	MinLevel      string                 `protobuf:"bytes,2,opt,name=min_level,json=minLevel,proto3" json:"min_level,omitempty"`             // "debug", "info", "warn" or "error"; empty for every line.
+	SinceUnixMs   int64                  `protobuf:"varint,3,opt,name=since_unix_ms,json=sinceUnixMs,proto3" json:"since_unix_ms,omitempty"` // Only lines captured at or after this time, if set.
+	UntilUnixMs   int64                  `protobuf:"varint,4,opt,name=until_unix_ms,json=untilUnixMs,proto3" json:"until_unix_ms,omitempty"` // Only lines captured at or before this time, if set.
+	Tail          int32                  `protobuf:"varint,5,opt,name=tail,proto3" json:"tail,omitempty"`                                    // At most this many of the buffered lines, the latest; 0 for all.
+	Follow        bool                   `protobuf:"varint,6,opt,name=follow,proto3" json:"follow,omitempty"`                                // Keep streaming new lines until the client disconnects.
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ExecuteToolRequest) Reset() {
-	*x = ExecuteToolRequest{}
+func (x *StreamToolLogsRequest) Reset() {
+	*x = StreamToolLogsRequest{}
 	mi := &file_mcp_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ExecuteToolRequest) String() string {
+func (x *StreamToolLogsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ExecuteToolRequest) ProtoMessage() {}
+func (*StreamToolLogsRequest) ProtoMessage() {}
 
-func (x *ExecuteToolRequest) ProtoReflect() protoreflect.Message {
+func (x *StreamToolLogsRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_mcp_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -452,55 +508,79 @@ func (x *ExecuteToolRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ExecuteToolRequest.ProtoReflect.Descriptor instead.
-func (*ExecuteToolRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use StreamToolLogsRequest.ProtoReflect.Descriptor instead.
+func (*StreamToolLogsRequest) Descriptor() ([]byte, []int) {
 	return file_mcp_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *ExecuteToolRequest) GetTaskId() string {
+func (x *StreamToolLogsRequest) GetToolName() string {
 	if x != nil {
-		return x.TaskId
+		return x.ToolName
 	}
 	return ""
 }
 
-func (x *ExecuteToolRequest) GetToolName() string {
+func (x *StreamToolLogsRequest) GetMinLevel() string {
 	if x != nil {
-		return x.ToolName
+		return x.MinLevel
 	}
 	return ""
 }
 
-func (x *ExecuteToolRequest) GetArguments() *structpb.Struct {
+func (x *StreamToolLogsRequest) GetSinceUnixMs() int64 {
 	if x != nil {
-		return x.Arguments
+		return x.SinceUnixMs
 	}
-	return nil
+	return 0
 }
 
-// Response from the MCP to an external client after executing a tool.
-type ExecuteToolResponse struct {
+func (x *StreamToolLogsRequest) GetUntilUnixMs() int64 {
+	if x != nil {
+		return x.UntilUnixMs
+	}
+	return 0
+}
+
+func (x *StreamToolLogsRequest) GetTail() int32 {
+	if x != nil {
+		return x.Tail
+	}
+	return 0
+}
+
+func (x *StreamToolLogsRequest) GetFollow() bool {
+	if x != nil {
+		return x.Follow
+	}
+	return false
+}
+
+type ToolLogLine struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
-	Result        *structpb.Struct       `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
+	ToolName      string                 `protobuf:"bytes,1,opt,name=tool_name,json=toolName,proto3" json:"tool_name,omitempty"`
+	Pid           int32                  `protobuf:"varint,2,opt,name=pid,proto3" json:"pid,omitempty"`
+	Stream        string                 `protobuf:"bytes,3,opt,name=stream,proto3" json:"stream,omitempty"`                              // "stdout" or "stderr"
+	Level         string                 `protobuf:"bytes,4,opt,name=level,proto3" json:"level,omitempty"`                                // "debug", "info", "warn" or "error"
+	TimeUnixMs    int64                  `protobuf:"varint,5,opt,name=time_unix_ms,json=timeUnixMs,proto3" json:"time_unix_ms,omitempty"` // When the server captured the line.
+	Line          string                 `protobuf:"bytes,6,opt,name=line,proto3" json:"line,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ExecuteToolResponse) Reset() {
-	*x = ExecuteToolResponse{}
+func (x *ToolLogLine) Reset() {
+	*x = ToolLogLine{}
 	mi := &file_mcp_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ExecuteToolResponse) String() string {
+func (x *ToolLogLine) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ExecuteToolResponse) ProtoMessage() {}
+func (*ToolLogLine) ProtoMessage() {}
 
-func (x *ExecuteToolResponse) ProtoReflect() protoreflect.Message {
+func (x *ToolLogLine) ProtoReflect() protoreflect.Message {
 	mi := &file_mcp_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -512,47 +592,76 @@ func (x *ExecuteToolResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ExecuteToolResponse.ProtoReflect.Descriptor instead.
-func (*ExecuteToolResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use ToolLogLine.ProtoReflect.Descriptor instead.
+func (*ToolLogLine) Descriptor() ([]byte, []int) {
 	return file_mcp_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *ExecuteToolResponse) GetTaskId() string {
+func (x *ToolLogLine) GetToolName() string {
 	if x != nil {
-		return x.TaskId
+		return x.ToolName
 	}
 	return ""
 }
 
-func (x *ExecuteToolResponse) GetResult() *structpb.Struct {
+func (x *ToolLogLine) GetPid() int32 {
 	if x != nil {
-		return x.Result
+		return x.Pid
 	}
-	return nil
+	return 0
 }
 
-type ProvideHumanInputRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
-	Response      *structpb.Value        `protobuf:"bytes,2,opt,name=response,proto3" json:"response,omitempty"`
+func (x *ToolLogLine) GetStream() string {
+	if x != nil {
+		return x.Stream
+	}
+	return ""
+}
+
+func (x *ToolLogLine) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+func (x *ToolLogLine) GetTimeUnixMs() int64 {
+	if x != nil {
+		return x.TimeUnixMs
+	}
+	return 0
+}
+
+func (x *ToolLogLine) GetLine() string {
+	if x != nil {
+		return x.Line
+	}
+	return ""
+}
+
+type ToolParameters struct {
+	state         protoimpl.MessageState    `protogen:"open.v1"`
+	Type          string                    `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // Typically "object"
+	Properties    map[string]*ToolParameter `protobuf:"bytes,2,rep,name=properties,proto3" json:"properties,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Required      []string                  `protobuf:"bytes,3,rep,name=required,proto3" json:"required,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ProvideHumanInputRequest) Reset() {
-	*x = ProvideHumanInputRequest{}
+func (x *ToolParameters) Reset() {
+	*x = ToolParameters{}
 	mi := &file_mcp_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ProvideHumanInputRequest) String() string {
+func (x *ToolParameters) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ProvideHumanInputRequest) ProtoMessage() {}
+func (*ToolParameters) ProtoMessage() {}
 
-func (x *ProvideHumanInputRequest) ProtoReflect() protoreflect.Message {
+func (x *ToolParameters) ProtoReflect() protoreflect.Message {
 	mi := &file_mcp_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -564,47 +673,1622 @@ func (x *ProvideHumanInputRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ProvideHumanInputRequest.ProtoReflect.Descriptor instead.
-func (*ProvideHumanInputRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ToolParameters.ProtoReflect.Descriptor instead.
+func (*ToolParameters) Descriptor() ([]byte, []int) {
 	return file_mcp_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *ProvideHumanInputRequest) GetTaskId() string {
+func (x *ToolParameters) GetType() string {
 	if x != nil {
-		return x.TaskId
+		return x.Type
 	}
 	return ""
 }
 
-func (x *ProvideHumanInputRequest) GetResponse() *structpb.Value {
+func (x *ToolParameters) GetProperties() map[string]*ToolParameter {
 	if x != nil {
-		return x.Response
+		return x.Properties
 	}
 	return nil
 }
 
-type ProvideHumanInputResponse struct {
+func (x *ToolParameters) GetRequired() []string {
+	if x != nil {
+		return x.Required
+	}
+	return nil
+}
+
+type ToolParameter struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"` // e.g., "received"
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // JSON schema types: "string", "number", etc.
+	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ProvideHumanInputResponse) Reset() {
-	*x = ProvideHumanInputResponse{}
+func (x *ToolParameter) Reset() {
+	*x = ToolParameter{}
 	mi := &file_mcp_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ProvideHumanInputResponse) String() string {
+func (x *ToolParameter) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ProvideHumanInputResponse) ProtoMessage() {}
+func (*ToolParameter) ProtoMessage() {}
+
+func (x *ToolParameter) ProtoReflect() protoreflect.Message {
+	mi := &file_mcp_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolParameter.ProtoReflect.Descriptor instead.
+func (*ToolParameter) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ToolParameter) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ToolParameter) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+// Internal request from MCP to a specific Tool.
+type ToolRunRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Arguments     *structpb.Struct       `protobuf:"bytes,2,opt,name=arguments,proto3" json:"arguments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToolRunRequest) Reset() {
+	*x = ToolRunRequest{}
+	mi := &file_mcp_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToolRunRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolRunRequest) ProtoMessage() {}
+
+func (x *ToolRunRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mcp_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolRunRequest.ProtoReflect.Descriptor instead.
+func (*ToolRunRequest) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ToolRunRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ToolRunRequest) GetArguments() *structpb.Struct {
+	if x != nil {
+		return x.Arguments
+	}
+	return nil
+}
+
+// Internal response from a specific Tool to MCP.
+type ToolRunResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Result        *structpb.Value        `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToolRunResponse) Reset() {
+	*x = ToolRunResponse{}
+	mi := &file_mcp_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToolRunResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolRunResponse) ProtoMessage() {}
+
+func (x *ToolRunResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mcp_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolRunResponse.ProtoReflect.Descriptor instead.
+func (*ToolRunResponse) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ToolRunResponse) GetResult() *structpb.Value {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *ToolRunResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// Request from an external client to the MCP to execute a tool.
+type ExecuteToolRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	TaskId    string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	ToolName  string                 `protobuf:"bytes,2,opt,name=tool_name,json=toolName,proto3" json:"tool_name,omitempty"`
+	Arguments *structpb.Struct       `protobuf:"bytes,3,opt,name=arguments,proto3" json:"arguments,omitempty"`
+	// Cache policy for this call: "" uses the tool's configured cache, "bypass" skips the
+	// lookup and always runs the tool (the fresh result still refreshes the cache).
+	Cache         string `protobuf:"bytes,4,opt,name=cache,proto3" json:"cache,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecuteToolRequest) Reset() {
+	*x = ExecuteToolRequest{}
+	mi := &file_mcp_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteToolRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteToolRequest) ProtoMessage() {}
+
+func (x *ExecuteToolRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mcp_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteToolRequest.ProtoReflect.Descriptor instead.
+func (*ExecuteToolRequest) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ExecuteToolRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *ExecuteToolRequest) GetToolName() string {
+	if x != nil {
+		return x.ToolName
+	}
+	return ""
+}
+
+func (x *ExecuteToolRequest) GetArguments() *structpb.Struct {
+	if x != nil {
+		return x.Arguments
+	}
+	return nil
+}
+
+func (x *ExecuteToolRequest) GetCache() string {
+	if x != nil {
+		return x.Cache
+	}
+	return ""
+}
+
+// Response from the MCP to an external client after executing a tool.
+type ExecuteToolResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Result        *structpb.Struct       `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
+	Cached        bool                   `protobuf:"varint,3,opt,name=cached,proto3" json:"cached,omitempty"` // True if the result was served from the orchestrator's result cache.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecuteToolResponse) Reset() {
+	*x = ExecuteToolResponse{}
+	mi := &file_mcp_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteToolResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteToolResponse) ProtoMessage() {}
+
+func (x *ExecuteToolResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mcp_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteToolResponse.ProtoReflect.Descriptor instead.
+func (*ExecuteToolResponse) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ExecuteToolResponse) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *ExecuteToolResponse) GetResult() *structpb.Struct {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *ExecuteToolResponse) GetCached() bool {
+	if x != nil {
+		return x.Cached
+	}
+	return false
+}
+
+// Request to execute several tools in one round trip.
+type ExecuteToolsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Calls []*ExecuteToolRequest  `protobuf:"bytes,1,rep,name=calls,proto3" json:"calls,omitempty"`
+	// Deadline shared by all calls, in milliseconds. Zero means only the caller's own deadline applies.
+	TimeoutMs int64 `protobuf:"varint,2,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"`
+	// Maximum number of calls running at the same time. Zero runs every call in parallel.
+	MaxConcurrency int32 `protobuf:"varint,3,opt,name=max_concurrency,json=maxConcurrency,proto3" json:"max_concurrency,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ExecuteToolsRequest) Reset() {
+	*x = ExecuteToolsRequest{}
+	mi := &file_mcp_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteToolsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteToolsRequest) ProtoMessage() {}
+
+func (x *ExecuteToolsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mcp_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteToolsRequest.ProtoReflect.Descriptor instead.
+func (*ExecuteToolsRequest) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ExecuteToolsRequest) GetCalls() []*ExecuteToolRequest {
+	if x != nil {
+		return x.Calls
+	}
+	return nil
+}
+
+func (x *ExecuteToolsRequest) GetTimeoutMs() int64 {
+	if x != nil {
+		return x.TimeoutMs
+	}
+	return 0
+}
+
+func (x *ExecuteToolsRequest) GetMaxConcurrency() int32 {
+	if x != nil {
+		return x.MaxConcurrency
+	}
+	return 0
+}
+
+// Per-call outcome of a batch, in the same order as ExecuteToolsRequest.calls.
+type ExecuteToolResult struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	TaskId    string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	ToolName  string                 `protobuf:"bytes,2,opt,name=tool_name,json=toolName,proto3" json:"tool_name,omitempty"`
+	Result    *structpb.Struct       `protobuf:"bytes,3,opt,name=result,proto3" json:"result,omitempty"` // Set when the call succeeded.
+	Cached    bool                   `protobuf:"varint,4,opt,name=cached,proto3" json:"cached,omitempty"`
+	ErrorCode string                 `protobuf:"bytes,5,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"` // gRPC status code name when the call failed, e.g. "NotFound".
+	Error     string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`                          // Error message when the call failed.
+	// Set when the call is held back until an operator approves it; result and error are
+	// empty. Wait on this human-input task for the outcome.
+	ApprovalTaskId string `protobuf:"bytes,7,opt,name=approval_task_id,json=approvalTaskId,proto3" json:"approval_task_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ExecuteToolResult) Reset() {
+	*x = ExecuteToolResult{}
+	mi := &file_mcp_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteToolResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteToolResult) ProtoMessage() {}
+
+func (x *ExecuteToolResult) ProtoReflect() protoreflect.Message {
+	mi := &file_mcp_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteToolResult.ProtoReflect.Descriptor instead.
+func (*ExecuteToolResult) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ExecuteToolResult) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *ExecuteToolResult) GetToolName() string {
+	if x != nil {
+		return x.ToolName
+	}
+	return ""
+}
+
+func (x *ExecuteToolResult) GetResult() *structpb.Struct {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *ExecuteToolResult) GetCached() bool {
+	if x != nil {
+		return x.Cached
+	}
+	return false
+}
+
+func (x *ExecuteToolResult) GetErrorCode() string {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return ""
+}
+
+func (x *ExecuteToolResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ExecuteToolResult) GetApprovalTaskId() string {
+	if x != nil {
+		return x.ApprovalTaskId
+	}
+	return ""
+}
+
+type ExecuteToolsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*ExecuteToolResult   `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecuteToolsResponse) Reset() {
+	*x = ExecuteToolsResponse{}
+	mi := &file_mcp_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteToolsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteToolsResponse) ProtoMessage() {}
+
+func (x *ExecuteToolsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mcp_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteToolsResponse.ProtoReflect.Descriptor instead.
+func (*ExecuteToolsResponse) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ExecuteToolsResponse) GetResults() []*ExecuteToolResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+// Request to run a workflow. Exactly one of definition or workflow_name must be set.
+type RunWorkflowRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	TaskId string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	// Inline workflow definition in YAML or JSON.
+	Definition string `protobuf:"bytes,2,opt,name=definition,proto3" json:"definition,omitempty"`
+	// Name of a workflow stored in the server's workflows directory (without extension).
+	WorkflowName string `protobuf:"bytes,3,opt,name=workflow_name,json=workflowName,proto3" json:"workflow_name,omitempty"`
+	// Values available to step templates as {{ .inputs.<name> }}.
+	Inputs        *structpb.Struct `protobuf:"bytes,4,opt,name=inputs,proto3" json:"inputs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunWorkflowRequest) Reset() {
+	*x = RunWorkflowRequest{}
+	mi := &file_mcp_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunWorkflowRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunWorkflowRequest) ProtoMessage() {}
+
+func (x *RunWorkflowRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mcp_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunWorkflowRequest.ProtoReflect.Descriptor instead.
+func (*RunWorkflowRequest) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *RunWorkflowRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *RunWorkflowRequest) GetDefinition() string {
+	if x != nil {
+		return x.Definition
+	}
+	return ""
+}
+
+func (x *RunWorkflowRequest) GetWorkflowName() string {
+	if x != nil {
+		return x.WorkflowName
+	}
+	return ""
+}
+
+func (x *RunWorkflowRequest) GetInputs() *structpb.Struct {
+	if x != nil {
+		return x.Inputs
+	}
+	return nil
+}
+
+// Outcome of one workflow step.
+type WorkflowStepResult struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Id       string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ToolName string                 `protobuf:"bytes,2,opt,name=tool_name,json=toolName,proto3" json:"tool_name,omitempty"`
+	Status   string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"` // "succeeded", "failed", "skipped" or "canceled"
+	// The tool result, or a list of results for a step with for_each.
+	Result        *structpb.Value `protobuf:"bytes,4,opt,name=result,proto3" json:"result,omitempty"`
+	Error         string          `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	Attempts      int32           `protobuf:"varint,6,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	DurationMs    int64           `protobuf:"varint,7,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WorkflowStepResult) Reset() {
+	*x = WorkflowStepResult{}
+	mi := &file_mcp_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkflowStepResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkflowStepResult) ProtoMessage() {}
+
+func (x *WorkflowStepResult) ProtoReflect() protoreflect.Message {
+	mi := &file_mcp_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkflowStepResult.ProtoReflect.Descriptor instead.
+func (*WorkflowStepResult) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *WorkflowStepResult) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *WorkflowStepResult) GetToolName() string {
+	if x != nil {
+		return x.ToolName
+	}
+	return ""
+}
+
+func (x *WorkflowStepResult) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *WorkflowStepResult) GetResult() *structpb.Value {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *WorkflowStepResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *WorkflowStepResult) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *WorkflowStepResult) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+type RunWorkflowResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	WorkflowName  string                 `protobuf:"bytes,2,opt,name=workflow_name,json=workflowName,proto3" json:"workflow_name,omitempty"`
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`   // "succeeded" or "failed"
+	Steps         []*WorkflowStepResult  `protobuf:"bytes,4,rep,name=steps,proto3" json:"steps,omitempty"`     // In definition order.
+	Outputs       *structpb.Struct       `protobuf:"bytes,5,opt,name=outputs,proto3" json:"outputs,omitempty"` // The workflow's rendered outputs section.
+	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunWorkflowResponse) Reset() {
+	*x = RunWorkflowResponse{}
+	mi := &file_mcp_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunWorkflowResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunWorkflowResponse) ProtoMessage() {}
+
+func (x *RunWorkflowResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mcp_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunWorkflowResponse.ProtoReflect.Descriptor instead.
+func (*RunWorkflowResponse) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *RunWorkflowResponse) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *RunWorkflowResponse) GetWorkflowName() string {
+	if x != nil {
+		return x.WorkflowName
+	}
+	return ""
+}
+
+func (x *RunWorkflowResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *RunWorkflowResponse) GetSteps() []*WorkflowStepResult {
+	if x != nil {
+		return x.Steps
+	}
+	return nil
+}
+
+func (x *RunWorkflowResponse) GetOutputs() *structpb.Struct {
+	if x != nil {
+		return x.Outputs
+	}
+	return nil
+}
+
+func (x *RunWorkflowResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type RunAgentRequest struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	TaskId       string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	SystemPrompt string                 `protobuf:"bytes,2,opt,name=system_prompt,json=systemPrompt,proto3" json:"system_prompt,omitempty"`
+	UserMessage  string                 `protobuf:"bytes,3,opt,name=user_message,json=userMessage,proto3" json:"user_message,omitempty"`
+	// Tools the model may call. Empty allows every healthy tool.
+	AllowedTools []string `protobuf:"bytes,4,rep,name=allowed_tools,json=allowedTools,proto3" json:"allowed_tools,omitempty"`
+	// Overrides the model from the server's agent configuration.
+	Model string `protobuf:"bytes,5,opt,name=model,proto3" json:"model,omitempty"`
+	// Maximum number of model round trips. Zero uses the server default.
+	MaxSteps int32 `protobuf:"varint,6,opt,name=max_steps,json=maxSteps,proto3" json:"max_steps,omitempty"`
+	// Total token budget across all model calls. Zero uses the server default (unlimited if unset).
+	MaxTokens     int64 `protobuf:"varint,7,opt,name=max_tokens,json=maxTokens,proto3" json:"max_tokens,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunAgentRequest) Reset() {
+	*x = RunAgentRequest{}
+	mi := &file_mcp_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunAgentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunAgentRequest) ProtoMessage() {}
+
+func (x *RunAgentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mcp_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunAgentRequest.ProtoReflect.Descriptor instead.
+func (*RunAgentRequest) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *RunAgentRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *RunAgentRequest) GetSystemPrompt() string {
+	if x != nil {
+		return x.SystemPrompt
+	}
+	return ""
+}
+
+func (x *RunAgentRequest) GetUserMessage() string {
+	if x != nil {
+		return x.UserMessage
+	}
+	return ""
+}
+
+func (x *RunAgentRequest) GetAllowedTools() []string {
+	if x != nil {
+		return x.AllowedTools
+	}
+	return nil
+}
+
+func (x *RunAgentRequest) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *RunAgentRequest) GetMaxSteps() int32 {
+	if x != nil {
+		return x.MaxSteps
+	}
+	return 0
+}
+
+func (x *RunAgentRequest) GetMaxTokens() int64 {
+	if x != nil {
+		return x.MaxTokens
+	}
+	return 0
+}
+
+// A tool call requested by the model and its outcome.
+type AgentToolCall struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ToolName  string                 `protobuf:"bytes,2,opt,name=tool_name,json=toolName,proto3" json:"tool_name,omitempty"`
+	Arguments *structpb.Struct       `protobuf:"bytes,3,opt,name=arguments,proto3" json:"arguments,omitempty"`
+	Result    *structpb.Struct       `protobuf:"bytes,4,opt,name=result,proto3" json:"result,omitempty"` // Set when the call succeeded.
+	Error     string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`   // Set when the call failed or was not allowed.
+	// Set when the call is held back until an operator approves it; it has not run.
+	ApprovalTaskId string `protobuf:"bytes,6,opt,name=approval_task_id,json=approvalTaskId,proto3" json:"approval_task_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *AgentToolCall) Reset() {
+	*x = AgentToolCall{}
+	mi := &file_mcp_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentToolCall) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentToolCall) ProtoMessage() {}
+
+func (x *AgentToolCall) ProtoReflect() protoreflect.Message {
+	mi := &file_mcp_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentToolCall.ProtoReflect.Descriptor instead.
+func (*AgentToolCall) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *AgentToolCall) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AgentToolCall) GetToolName() string {
+	if x != nil {
+		return x.ToolName
+	}
+	return ""
+}
+
+func (x *AgentToolCall) GetArguments() *structpb.Struct {
+	if x != nil {
+		return x.Arguments
+	}
+	return nil
+}
+
+func (x *AgentToolCall) GetResult() *structpb.Struct {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *AgentToolCall) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *AgentToolCall) GetApprovalTaskId() string {
+	if x != nil {
+		return x.ApprovalTaskId
+	}
+	return ""
+}
+
+// One message of the agent transcript.
+type AgentMessage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Role          string                 `protobuf:"bytes,1,opt,name=role,proto3" json:"role,omitempty"` // "system", "user", "assistant" or "tool"
+	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	ToolCalls     []*AgentToolCall       `protobuf:"bytes,3,rep,name=tool_calls,json=toolCalls,proto3" json:"tool_calls,omitempty"`      // Assistant messages only.
+	ToolCallId    string                 `protobuf:"bytes,4,opt,name=tool_call_id,json=toolCallId,proto3" json:"tool_call_id,omitempty"` // Tool messages only.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AgentMessage) Reset() {
+	*x = AgentMessage{}
+	mi := &file_mcp_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentMessage) ProtoMessage() {}
+
+func (x *AgentMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_mcp_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentMessage.ProtoReflect.Descriptor instead.
+func (*AgentMessage) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *AgentMessage) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *AgentMessage) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *AgentMessage) GetToolCalls() []*AgentToolCall {
+	if x != nil {
+		return x.ToolCalls
+	}
+	return nil
+}
+
+func (x *AgentMessage) GetToolCallId() string {
+	if x != nil {
+		return x.ToolCallId
+	}
+	return ""
+}
+
+type AgentUsage struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	PromptTokens     int64                  `protobuf:"varint,1,opt,name=prompt_tokens,json=promptTokens,proto3" json:"prompt_tokens,omitempty"`
+	CompletionTokens int64                  `protobuf:"varint,2,opt,name=completion_tokens,json=completionTokens,proto3" json:"completion_tokens,omitempty"`
+	TotalTokens      int64                  `protobuf:"varint,3,opt,name=total_tokens,json=totalTokens,proto3" json:"total_tokens,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *AgentUsage) Reset() {
+	*x = AgentUsage{}
+	mi := &file_mcp_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AgentUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AgentUsage) ProtoMessage() {}
+
+func (x *AgentUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_mcp_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AgentUsage.ProtoReflect.Descriptor instead.
+func (*AgentUsage) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *AgentUsage) GetPromptTokens() int64 {
+	if x != nil {
+		return x.PromptTokens
+	}
+	return 0
+}
+
+func (x *AgentUsage) GetCompletionTokens() int64 {
+	if x != nil {
+		return x.CompletionTokens
+	}
+	return 0
+}
+
+func (x *AgentUsage) GetTotalTokens() int64 {
+	if x != nil {
+		return x.TotalTokens
+	}
+	return 0
+}
+
+type RunAgentResponse struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	TaskId string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	// "completed", "max_steps_exceeded", "token_budget_exceeded" or "failed"
+	Status        string          `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	FinalAnswer   string          `protobuf:"bytes,3,opt,name=final_answer,json=finalAnswer,proto3" json:"final_answer,omitempty"`
+	Transcript    []*AgentMessage `protobuf:"bytes,4,rep,name=transcript,proto3" json:"transcript,omitempty"`
+	Steps         int32           `protobuf:"varint,5,opt,name=steps,proto3" json:"steps,omitempty"`
+	Usage         *AgentUsage     `protobuf:"bytes,6,opt,name=usage,proto3" json:"usage,omitempty"`
+	Error         string          `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunAgentResponse) Reset() {
+	*x = RunAgentResponse{}
+	mi := &file_mcp_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunAgentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunAgentResponse) ProtoMessage() {}
+
+func (x *RunAgentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mcp_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunAgentResponse.ProtoReflect.Descriptor instead.
+func (*RunAgentResponse) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *RunAgentResponse) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *RunAgentResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *RunAgentResponse) GetFinalAnswer() string {
+	if x != nil {
+		return x.FinalAnswer
+	}
+	return ""
+}
+
+func (x *RunAgentResponse) GetTranscript() []*AgentMessage {
+	if x != nil {
+		return x.Transcript
+	}
+	return nil
+}
+
+func (x *RunAgentResponse) GetSteps() int32 {
+	if x != nil {
+		return x.Steps
+	}
+	return 0
+}
+
+func (x *RunAgentResponse) GetUsage() *AgentUsage {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+func (x *RunAgentResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ProvideHumanInputRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Response      *structpb.Value        `protobuf:"bytes,2,opt,name=response,proto3" json:"response,omitempty"`
+	Operator      string                 `protobuf:"bytes,3,opt,name=operator,proto3" json:"operator,omitempty"` // Who answered; recorded on the task.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProvideHumanInputRequest) Reset() {
+	*x = ProvideHumanInputRequest{}
+	mi := &file_mcp_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProvideHumanInputRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProvideHumanInputRequest) ProtoMessage() {}
+
+func (x *ProvideHumanInputRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mcp_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProvideHumanInputRequest.ProtoReflect.Descriptor instead.
+func (*ProvideHumanInputRequest) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ProvideHumanInputRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *ProvideHumanInputRequest) GetResponse() *structpb.Value {
+	if x != nil {
+		return x.Response
+	}
+	return nil
+}
+
+func (x *ProvideHumanInputRequest) GetOperator() string {
+	if x != nil {
+		return x.Operator
+	}
+	return ""
+}
+
+type ProvideHumanInputResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"` // e.g., "received"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProvideHumanInputResponse) Reset() {
+	*x = ProvideHumanInputResponse{}
+	mi := &file_mcp_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProvideHumanInputResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProvideHumanInputResponse) ProtoMessage() {}
 
 func (x *ProvideHumanInputResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_mcp_proto_msgTypes[11]
+	mi := &file_mcp_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProvideHumanInputResponse.ProtoReflect.Descriptor instead.
+func (*ProvideHumanInputResponse) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ProvideHumanInputResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type GetHumanInputRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHumanInputRequest) Reset() {
+	*x = GetHumanInputRequest{}
+	mi := &file_mcp_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHumanInputRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHumanInputRequest) ProtoMessage() {}
+
+func (x *GetHumanInputRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mcp_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHumanInputRequest.ProtoReflect.Descriptor instead.
+func (*GetHumanInputRequest) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *GetHumanInputRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+type GetHumanInputResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"` // "pending", "completed", "canceled" or "expired"
+	Response      *structpb.Value        `protobuf:"bytes,2,opt,name=response,proto3" json:"response,omitempty"`
+	Task          *HumanInputTask        `protobuf:"bytes,3,opt,name=task,proto3" json:"task,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHumanInputResponse) Reset() {
+	*x = GetHumanInputResponse{}
+	mi := &file_mcp_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHumanInputResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHumanInputResponse) ProtoMessage() {}
+
+func (x *GetHumanInputResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mcp_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHumanInputResponse.ProtoReflect.Descriptor instead.
+func (*GetHumanInputResponse) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *GetHumanInputResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *GetHumanInputResponse) GetResponse() *structpb.Value {
+	if x != nil {
+		return x.Response
+	}
+	return nil
+}
+
+func (x *GetHumanInputResponse) GetTask() *HumanInputTask {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+// A request for human input, as registered with the orchestrator.
+type HumanInputTask struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	TaskId            string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Status            string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"` // "pending", "completed", "canceled" or "expired"
+	Prompt            string                 `protobuf:"bytes,3,opt,name=prompt,proto3" json:"prompt,omitempty"`
+	Creator           string                 `protobuf:"bytes,4,opt,name=creator,proto3" json:"creator,omitempty"` // Who asked, e.g. the tool that registered the task.
+	CreatedAtUnixMs   int64                  `protobuf:"varint,5,opt,name=created_at_unix_ms,json=createdAtUnixMs,proto3" json:"created_at_unix_ms,omitempty"`
+	ExpiresAtUnixMs   int64                  `protobuf:"varint,6,opt,name=expires_at_unix_ms,json=expiresAtUnixMs,proto3" json:"expires_at_unix_ms,omitempty"`       // A pending task expires unanswered at this time.
+	CompletedAtUnixMs int64                  `protobuf:"varint,7,opt,name=completed_at_unix_ms,json=completedAtUnixMs,proto3" json:"completed_at_unix_ms,omitempty"` // When it was answered or canceled.
+	CancelReason      string                 `protobuf:"bytes,8,opt,name=cancel_reason,json=cancelReason,proto3" json:"cancel_reason,omitempty"`
+	Form              *structpb.Struct       `protobuf:"bytes,9,opt,name=form,proto3" json:"form,omitempty"`                                // The form the answer must fill in, if any.
+	AnsweredBy        string                 `protobuf:"bytes,10,opt,name=answered_by,json=answeredBy,proto3" json:"answered_by,omitempty"` // The operator who answered, if known.
+	Approval          *ToolApproval          `protobuf:"bytes,11,opt,name=approval,proto3" json:"approval,omitempty"`                       // Set for tasks that gate a tool call.
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *HumanInputTask) Reset() {
+	*x = HumanInputTask{}
+	mi := &file_mcp_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HumanInputTask) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HumanInputTask) ProtoMessage() {}
+
+func (x *HumanInputTask) ProtoReflect() protoreflect.Message {
+	mi := &file_mcp_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HumanInputTask.ProtoReflect.Descriptor instead.
+func (*HumanInputTask) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *HumanInputTask) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *HumanInputTask) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *HumanInputTask) GetPrompt() string {
+	if x != nil {
+		return x.Prompt
+	}
+	return ""
+}
+
+func (x *HumanInputTask) GetCreator() string {
+	if x != nil {
+		return x.Creator
+	}
+	return ""
+}
+
+func (x *HumanInputTask) GetCreatedAtUnixMs() int64 {
+	if x != nil {
+		return x.CreatedAtUnixMs
+	}
+	return 0
+}
+
+func (x *HumanInputTask) GetExpiresAtUnixMs() int64 {
+	if x != nil {
+		return x.ExpiresAtUnixMs
+	}
+	return 0
+}
+
+func (x *HumanInputTask) GetCompletedAtUnixMs() int64 {
+	if x != nil {
+		return x.CompletedAtUnixMs
+	}
+	return 0
+}
+
+func (x *HumanInputTask) GetCancelReason() string {
+	if x != nil {
+		return x.CancelReason
+	}
+	return ""
+}
+
+func (x *HumanInputTask) GetForm() *structpb.Struct {
+	if x != nil {
+		return x.Form
+	}
+	return nil
+}
+
+func (x *HumanInputTask) GetAnsweredBy() string {
+	if x != nil {
+		return x.AnsweredBy
+	}
+	return ""
+}
+
+func (x *HumanInputTask) GetApproval() *ToolApproval {
+	if x != nil {
+		return x.Approval
+	}
+	return nil
+}
+
+// A tool call held back until an operator approves it.
+type ToolApproval struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ToolName      string                 `protobuf:"bytes,1,opt,name=tool_name,json=toolName,proto3" json:"tool_name,omitempty"`
+	Arguments     *structpb.Struct       `protobuf:"bytes,2,opt,name=arguments,proto3" json:"arguments,omitempty"`
+	CallTaskId    string                 `protobuf:"bytes,3,opt,name=call_task_id,json=callTaskId,proto3" json:"call_task_id,omitempty"` // The task_id of the ExecuteTool call.
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`                             // "waiting", "rejected", "running", "succeeded" or "failed"
+	Result        *structpb.Struct       `protobuf:"bytes,5,opt,name=result,proto3" json:"result,omitempty"`                             // The tool's result, once it succeeded.
+	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`                               // Why the call failed, if it did.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ToolApproval) Reset() {
+	*x = ToolApproval{}
+	mi := &file_mcp_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ToolApproval) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ToolApproval) ProtoMessage() {}
+
+func (x *ToolApproval) ProtoReflect() protoreflect.Message {
+	mi := &file_mcp_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ToolApproval.ProtoReflect.Descriptor instead.
+func (*ToolApproval) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ToolApproval) GetToolName() string {
+	if x != nil {
+		return x.ToolName
+	}
+	return ""
+}
+
+func (x *ToolApproval) GetArguments() *structpb.Struct {
+	if x != nil {
+		return x.Arguments
+	}
+	return nil
+}
+
+func (x *ToolApproval) GetCallTaskId() string {
+	if x != nil {
+		return x.CallTaskId
+	}
+	return ""
+}
+
+func (x *ToolApproval) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ToolApproval) GetResult() *structpb.Struct {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *ToolApproval) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type RegisterHumanInputRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"` // Generated by the orchestrator if empty.
+	Prompt        string                 `protobuf:"bytes,2,opt,name=prompt,proto3" json:"prompt,omitempty"`
+	Creator       string                 `protobuf:"bytes,3,opt,name=creator,proto3" json:"creator,omitempty"`
+	TtlSeconds    int32                  `protobuf:"varint,4,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"` // How long the task waits for a response; the server default if 0.
+	Form          *structpb.Struct       `protobuf:"bytes,5,opt,name=form,proto3" json:"form,omitempty"`                                // A structured form the response is validated against.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterHumanInputRequest) Reset() {
+	*x = RegisterHumanInputRequest{}
+	mi := &file_mcp_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterHumanInputRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterHumanInputRequest) ProtoMessage() {}
+
+func (x *RegisterHumanInputRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mcp_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -615,40 +2299,157 @@ func (x *ProvideHumanInputResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ProvideHumanInputResponse.ProtoReflect.Descriptor instead.
-func (*ProvideHumanInputResponse) Descriptor() ([]byte, []int) {
-	return file_mcp_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use RegisterHumanInputRequest.ProtoReflect.Descriptor instead.
+func (*RegisterHumanInputRequest) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{33}
 }
 
-func (x *ProvideHumanInputResponse) GetStatus() string {
+func (x *RegisterHumanInputRequest) GetTaskId() string {
 	if x != nil {
-		return x.Status
+		return x.TaskId
 	}
 	return ""
 }
 
-type GetHumanInputRequest struct {
+func (x *RegisterHumanInputRequest) GetPrompt() string {
+	if x != nil {
+		return x.Prompt
+	}
+	return ""
+}
+
+func (x *RegisterHumanInputRequest) GetCreator() string {
+	if x != nil {
+		return x.Creator
+	}
+	return ""
+}
+
+func (x *RegisterHumanInputRequest) GetTtlSeconds() int32 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+func (x *RegisterHumanInputRequest) GetForm() *structpb.Struct {
+	if x != nil {
+		return x.Form
+	}
+	return nil
+}
+
+type ListPendingHumanInputsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Creator       string                 `protobuf:"bytes,1,opt,name=creator,proto3" json:"creator,omitempty"` // Only tasks from this creator, if set.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPendingHumanInputsRequest) Reset() {
+	*x = ListPendingHumanInputsRequest{}
+	mi := &file_mcp_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPendingHumanInputsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPendingHumanInputsRequest) ProtoMessage() {}
+
+func (x *ListPendingHumanInputsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mcp_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPendingHumanInputsRequest.ProtoReflect.Descriptor instead.
+func (*ListPendingHumanInputsRequest) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ListPendingHumanInputsRequest) GetCreator() string {
+	if x != nil {
+		return x.Creator
+	}
+	return ""
+}
+
+type ListPendingHumanInputsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tasks         []*HumanInputTask      `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPendingHumanInputsResponse) Reset() {
+	*x = ListPendingHumanInputsResponse{}
+	mi := &file_mcp_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPendingHumanInputsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPendingHumanInputsResponse) ProtoMessage() {}
+
+func (x *ListPendingHumanInputsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mcp_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPendingHumanInputsResponse.ProtoReflect.Descriptor instead.
+func (*ListPendingHumanInputsResponse) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ListPendingHumanInputsResponse) GetTasks() []*HumanInputTask {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
+type CancelHumanInputRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	TaskId        string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetHumanInputRequest) Reset() {
-	*x = GetHumanInputRequest{}
-	mi := &file_mcp_proto_msgTypes[12]
+func (x *CancelHumanInputRequest) Reset() {
+	*x = CancelHumanInputRequest{}
+	mi := &file_mcp_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetHumanInputRequest) String() string {
+func (x *CancelHumanInputRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetHumanInputRequest) ProtoMessage() {}
+func (*CancelHumanInputRequest) ProtoMessage() {}
 
-func (x *GetHumanInputRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_mcp_proto_msgTypes[12]
+func (x *CancelHumanInputRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mcp_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -659,41 +2460,161 @@ func (x *GetHumanInputRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetHumanInputRequest.ProtoReflect.Descriptor instead.
-func (*GetHumanInputRequest) Descriptor() ([]byte, []int) {
-	return file_mcp_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use CancelHumanInputRequest.ProtoReflect.Descriptor instead.
+func (*CancelHumanInputRequest) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{36}
 }
 
-func (x *GetHumanInputRequest) GetTaskId() string {
+func (x *CancelHumanInputRequest) GetTaskId() string {
 	if x != nil {
 		return x.TaskId
 	}
 	return ""
 }
 
-type GetHumanInputResponse struct {
+func (x *CancelHumanInputRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type WaitHumanInputRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TaskId         string                 `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	TimeoutSeconds int32                  `protobuf:"varint,2,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"` // How long to wait; 30 if 0, at most 300.
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *WaitHumanInputRequest) Reset() {
+	*x = WaitHumanInputRequest{}
+	mi := &file_mcp_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WaitHumanInputRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WaitHumanInputRequest) ProtoMessage() {}
+
+func (x *WaitHumanInputRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mcp_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WaitHumanInputRequest.ProtoReflect.Descriptor instead.
+func (*WaitHumanInputRequest) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *WaitHumanInputRequest) GetTaskId() string {
+	if x != nil {
+		return x.TaskId
+	}
+	return ""
+}
+
+func (x *WaitHumanInputRequest) GetTimeoutSeconds() int32 {
+	if x != nil {
+		return x.TimeoutSeconds
+	}
+	return 0
+}
+
+type WatchHumanInputsRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Creator        string                 `protobuf:"bytes,1,opt,name=creator,proto3" json:"creator,omitempty"`                                      // Only tasks from this creator, if set.
+	TaskIds        []string               `protobuf:"bytes,2,rep,name=task_ids,json=taskIds,proto3" json:"task_ids,omitempty"`                       // Only these tasks, if set.
+	IncludePending bool                   `protobuf:"varint,3,opt,name=include_pending,json=includePending,proto3" json:"include_pending,omitempty"` // Start with a "pending" event for every task still waiting.
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *WatchHumanInputsRequest) Reset() {
+	*x = WatchHumanInputsRequest{}
+	mi := &file_mcp_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchHumanInputsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchHumanInputsRequest) ProtoMessage() {}
+
+func (x *WatchHumanInputsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mcp_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchHumanInputsRequest.ProtoReflect.Descriptor instead.
+func (*WatchHumanInputsRequest) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *WatchHumanInputsRequest) GetCreator() string {
+	if x != nil {
+		return x.Creator
+	}
+	return ""
+}
+
+func (x *WatchHumanInputsRequest) GetTaskIds() []string {
+	if x != nil {
+		return x.TaskIds
+	}
+	return nil
+}
+
+func (x *WatchHumanInputsRequest) GetIncludePending() bool {
+	if x != nil {
+		return x.IncludePending
+	}
+	return false
+}
+
+type HumanInputEvent struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"` // e.g., "pending", "completed"
-	Response      *structpb.Value        `protobuf:"bytes,2,opt,name=response,proto3" json:"response,omitempty"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // "pending", "registered", "completed", "canceled" or "executed"
+	Task          *HumanInputTask        `protobuf:"bytes,2,opt,name=task,proto3" json:"task,omitempty"`
+	Response      *structpb.Value        `protobuf:"bytes,3,opt,name=response,proto3" json:"response,omitempty"` // The operator's answer, for "completed".
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetHumanInputResponse) Reset() {
-	*x = GetHumanInputResponse{}
-	mi := &file_mcp_proto_msgTypes[13]
+func (x *HumanInputEvent) Reset() {
+	*x = HumanInputEvent{}
+	mi := &file_mcp_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetHumanInputResponse) String() string {
+func (x *HumanInputEvent) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetHumanInputResponse) ProtoMessage() {}
+func (*HumanInputEvent) ProtoMessage() {}
 
-func (x *GetHumanInputResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_mcp_proto_msgTypes[13]
+func (x *HumanInputEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_mcp_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -704,19 +2625,26 @@ func (x *GetHumanInputResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetHumanInputResponse.ProtoReflect.Descriptor instead.
-func (*GetHumanInputResponse) Descriptor() ([]byte, []int) {
-	return file_mcp_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use HumanInputEvent.ProtoReflect.Descriptor instead.
+func (*HumanInputEvent) Descriptor() ([]byte, []int) {
+	return file_mcp_proto_rawDescGZIP(), []int{39}
 }
 
-func (x *GetHumanInputResponse) GetStatus() string {
+func (x *HumanInputEvent) GetType() string {
 	if x != nil {
-		return x.Status
+		return x.Type
 	}
 	return ""
 }
 
-func (x *GetHumanInputResponse) GetResponse() *structpb.Value {
+func (x *HumanInputEvent) GetTask() *HumanInputTask {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+func (x *HumanInputEvent) GetResponse() *structpb.Value {
 	if x != nil {
 		return x.Response
 	}
@@ -727,17 +2655,56 @@ var File_mcp_proto protoreflect.FileDescriptor
 
 const file_mcp_proto_rawDesc = "" +
 	"\n" +
-	"\tmcp.proto\x12\x03mcp\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1cgoogle/api/annotations.proto\"\x12\n" +
-	"\x10ListToolsRequest\"?\n" +
+	"\tmcp.proto\x12\x03mcp\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1cgoogle/api/annotations.proto\"*\n" +
+	"\x10ListToolsRequest\x12\x16\n" +
+	"\x06format\x18\x01 \x01(\tR\x06format\"\x85\x02\n" +
 	"\x11ListToolsResponse\x12*\n" +
-	"\x05tools\x18\x01 \x03(\v2\x14.mcp.ToolDescriptionR\x05tools\"\x17\n" +
-	"\x15GetDescriptionRequest\"|\n" +
+	"\x05tools\x18\x01 \x03(\v2\x14.mcp.ToolDescriptionR\x05tools\x12@\n" +
+	"\x0fformatted_tools\x18\x02 \x03(\v2\x17.google.protobuf.StructR\x0eformattedTools\x12D\n" +
+	"\n" +
+	"tool_names\x18\x03 \x03(\v2%.mcp.ListToolsResponse.ToolNamesEntryR\ttoolNames\x1a<\n" +
+	"\x0eToolNamesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x17\n" +
+	"\x15GetDescriptionRequest\"\xd1\x01\n" +
 	"\x0fToolDescription\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
 	"\vdescription\x18\x02 \x01(\tR\vdescription\x123\n" +
 	"\n" +
 	"parameters\x18\x03 \x01(\v2\x13.mcp.ToolParametersR\n" +
-	"parameters\"\xd8\x01\n" +
+	"parameters\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\tR\aversion\x129\n" +
+	"\vannotations\x18\x05 \x01(\v2\x17.google.protobuf.StructR\vannotations\"\x16\n" +
+	"\x14GetToolStatusRequest\"W\n" +
+	"\x11ToolReplicaStatus\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x10\n" +
+	"\x03pid\x18\x03 \x01(\x05R\x03pid\"\xb0\x01\n" +
+	"\n" +
+	"ToolStatus\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x10\n" +
+	"\x03dir\x18\x02 \x01(\tR\x03dir\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x12\x1a\n" +
+	"\bwarnings\x18\x05 \x03(\tR\bwarnings\x122\n" +
+	"\breplicas\x18\x06 \x03(\v2\x16.mcp.ToolReplicaStatusR\breplicas\">\n" +
+	"\x15GetToolStatusResponse\x12%\n" +
+	"\x05tools\x18\x01 \x03(\v2\x0f.mcp.ToolStatusR\x05tools\"\xc5\x01\n" +
+	"\x15StreamToolLogsRequest\x12\x1b\n" +
+	"\ttool_name\x18\x01 \x01(\tR\btoolName\x12\x1b\n" +
+	"\tmin_level\x18\x02 \x01(\tR\bminLevel\x12\"\n" +
+	"\rsince_unix_ms\x18\x03 \x01(\x03R\vsinceUnixMs\x12\"\n" +
+	"\runtil_unix_ms\x18\x04 \x01(\x03R\vuntilUnixMs\x12\x12\n" +
+	"\x04tail\x18\x05 \x01(\x05R\x04tail\x12\x16\n" +
+	"\x06follow\x18\x06 \x01(\bR\x06follow\"\xa0\x01\n" +
+	"\vToolLogLine\x12\x1b\n" +
+	"\ttool_name\x18\x01 \x01(\tR\btoolName\x12\x10\n" +
+	"\x03pid\x18\x02 \x01(\x05R\x03pid\x12\x16\n" +
+	"\x06stream\x18\x03 \x01(\tR\x06stream\x12\x14\n" +
+	"\x05level\x18\x04 \x01(\tR\x05level\x12 \n" +
+	"\ftime_unix_ms\x18\x05 \x01(\x03R\n" +
+	"timeUnixMs\x12\x12\n" +
+	"\x04line\x18\x06 \x01(\tR\x04line\"\xd8\x01\n" +
 	"\x0eToolParameters\x12\x12\n" +
 	"\x04type\x18\x01 \x01(\tR\x04type\x12C\n" +
 	"\n" +
@@ -755,29 +2722,167 @@ const file_mcp_proto_rawDesc = "" +
 	"\targuments\x18\x02 \x01(\v2\x17.google.protobuf.StructR\targuments\"W\n" +
 	"\x0fToolRunResponse\x12.\n" +
 	"\x06result\x18\x01 \x01(\v2\x16.google.protobuf.ValueR\x06result\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"\x81\x01\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"\x97\x01\n" +
 	"\x12ExecuteToolRequest\x12\x17\n" +
 	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x1b\n" +
 	"\ttool_name\x18\x02 \x01(\tR\btoolName\x125\n" +
-	"\targuments\x18\x03 \x01(\v2\x17.google.protobuf.StructR\targuments\"_\n" +
+	"\targuments\x18\x03 \x01(\v2\x17.google.protobuf.StructR\targuments\x12\x14\n" +
+	"\x05cache\x18\x04 \x01(\tR\x05cache\"w\n" +
 	"\x13ExecuteToolResponse\x12\x17\n" +
 	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12/\n" +
-	"\x06result\x18\x02 \x01(\v2\x17.google.protobuf.StructR\x06result\"g\n" +
+	"\x06result\x18\x02 \x01(\v2\x17.google.protobuf.StructR\x06result\x12\x16\n" +
+	"\x06cached\x18\x03 \x01(\bR\x06cached\"\x8c\x01\n" +
+	"\x13ExecuteToolsRequest\x12-\n" +
+	"\x05calls\x18\x01 \x03(\v2\x17.mcp.ExecuteToolRequestR\x05calls\x12\x1d\n" +
+	"\n" +
+	"timeout_ms\x18\x02 \x01(\x03R\ttimeoutMs\x12'\n" +
+	"\x0fmax_concurrency\x18\x03 \x01(\x05R\x0emaxConcurrency\"\xf1\x01\n" +
+	"\x11ExecuteToolResult\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x1b\n" +
+	"\ttool_name\x18\x02 \x01(\tR\btoolName\x12/\n" +
+	"\x06result\x18\x03 \x01(\v2\x17.google.protobuf.StructR\x06result\x12\x16\n" +
+	"\x06cached\x18\x04 \x01(\bR\x06cached\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\x05 \x01(\tR\terrorCode\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\x12(\n" +
+	"\x10approval_task_id\x18\a \x01(\tR\x0eapprovalTaskId\"H\n" +
+	"\x14ExecuteToolsResponse\x120\n" +
+	"\aresults\x18\x01 \x03(\v2\x16.mcp.ExecuteToolResultR\aresults\"\xa3\x01\n" +
+	"\x12RunWorkflowRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x1e\n" +
+	"\n" +
+	"definition\x18\x02 \x01(\tR\n" +
+	"definition\x12#\n" +
+	"\rworkflow_name\x18\x03 \x01(\tR\fworkflowName\x12/\n" +
+	"\x06inputs\x18\x04 \x01(\v2\x17.google.protobuf.StructR\x06inputs\"\xdc\x01\n" +
+	"\x12WorkflowStepResult\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\ttool_name\x18\x02 \x01(\tR\btoolName\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12.\n" +
+	"\x06result\x18\x04 \x01(\v2\x16.google.protobuf.ValueR\x06result\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x12\x1a\n" +
+	"\battempts\x18\x06 \x01(\x05R\battempts\x12\x1f\n" +
+	"\vduration_ms\x18\a \x01(\x03R\n" +
+	"durationMs\"\xe3\x01\n" +
+	"\x13RunWorkflowResponse\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12#\n" +
+	"\rworkflow_name\x18\x02 \x01(\tR\fworkflowName\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12-\n" +
+	"\x05steps\x18\x04 \x03(\v2\x17.mcp.WorkflowStepResultR\x05steps\x121\n" +
+	"\aoutputs\x18\x05 \x01(\v2\x17.google.protobuf.StructR\aoutputs\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\"\xe9\x01\n" +
+	"\x0fRunAgentRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12#\n" +
+	"\rsystem_prompt\x18\x02 \x01(\tR\fsystemPrompt\x12!\n" +
+	"\fuser_message\x18\x03 \x01(\tR\vuserMessage\x12#\n" +
+	"\rallowed_tools\x18\x04 \x03(\tR\fallowedTools\x12\x14\n" +
+	"\x05model\x18\x05 \x01(\tR\x05model\x12\x1b\n" +
+	"\tmax_steps\x18\x06 \x01(\x05R\bmaxSteps\x12\x1d\n" +
+	"\n" +
+	"max_tokens\x18\a \x01(\x03R\tmaxTokens\"\xe4\x01\n" +
+	"\rAgentToolCall\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\ttool_name\x18\x02 \x01(\tR\btoolName\x125\n" +
+	"\targuments\x18\x03 \x01(\v2\x17.google.protobuf.StructR\targuments\x12/\n" +
+	"\x06result\x18\x04 \x01(\v2\x17.google.protobuf.StructR\x06result\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x12(\n" +
+	"\x10approval_task_id\x18\x06 \x01(\tR\x0eapprovalTaskId\"\x91\x01\n" +
+	"\fAgentMessage\x12\x12\n" +
+	"\x04role\x18\x01 \x01(\tR\x04role\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\x121\n" +
+	"\n" +
+	"tool_calls\x18\x03 \x03(\v2\x12.mcp.AgentToolCallR\ttoolCalls\x12 \n" +
+	"\ftool_call_id\x18\x04 \x01(\tR\n" +
+	"toolCallId\"\x81\x01\n" +
+	"\n" +
+	"AgentUsage\x12#\n" +
+	"\rprompt_tokens\x18\x01 \x01(\x03R\fpromptTokens\x12+\n" +
+	"\x11completion_tokens\x18\x02 \x01(\x03R\x10completionTokens\x12!\n" +
+	"\ftotal_tokens\x18\x03 \x01(\x03R\vtotalTokens\"\xec\x01\n" +
+	"\x10RunAgentResponse\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12!\n" +
+	"\ffinal_answer\x18\x03 \x01(\tR\vfinalAnswer\x121\n" +
+	"\n" +
+	"transcript\x18\x04 \x03(\v2\x11.mcp.AgentMessageR\n" +
+	"transcript\x12\x14\n" +
+	"\x05steps\x18\x05 \x01(\x05R\x05steps\x12%\n" +
+	"\x05usage\x18\x06 \x01(\v2\x0f.mcp.AgentUsageR\x05usage\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\"\x83\x01\n" +
 	"\x18ProvideHumanInputRequest\x12\x17\n" +
 	"\atask_id\x18\x01 \x01(\tR\x06taskId\x122\n" +
-	"\bresponse\x18\x02 \x01(\v2\x16.google.protobuf.ValueR\bresponse\"3\n" +
+	"\bresponse\x18\x02 \x01(\v2\x16.google.protobuf.ValueR\bresponse\x12\x1a\n" +
+	"\boperator\x18\x03 \x01(\tR\boperator\"3\n" +
 	"\x19ProvideHumanInputResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\"/\n" +
 	"\x14GetHumanInputRequest\x12\x17\n" +
-	"\atask_id\x18\x01 \x01(\tR\x06taskId\"c\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\"\x8c\x01\n" +
 	"\x15GetHumanInputResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x122\n" +
-	"\bresponse\x18\x02 \x01(\v2\x16.google.protobuf.ValueR\bresponse2\x97\x03\n" +
+	"\bresponse\x18\x02 \x01(\v2\x16.google.protobuf.ValueR\bresponse\x12'\n" +
+	"\x04task\x18\x03 \x01(\v2\x13.mcp.HumanInputTaskR\x04task\"\xa0\x03\n" +
+	"\x0eHumanInputTask\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x16\n" +
+	"\x06prompt\x18\x03 \x01(\tR\x06prompt\x12\x18\n" +
+	"\acreator\x18\x04 \x01(\tR\acreator\x12+\n" +
+	"\x12created_at_unix_ms\x18\x05 \x01(\x03R\x0fcreatedAtUnixMs\x12+\n" +
+	"\x12expires_at_unix_ms\x18\x06 \x01(\x03R\x0fexpiresAtUnixMs\x12/\n" +
+	"\x14completed_at_unix_ms\x18\a \x01(\x03R\x11completedAtUnixMs\x12#\n" +
+	"\rcancel_reason\x18\b \x01(\tR\fcancelReason\x12+\n" +
+	"\x04form\x18\t \x01(\v2\x17.google.protobuf.StructR\x04form\x12\x1f\n" +
+	"\vanswered_by\x18\n" +
+	" \x01(\tR\n" +
+	"answeredBy\x12-\n" +
+	"\bapproval\x18\v \x01(\v2\x11.mcp.ToolApprovalR\bapproval\"\xe3\x01\n" +
+	"\fToolApproval\x12\x1b\n" +
+	"\ttool_name\x18\x01 \x01(\tR\btoolName\x125\n" +
+	"\targuments\x18\x02 \x01(\v2\x17.google.protobuf.StructR\targuments\x12 \n" +
+	"\fcall_task_id\x18\x03 \x01(\tR\n" +
+	"callTaskId\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12/\n" +
+	"\x06result\x18\x05 \x01(\v2\x17.google.protobuf.StructR\x06result\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\"\xb4\x01\n" +
+	"\x19RegisterHumanInputRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x16\n" +
+	"\x06prompt\x18\x02 \x01(\tR\x06prompt\x12\x18\n" +
+	"\acreator\x18\x03 \x01(\tR\acreator\x12\x1f\n" +
+	"\vttl_seconds\x18\x04 \x01(\x05R\n" +
+	"ttlSeconds\x12+\n" +
+	"\x04form\x18\x05 \x01(\v2\x17.google.protobuf.StructR\x04form\"9\n" +
+	"\x1dListPendingHumanInputsRequest\x12\x18\n" +
+	"\acreator\x18\x01 \x01(\tR\acreator\"K\n" +
+	"\x1eListPendingHumanInputsResponse\x12)\n" +
+	"\x05tasks\x18\x01 \x03(\v2\x13.mcp.HumanInputTaskR\x05tasks\"J\n" +
+	"\x17CancelHumanInputRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"Y\n" +
+	"\x15WaitHumanInputRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12'\n" +
+	"\x0ftimeout_seconds\x18\x02 \x01(\x05R\x0etimeoutSeconds\"w\n" +
+	"\x17WatchHumanInputsRequest\x12\x18\n" +
+	"\acreator\x18\x01 \x01(\tR\acreator\x12\x19\n" +
+	"\btask_ids\x18\x02 \x03(\tR\ataskIds\x12'\n" +
+	"\x0finclude_pending\x18\x03 \x01(\bR\x0eincludePending\"\x82\x01\n" +
+	"\x0fHumanInputEvent\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12'\n" +
+	"\x04task\x18\x02 \x01(\v2\x13.mcp.HumanInputTaskR\x04task\x122\n" +
+	"\bresponse\x18\x03 \x01(\v2\x16.google.protobuf.ValueR\bresponse2\xbe\v\n" +
 	"\x03MCP\x12M\n" +
-	"\tListTools\x12\x15.mcp.ListToolsRequest\x1a\x16.mcp.ListToolsResponse\"\x11\x82\xd3\xe4\x93\x02\v\x12\t/v1/tools\x12^\n" +
-	"\vExecuteTool\x12\x17.mcp.ExecuteToolRequest\x1a\x18.mcp.ExecuteToolResponse\"\x1c\x82\xd3\xe4\x93\x02\x16:\x01*\"\x11/v1/tools:execute\x12v\n" +
+	"\tListTools\x12\x15.mcp.ListToolsRequest\x1a\x16.mcp.ListToolsResponse\"\x11\x82\xd3\xe4\x93\x02\v\x12\t/v1/tools\x12`\n" +
+	"\rGetToolStatus\x12\x19.mcp.GetToolStatusRequest\x1a\x1a.mcp.GetToolStatusResponse\"\x18\x82\xd3\xe4\x93\x02\x12\x12\x10/v1/tools:status\x12d\n" +
+	"\x0eStreamToolLogs\x12\x1a.mcp.StreamToolLogsRequest\x1a\x10.mcp.ToolLogLine\"\"\x82\xd3\xe4\x93\x02\x1c\x12\x1a/v1/tools/{tool_name}/logs0\x01\x12^\n" +
+	"\vExecuteTool\x12\x17.mcp.ExecuteToolRequest\x1a\x18.mcp.ExecuteToolResponse\"\x1c\x82\xd3\xe4\x93\x02\x16:\x01*\"\x11/v1/tools:execute\x12f\n" +
+	"\fExecuteTools\x12\x18.mcp.ExecuteToolsRequest\x1a\x19.mcp.ExecuteToolsResponse\"!\x82\xd3\xe4\x93\x02\x1b:\x01*\"\x16/v1/tools:batchExecute\x12^\n" +
+	"\vRunWorkflow\x12\x17.mcp.RunWorkflowRequest\x1a\x18.mcp.RunWorkflowResponse\"\x1c\x82\xd3\xe4\x93\x02\x16:\x01*\"\x11/v1/workflows:run\x12Q\n" +
+	"\bRunAgent\x12\x14.mcp.RunAgentRequest\x1a\x15.mcp.RunAgentResponse\"\x18\x82\xd3\xe4\x93\x02\x12:\x01*\"\r/v1/agent:run\x12v\n" +
 	"\x11ProvideHumanInput\x12\x1d.mcp.ProvideHumanInputRequest\x1a\x1e.mcp.ProvideHumanInputResponse\"\"\x82\xd3\xe4\x93\x02\x1c:\x01*\"\x17/v1/human-input:provide\x12i\n" +
-	"\rGetHumanInput\x12\x19.mcp.GetHumanInputRequest\x1a\x1a.mcp.GetHumanInputResponse\"!\x82\xd3\xe4\x93\x02\x1b\x12\x19/v1/human-input/{task_id}2|\n" +
+	"\rGetHumanInput\x12\x19.mcp.GetHumanInputRequest\x1a\x1a.mcp.GetHumanInputResponse\"!\x82\xd3\xe4\x93\x02\x1b\x12\x19/v1/human-input/{task_id}\x12n\n" +
+	"\x12RegisterHumanInput\x12\x1e.mcp.RegisterHumanInputRequest\x1a\x13.mcp.HumanInputTask\"#\x82\xd3\xe4\x93\x02\x1d:\x01*\"\x18/v1/human-input:register\x12\x82\x01\n" +
+	"\x16ListPendingHumanInputs\x12\".mcp.ListPendingHumanInputsRequest\x1a#.mcp.ListPendingHumanInputsResponse\"\x1f\x82\xd3\xe4\x93\x02\x19\x12\x17/v1/human-input:pending\x12r\n" +
+	"\x10CancelHumanInput\x12\x1c.mcp.CancelHumanInputRequest\x1a\x13.mcp.HumanInputTask\"+\x82\xd3\xe4\x93\x02%:\x01*\" /v1/human-input/{task_id}:cancel\x12p\n" +
+	"\x0eWaitHumanInput\x12\x1a.mcp.WaitHumanInputRequest\x1a\x1a.mcp.GetHumanInputResponse\"&\x82\xd3\xe4\x93\x02 \x12\x1e/v1/human-input/{task_id}:wait\x12g\n" +
+	"\x10WatchHumanInputs\x12\x1c.mcp.WatchHumanInputsRequest\x1a\x14.mcp.HumanInputEvent\"\x1d\x82\xd3\xe4\x93\x02\x17\x12\x15/v1/human-input:watch0\x012|\n" +
 	"\x04Tool\x12B\n" +
 	"\x0eGetDescription\x12\x1a.mcp.GetDescriptionRequest\x1a\x14.mcp.ToolDescription\x120\n" +
 	"\x03Run\x12\x13.mcp.ToolRunRequest\x1a\x14.mcp.ToolRunResponseB\x17Z\x15mcp-ng/server/pkg/mcpb\x06proto3"
@@ -794,54 +2899,127 @@ func file_mcp_proto_rawDescGZIP() []byte {
 	return file_mcp_proto_rawDescData
 }
 
-var file_mcp_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_mcp_proto_msgTypes = make([]protoimpl.MessageInfo, 42)
 var file_mcp_proto_goTypes = []any{
-	(*ListToolsRequest)(nil),          // 0: mcp.ListToolsRequest
-	(*ListToolsResponse)(nil),         // 1: mcp.ListToolsResponse
-	(*GetDescriptionRequest)(nil),     // 2: mcp.GetDescriptionRequest
-	(*ToolDescription)(nil),           // 3: mcp.ToolDescription
-	(*ToolParameters)(nil),            // 4: mcp.ToolParameters
-	(*ToolParameter)(nil),             // 5: mcp.ToolParameter
-	(*ToolRunRequest)(nil),            // 6: mcp.ToolRunRequest
-	(*ToolRunResponse)(nil),           // 7: mcp.ToolRunResponse
-	(*ExecuteToolRequest)(nil),        // 8: mcp.ExecuteToolRequest
-	(*ExecuteToolResponse)(nil),       // 9: mcp.ExecuteToolResponse
-	(*ProvideHumanInputRequest)(nil),  // 10: mcp.ProvideHumanInputRequest
-	(*ProvideHumanInputResponse)(nil), // 11: mcp.ProvideHumanInputResponse
-	(*GetHumanInputRequest)(nil),      // 12: mcp.GetHumanInputRequest
-	(*GetHumanInputResponse)(nil),     // 13: mcp.GetHumanInputResponse
-	nil,                               // 14: mcp.ToolParameters.PropertiesEntry
-	(*structpb.Struct)(nil),           // 15: google.protobuf.Struct
-	(*structpb.Value)(nil),            // 16: google.protobuf.Value
+	(*ListToolsRequest)(nil),               // 0: mcp.ListToolsRequest
+	(*ListToolsResponse)(nil),              // 1: mcp.ListToolsResponse
+	(*GetDescriptionRequest)(nil),          // 2: mcp.GetDescriptionRequest
+	(*ToolDescription)(nil),                // 3: mcp.ToolDescription
+	(*GetToolStatusRequest)(nil),           // 4: mcp.GetToolStatusRequest
+	(*ToolReplicaStatus)(nil),              // 5: mcp.ToolReplicaStatus
+	(*ToolStatus)(nil),                     // 6: mcp.ToolStatus
+	(*GetToolStatusResponse)(nil),          // 7: mcp.GetToolStatusResponse
+	(*StreamToolLogsRequest)(nil),          // 8: mcp.StreamToolLogsRequest
+	(*ToolLogLine)(nil),                    // 9: mcp.ToolLogLine
+	(*ToolParameters)(nil),                 // 10: mcp.ToolParameters
+	(*ToolParameter)(nil),                  // 11: mcp.ToolParameter
+	(*ToolRunRequest)(nil),                 // 12: mcp.ToolRunRequest
+	(*ToolRunResponse)(nil),                // 13: mcp.ToolRunResponse
+	(*ExecuteToolRequest)(nil),             // 14: mcp.ExecuteToolRequest
+	(*ExecuteToolResponse)(nil),            // 15: mcp.ExecuteToolResponse
+	(*ExecuteToolsRequest)(nil),            // 16: mcp.ExecuteToolsRequest
+	(*ExecuteToolResult)(nil),              // 17: mcp.ExecuteToolResult
+	(*ExecuteToolsResponse)(nil),           // 18: mcp.ExecuteToolsResponse
+	(*RunWorkflowRequest)(nil),             // 19: mcp.RunWorkflowRequest
+	(*WorkflowStepResult)(nil),             // 20: mcp.WorkflowStepResult
+	(*RunWorkflowResponse)(nil),            // 21: mcp.RunWorkflowResponse
+	(*RunAgentRequest)(nil),                // 22: mcp.RunAgentRequest
+	(*AgentToolCall)(nil),                  // 23: mcp.AgentToolCall
+	(*AgentMessage)(nil),                   // 24: mcp.AgentMessage
+	(*AgentUsage)(nil),                     // 25: mcp.AgentUsage
+	(*RunAgentResponse)(nil),               // 26: mcp.RunAgentResponse
+	(*ProvideHumanInputRequest)(nil),       // 27: mcp.ProvideHumanInputRequest
+	(*ProvideHumanInputResponse)(nil),      // 28: mcp.ProvideHumanInputResponse
+	(*GetHumanInputRequest)(nil),           // 29: mcp.GetHumanInputRequest
+	(*GetHumanInputResponse)(nil),          // 30: mcp.GetHumanInputResponse
+	(*HumanInputTask)(nil),                 // 31: mcp.HumanInputTask
+	(*ToolApproval)(nil),                   // 32: mcp.ToolApproval
+	(*RegisterHumanInputRequest)(nil),      // 33: mcp.RegisterHumanInputRequest
+	(*ListPendingHumanInputsRequest)(nil),  // 34: mcp.ListPendingHumanInputsRequest
+	(*ListPendingHumanInputsResponse)(nil), // 35: mcp.ListPendingHumanInputsResponse
+	(*CancelHumanInputRequest)(nil),        // 36: mcp.CancelHumanInputRequest
+	(*WaitHumanInputRequest)(nil),          // 37: mcp.WaitHumanInputRequest
+	(*WatchHumanInputsRequest)(nil),        // 38: mcp.WatchHumanInputsRequest
+	(*HumanInputEvent)(nil),                // 39: mcp.HumanInputEvent
+	nil,                                    // 40: mcp.ListToolsResponse.ToolNamesEntry
+	nil,                                    // 41: mcp.ToolParameters.PropertiesEntry
+	(*structpb.Struct)(nil),                // 42: google.protobuf.Struct
+	(*structpb.Value)(nil),                 // 43: google.protobuf.Value
 }
 var file_mcp_proto_depIdxs = []int32{
 	3,  // 0: mcp.ListToolsResponse.tools:type_name -> mcp.ToolDescription
-	4,  // 1: mcp.ToolDescription.parameters:type_name -> mcp.ToolParameters
-	14, // 2: mcp.ToolParameters.properties:type_name -> mcp.ToolParameters.PropertiesEntry
-	15, // 3: mcp.ToolRunRequest.arguments:type_name -> google.protobuf.Struct
-	16, // 4: mcp.ToolRunResponse.result:type_name -> google.protobuf.Value
-	15, // 5: mcp.ExecuteToolRequest.arguments:type_name -> google.protobuf.Struct
-	15, // 6: mcp.ExecuteToolResponse.result:type_name -> google.protobuf.Struct
-	16, // 7: mcp.ProvideHumanInputRequest.response:type_name -> google.protobuf.Value
-	16, // 8: mcp.GetHumanInputResponse.response:type_name -> google.protobuf.Value
-	5,  // 9: mcp.ToolParameters.PropertiesEntry.value:type_name -> mcp.ToolParameter
-	0,  // 10: mcp.MCP.ListTools:input_type -> mcp.ListToolsRequest
-	8,  // 11: mcp.MCP.ExecuteTool:input_type -> mcp.ExecuteToolRequest
-	10, // 12: mcp.MCP.ProvideHumanInput:input_type -> mcp.ProvideHumanInputRequest
-	12, // 13: mcp.MCP.GetHumanInput:input_type -> mcp.GetHumanInputRequest
-	2,  // 14: mcp.Tool.GetDescription:input_type -> mcp.GetDescriptionRequest
-	6,  // 15: mcp.Tool.Run:input_type -> mcp.ToolRunRequest
-	1,  // 16: mcp.MCP.ListTools:output_type -> mcp.ListToolsResponse
-	9,  // 17: mcp.MCP.ExecuteTool:output_type -> mcp.ExecuteToolResponse
-	11, // 18: mcp.MCP.ProvideHumanInput:output_type -> mcp.ProvideHumanInputResponse
-	13, // 19: mcp.MCP.GetHumanInput:output_type -> mcp.GetHumanInputResponse
-	3,  // 20: mcp.Tool.GetDescription:output_type -> mcp.ToolDescription
-	7,  // 21: mcp.Tool.Run:output_type -> mcp.ToolRunResponse
-	16, // [16:22] is the sub-list for method output_type
-	10, // [10:16] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	42, // 1: mcp.ListToolsResponse.formatted_tools:type_name -> google.protobuf.Struct
+	40, // 2: mcp.ListToolsResponse.tool_names:type_name -> mcp.ListToolsResponse.ToolNamesEntry
+	10, // 3: mcp.ToolDescription.parameters:type_name -> mcp.ToolParameters
+	42, // 4: mcp.ToolDescription.annotations:type_name -> google.protobuf.Struct
+	5,  // 5: mcp.ToolStatus.replicas:type_name -> mcp.ToolReplicaStatus
+	6,  // 6: mcp.GetToolStatusResponse.tools:type_name -> mcp.ToolStatus
+	41, // 7: mcp.ToolParameters.properties:type_name -> mcp.ToolParameters.PropertiesEntry
+	42, // 8: mcp.ToolRunRequest.arguments:type_name -> google.protobuf.Struct
+	43, // 9: mcp.ToolRunResponse.result:type_name -> google.protobuf.Value
+	42, // 10: mcp.ExecuteToolRequest.arguments:type_name -> google.protobuf.Struct
+	42, // 11: mcp.ExecuteToolResponse.result:type_name -> google.protobuf.Struct
+	14, // 12: mcp.ExecuteToolsRequest.calls:type_name -> mcp.ExecuteToolRequest
+	42, // 13: mcp.ExecuteToolResult.result:type_name -> google.protobuf.Struct
+	17, // 14: mcp.ExecuteToolsResponse.results:type_name -> mcp.ExecuteToolResult
+	42, // 15: mcp.RunWorkflowRequest.inputs:type_name -> google.protobuf.Struct
+	43, // 16: mcp.WorkflowStepResult.result:type_name -> google.protobuf.Value
+	20, // 17: mcp.RunWorkflowResponse.steps:type_name -> mcp.WorkflowStepResult
+	42, // 18: mcp.RunWorkflowResponse.outputs:type_name -> google.protobuf.Struct
+	42, // 19: mcp.AgentToolCall.arguments:type_name -> google.protobuf.Struct
+	42, // 20: mcp.AgentToolCall.result:type_name -> google.protobuf.Struct
+	23, // 21: mcp.AgentMessage.tool_calls:type_name -> mcp.AgentToolCall
+	24, // 22: mcp.RunAgentResponse.transcript:type_name -> mcp.AgentMessage
+	25, // 23: mcp.RunAgentResponse.usage:type_name -> mcp.AgentUsage
+	43, // 24: mcp.ProvideHumanInputRequest.response:type_name -> google.protobuf.Value
+	43, // 25: mcp.GetHumanInputResponse.response:type_name -> google.protobuf.Value
+	31, // 26: mcp.GetHumanInputResponse.task:type_name -> mcp.HumanInputTask
+	42, // 27: mcp.HumanInputTask.form:type_name -> google.protobuf.Struct
+	32, // 28: mcp.HumanInputTask.approval:type_name -> mcp.ToolApproval
+	42, // 29: mcp.ToolApproval.arguments:type_name -> google.protobuf.Struct
+	42, // 30: mcp.ToolApproval.result:type_name -> google.protobuf.Struct
+	42, // 31: mcp.RegisterHumanInputRequest.form:type_name -> google.protobuf.Struct
+	31, // 32: mcp.ListPendingHumanInputsResponse.tasks:type_name -> mcp.HumanInputTask
+	31, // 33: mcp.HumanInputEvent.task:type_name -> mcp.HumanInputTask
+	43, // 34: mcp.HumanInputEvent.response:type_name -> google.protobuf.Value
+	11, // 35: mcp.ToolParameters.PropertiesEntry.value:type_name -> mcp.ToolParameter
+	0,  // 36: mcp.MCP.ListTools:input_type -> mcp.ListToolsRequest
+	4,  // 37: mcp.MCP.GetToolStatus:input_type -> mcp.GetToolStatusRequest
+	8,  // 38: mcp.MCP.StreamToolLogs:input_type -> mcp.StreamToolLogsRequest
+	14, // 39: mcp.MCP.ExecuteTool:input_type -> mcp.ExecuteToolRequest
+	16, // 40: mcp.MCP.ExecuteTools:input_type -> mcp.ExecuteToolsRequest
+	19, // 41: mcp.MCP.RunWorkflow:input_type -> mcp.RunWorkflowRequest
+	22, // 42: mcp.MCP.RunAgent:input_type -> mcp.RunAgentRequest
+	27, // 43: mcp.MCP.ProvideHumanInput:input_type -> mcp.ProvideHumanInputRequest
+	29, // 44: mcp.MCP.GetHumanInput:input_type -> mcp.GetHumanInputRequest
+	33, // 45: mcp.MCP.RegisterHumanInput:input_type -> mcp.RegisterHumanInputRequest
+	34, // 46: mcp.MCP.ListPendingHumanInputs:input_type -> mcp.ListPendingHumanInputsRequest
+	36, // 47: mcp.MCP.CancelHumanInput:input_type -> mcp.CancelHumanInputRequest
+	37, // 48: mcp.MCP.WaitHumanInput:input_type -> mcp.WaitHumanInputRequest
+	38, // 49: mcp.MCP.WatchHumanInputs:input_type -> mcp.WatchHumanInputsRequest
+	2,  // 50: mcp.Tool.GetDescription:input_type -> mcp.GetDescriptionRequest
+	12, // 51: mcp.Tool.Run:input_type -> mcp.ToolRunRequest
+	1,  // 52: mcp.MCP.ListTools:output_type -> mcp.ListToolsResponse
+	7,  // 53: mcp.MCP.GetToolStatus:output_type -> mcp.GetToolStatusResponse
+	9,  // 54: mcp.MCP.StreamToolLogs:output_type -> mcp.ToolLogLine
+	15, // 55: mcp.MCP.ExecuteTool:output_type -> mcp.ExecuteToolResponse
+	18, // 56: mcp.MCP.ExecuteTools:output_type -> mcp.ExecuteToolsResponse
+	21, // 57: mcp.MCP.RunWorkflow:output_type -> mcp.RunWorkflowResponse
+	26, // 58: mcp.MCP.RunAgent:output_type -> mcp.RunAgentResponse
+	28, // 59: mcp.MCP.ProvideHumanInput:output_type -> mcp.ProvideHumanInputResponse
+	30, // 60: mcp.MCP.GetHumanInput:output_type -> mcp.GetHumanInputResponse
+	31, // 61: mcp.MCP.RegisterHumanInput:output_type -> mcp.HumanInputTask
+	35, // 62: mcp.MCP.ListPendingHumanInputs:output_type -> mcp.ListPendingHumanInputsResponse
+	31, // 63: mcp.MCP.CancelHumanInput:output_type -> mcp.HumanInputTask
+	30, // 64: mcp.MCP.WaitHumanInput:output_type -> mcp.GetHumanInputResponse
+	39, // 65: mcp.MCP.WatchHumanInputs:output_type -> mcp.HumanInputEvent
+	3,  // 66: mcp.Tool.GetDescription:output_type -> mcp.ToolDescription
+	13, // 67: mcp.Tool.Run:output_type -> mcp.ToolRunResponse
+	52, // [52:68] is the sub-list for method output_type
+	36, // [36:52] is the sub-list for method input_type
+	36, // [36:36] is the sub-list for extension type_name
+	36, // [36:36] is the sub-list for extension extendee
+	0,  // [0:36] is the sub-list for field type_name
 }
 
 func init() { file_mcp_proto_init() }
@@ -855,7 +3033,7 @@ func file_mcp_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_mcp_proto_rawDesc), len(file_mcp_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   15,
+			NumMessages:   42,
 			NumExtensions: 0,
 			NumServices:   2,
 		},
@@ -19,10 +19,20 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	MCP_ListTools_FullMethodName         = "/mcp.MCP/ListTools"
-	MCP_ExecuteTool_FullMethodName       = "/mcp.MCP/ExecuteTool"
-	MCP_ProvideHumanInput_FullMethodName = "/mcp.MCP/ProvideHumanInput"
-	MCP_GetHumanInput_FullMethodName     = "/mcp.MCP/GetHumanInput"
+	MCP_ListTools_FullMethodName              = "/mcp.MCP/ListTools"
+	MCP_GetToolStatus_FullMethodName          = "/mcp.MCP/GetToolStatus"
+	MCP_StreamToolLogs_FullMethodName         = "/mcp.MCP/StreamToolLogs"
+	MCP_ExecuteTool_FullMethodName            = "/mcp.MCP/ExecuteTool"
+	MCP_ExecuteTools_FullMethodName           = "/mcp.MCP/ExecuteTools"
+	MCP_RunWorkflow_FullMethodName            = "/mcp.MCP/RunWorkflow"
+	MCP_RunAgent_FullMethodName               = "/mcp.MCP/RunAgent"
+	MCP_ProvideHumanInput_FullMethodName      = "/mcp.MCP/ProvideHumanInput"
+	MCP_GetHumanInput_FullMethodName          = "/mcp.MCP/GetHumanInput"
+	MCP_RegisterHumanInput_FullMethodName     = "/mcp.MCP/RegisterHumanInput"
+	MCP_ListPendingHumanInputs_FullMethodName = "/mcp.MCP/ListPendingHumanInputs"
+	MCP_CancelHumanInput_FullMethodName       = "/mcp.MCP/CancelHumanInput"
+	MCP_WaitHumanInput_FullMethodName         = "/mcp.MCP/WaitHumanInput"
+	MCP_WatchHumanInputs_FullMethodName       = "/mcp.MCP/WatchHumanInputs"
 )
 
 // MCPClient is the client API for MCP service.
@@ -33,12 +43,39 @@ const (
 type MCPClient interface {
 	// Returns a list of all available and healthy tools.
 	ListTools(ctx context.Context, in *ListToolsRequest, opts ...grpc.CallOption) (*ListToolsResponse, error)
+	// Reports every discovered tool, including tools that are not running or failed to start,
+	// with the state of each replica.
+	GetToolStatus(ctx context.Context, in *GetToolStatusRequest, opts ...grpc.CallOption) (*GetToolStatusResponse, error)
+	// Streams a tool's captured stdout and stderr: the buffered lines that pass the filters,
+	// then, with follow set, new lines as the tool writes them.
+	StreamToolLogs(ctx context.Context, in *StreamToolLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ToolLogLine], error)
 	// *** KEY CHANGE: Executes a tool as part of an async-ready task. ***
 	ExecuteTool(ctx context.Context, in *ExecuteToolRequest, opts ...grpc.CallOption) (*ExecuteToolResponse, error)
+	// Executes several tool calls concurrently under a shared deadline. Each call succeeds or
+	// fails on its own, so a batch can return a mix of results and errors.
+	ExecuteTools(ctx context.Context, in *ExecuteToolsRequest, opts ...grpc.CallOption) (*ExecuteToolsResponse, error)
+	// Runs a declarative workflow: a DAG of tool calls whose arguments may reference the
+	// outputs of earlier steps. The response reports the status and result of every step.
+	RunWorkflow(ctx context.Context, in *RunWorkflowRequest, opts ...grpc.CallOption) (*RunWorkflowResponse, error)
+	// Runs an agent loop: an OpenAI-compatible chat model is given the allowed tools and its
+	// tool calls are executed until it produces a final answer or a limit is reached.
+	RunAgent(ctx context.Context, in *RunAgentRequest, opts ...grpc.CallOption) (*RunAgentResponse, error)
 	// Allows a human operator to provide a response for a pending task.
 	ProvideHumanInput(ctx context.Context, in *ProvideHumanInputRequest, opts ...grpc.CallOption) (*ProvideHumanInputResponse, error)
 	// Polls for the status and result of a human input task.
 	GetHumanInput(ctx context.Context, in *GetHumanInputRequest, opts ...grpc.CallOption) (*GetHumanInputResponse, error)
+	// Registers a human input task before its prompt is shown to an operator. Responses are
+	// only accepted for registered tasks that have not expired or been canceled.
+	RegisterHumanInput(ctx context.Context, in *RegisterHumanInputRequest, opts ...grpc.CallOption) (*HumanInputTask, error)
+	// Lists the tasks still waiting for an operator, oldest first.
+	ListPendingHumanInputs(ctx context.Context, in *ListPendingHumanInputsRequest, opts ...grpc.CallOption) (*ListPendingHumanInputsResponse, error)
+	// Withdraws a pending task; later responses to it are rejected.
+	CancelHumanInput(ctx context.Context, in *CancelHumanInputRequest, opts ...grpc.CallOption) (*HumanInputTask, error)
+	// Waits until a task is answered, canceled or expires, or the timeout passes, and returns
+	// its state like GetHumanInput. Over REST this is a long-poll.
+	WaitHumanInput(ctx context.Context, in *WaitHumanInputRequest, opts ...grpc.CallOption) (*GetHumanInputResponse, error)
+	// Streams task changes as they happen: registrations, responses and cancellations.
+	WatchHumanInputs(ctx context.Context, in *WatchHumanInputsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[HumanInputEvent], error)
 }
 
 type mCPClient struct {
@@ -59,6 +96,35 @@ func (c *mCPClient) ListTools(ctx context.Context, in *ListToolsRequest, opts ..
 	return out, nil
 }
 
+func (c *mCPClient) GetToolStatus(ctx context.Context, in *GetToolStatusRequest, opts ...grpc.CallOption) (*GetToolStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetToolStatusResponse)
+	err := c.cc.Invoke(ctx, MCP_GetToolStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mCPClient) StreamToolLogs(ctx context.Context, in *StreamToolLogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ToolLogLine], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MCP_ServiceDesc.Streams[0], MCP_StreamToolLogs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamToolLogsRequest, ToolLogLine]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MCP_StreamToolLogsClient = grpc.ServerStreamingClient[ToolLogLine]
+
 func (c *mCPClient) ExecuteTool(ctx context.Context, in *ExecuteToolRequest, opts ...grpc.CallOption) (*ExecuteToolResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ExecuteToolResponse)
@@ -69,6 +135,36 @@ func (c *mCPClient) ExecuteTool(ctx context.Context, in *ExecuteToolRequest, opt
 	return out, nil
 }
 
+func (c *mCPClient) ExecuteTools(ctx context.Context, in *ExecuteToolsRequest, opts ...grpc.CallOption) (*ExecuteToolsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExecuteToolsResponse)
+	err := c.cc.Invoke(ctx, MCP_ExecuteTools_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mCPClient) RunWorkflow(ctx context.Context, in *RunWorkflowRequest, opts ...grpc.CallOption) (*RunWorkflowResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RunWorkflowResponse)
+	err := c.cc.Invoke(ctx, MCP_RunWorkflow_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mCPClient) RunAgent(ctx context.Context, in *RunAgentRequest, opts ...grpc.CallOption) (*RunAgentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RunAgentResponse)
+	err := c.cc.Invoke(ctx, MCP_RunAgent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *mCPClient) ProvideHumanInput(ctx context.Context, in *ProvideHumanInputRequest, opts ...grpc.CallOption) (*ProvideHumanInputResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ProvideHumanInputResponse)
@@ -89,6 +185,65 @@ func (c *mCPClient) GetHumanInput(ctx context.Context, in *GetHumanInputRequest,
 	return out, nil
 }
 
+func (c *mCPClient) RegisterHumanInput(ctx context.Context, in *RegisterHumanInputRequest, opts ...grpc.CallOption) (*HumanInputTask, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HumanInputTask)
+	err := c.cc.Invoke(ctx, MCP_RegisterHumanInput_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mCPClient) ListPendingHumanInputs(ctx context.Context, in *ListPendingHumanInputsRequest, opts ...grpc.CallOption) (*ListPendingHumanInputsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPendingHumanInputsResponse)
+	err := c.cc.Invoke(ctx, MCP_ListPendingHumanInputs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mCPClient) CancelHumanInput(ctx context.Context, in *CancelHumanInputRequest, opts ...grpc.CallOption) (*HumanInputTask, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HumanInputTask)
+	err := c.cc.Invoke(ctx, MCP_CancelHumanInput_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mCPClient) WaitHumanInput(ctx context.Context, in *WaitHumanInputRequest, opts ...grpc.CallOption) (*GetHumanInputResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetHumanInputResponse)
+	err := c.cc.Invoke(ctx, MCP_WaitHumanInput_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mCPClient) WatchHumanInputs(ctx context.Context, in *WatchHumanInputsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[HumanInputEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MCP_ServiceDesc.Streams[1], MCP_WatchHumanInputs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchHumanInputsRequest, HumanInputEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MCP_WatchHumanInputsClient = grpc.ServerStreamingClient[HumanInputEvent]
+
 // MCPServer is the server API for MCP service.
 // All implementations must embed UnimplementedMCPServer
 // for forward compatibility.
@@ -97,12 +252,39 @@ func (c *mCPClient) GetHumanInput(ctx context.Context, in *GetHumanInputRequest,
 type MCPServer interface {
 	// Returns a list of all available and healthy tools.
 	ListTools(context.Context, *ListToolsRequest) (*ListToolsResponse, error)
+	// Reports every discovered tool, including tools that are not running or failed to start,
+	// with the state of each replica.
+	GetToolStatus(context.Context, *GetToolStatusRequest) (*GetToolStatusResponse, error)
+	// Streams a tool's captured stdout and stderr: the buffered lines that pass the filters,
+	// then, with follow set, new lines as the tool writes them.
+	StreamToolLogs(*StreamToolLogsRequest, grpc.ServerStreamingServer[ToolLogLine]) error
 	// *** KEY CHANGE: Executes a tool as part of an async-ready task. ***
 	ExecuteTool(context.Context, *ExecuteToolRequest) (*ExecuteToolResponse, error)
+	// Executes several tool calls concurrently under a shared deadline. Each call succeeds or
+	// fails on its own, so a batch can return a mix of results and errors.
+	ExecuteTools(context.Context, *ExecuteToolsRequest) (*ExecuteToolsResponse, error)
+	// Runs a declarative workflow: a DAG of tool calls whose arguments may reference the
+	// outputs of earlier steps. The response reports the status and result of every step.
+	RunWorkflow(context.Context, *RunWorkflowRequest) (*RunWorkflowResponse, error)
+	// Runs an agent loop: an OpenAI-compatible chat model is given the allowed tools and its
+	// tool calls are executed until it produces a final answer or a limit is reached.
+	RunAgent(context.Context, *RunAgentRequest) (*RunAgentResponse, error)
 	// Allows a human operator to provide a response for a pending task.
 	ProvideHumanInput(context.Context, *ProvideHumanInputRequest) (*ProvideHumanInputResponse, error)
 	// Polls for the status and result of a human input task.
 	GetHumanInput(context.Context, *GetHumanInputRequest) (*GetHumanInputResponse, error)
+	// Registers a human input task before its prompt is shown to an operator. Responses are
+	// only accepted for registered tasks that have not expired or been canceled.
+	RegisterHumanInput(context.Context, *RegisterHumanInputRequest) (*HumanInputTask, error)
+	// Lists the tasks still waiting for an operator, oldest first.
+	ListPendingHumanInputs(context.Context, *ListPendingHumanInputsRequest) (*ListPendingHumanInputsResponse, error)
+	// Withdraws a pending task; later responses to it are rejected.
+	CancelHumanInput(context.Context, *CancelHumanInputRequest) (*HumanInputTask, error)
+	// Waits until a task is answered, canceled or expires, or the timeout passes, and returns
+	// its state like GetHumanInput. Over REST this is a long-poll.
+	WaitHumanInput(context.Context, *WaitHumanInputRequest) (*GetHumanInputResponse, error)
+	// Streams task changes as they happen: registrations, responses and cancellations.
+	WatchHumanInputs(*WatchHumanInputsRequest, grpc.ServerStreamingServer[HumanInputEvent]) error
 	mustEmbedUnimplementedMCPServer()
 }
 
@@ -116,15 +298,45 @@ type UnimplementedMCPServer struct{}
 func (UnimplementedMCPServer) ListTools(context.Context, *ListToolsRequest) (*ListToolsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListTools not implemented")
 }
+func (UnimplementedMCPServer) GetToolStatus(context.Context, *GetToolStatusRequest) (*GetToolStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetToolStatus not implemented")
+}
+func (UnimplementedMCPServer) StreamToolLogs(*StreamToolLogsRequest, grpc.ServerStreamingServer[ToolLogLine]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamToolLogs not implemented")
+}
 func (UnimplementedMCPServer) ExecuteTool(context.Context, *ExecuteToolRequest) (*ExecuteToolResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ExecuteTool not implemented")
 }
+func (UnimplementedMCPServer) ExecuteTools(context.Context, *ExecuteToolsRequest) (*ExecuteToolsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExecuteTools not implemented")
+}
+func (UnimplementedMCPServer) RunWorkflow(context.Context, *RunWorkflowRequest) (*RunWorkflowResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RunWorkflow not implemented")
+}
+func (UnimplementedMCPServer) RunAgent(context.Context, *RunAgentRequest) (*RunAgentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RunAgent not implemented")
+}
 func (UnimplementedMCPServer) ProvideHumanInput(context.Context, *ProvideHumanInputRequest) (*ProvideHumanInputResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ProvideHumanInput not implemented")
 }
 func (UnimplementedMCPServer) GetHumanInput(context.Context, *GetHumanInputRequest) (*GetHumanInputResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetHumanInput not implemented")
 }
+func (UnimplementedMCPServer) RegisterHumanInput(context.Context, *RegisterHumanInputRequest) (*HumanInputTask, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterHumanInput not implemented")
+}
+func (UnimplementedMCPServer) ListPendingHumanInputs(context.Context, *ListPendingHumanInputsRequest) (*ListPendingHumanInputsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPendingHumanInputs not implemented")
+}
+func (UnimplementedMCPServer) CancelHumanInput(context.Context, *CancelHumanInputRequest) (*HumanInputTask, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelHumanInput not implemented")
+}
+func (UnimplementedMCPServer) WaitHumanInput(context.Context, *WaitHumanInputRequest) (*GetHumanInputResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WaitHumanInput not implemented")
+}
+func (UnimplementedMCPServer) WatchHumanInputs(*WatchHumanInputsRequest, grpc.ServerStreamingServer[HumanInputEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchHumanInputs not implemented")
+}
 func (UnimplementedMCPServer) mustEmbedUnimplementedMCPServer() {}
 func (UnimplementedMCPServer) testEmbeddedByValue()             {}
 
@@ -164,6 +376,35 @@ func _MCP_ListTools_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MCP_GetToolStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetToolStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MCPServer).GetToolStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MCP_GetToolStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MCPServer).GetToolStatus(ctx, req.(*GetToolStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MCP_StreamToolLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamToolLogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MCPServer).StreamToolLogs(m, &grpc.GenericServerStream[StreamToolLogsRequest, ToolLogLine]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MCP_StreamToolLogsServer = grpc.ServerStreamingServer[ToolLogLine]
+
 func _MCP_ExecuteTool_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ExecuteToolRequest)
 	if err := dec(in); err != nil {
@@ -182,6 +423,60 @@ func _MCP_ExecuteTool_Handler(srv interface{}, ctx context.Context, dec func(int
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MCP_ExecuteTools_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExecuteToolsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MCPServer).ExecuteTools(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MCP_ExecuteTools_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MCPServer).ExecuteTools(ctx, req.(*ExecuteToolsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MCP_RunWorkflow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunWorkflowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MCPServer).RunWorkflow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MCP_RunWorkflow_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MCPServer).RunWorkflow(ctx, req.(*RunWorkflowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MCP_RunAgent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunAgentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MCPServer).RunAgent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MCP_RunAgent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MCPServer).RunAgent(ctx, req.(*RunAgentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _MCP_ProvideHumanInput_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ProvideHumanInputRequest)
 	if err := dec(in); err != nil {
@@ -218,6 +513,89 @@ func _MCP_GetHumanInput_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MCP_RegisterHumanInput_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterHumanInputRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MCPServer).RegisterHumanInput(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MCP_RegisterHumanInput_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MCPServer).RegisterHumanInput(ctx, req.(*RegisterHumanInputRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MCP_ListPendingHumanInputs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPendingHumanInputsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MCPServer).ListPendingHumanInputs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MCP_ListPendingHumanInputs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MCPServer).ListPendingHumanInputs(ctx, req.(*ListPendingHumanInputsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MCP_CancelHumanInput_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelHumanInputRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MCPServer).CancelHumanInput(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MCP_CancelHumanInput_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MCPServer).CancelHumanInput(ctx, req.(*CancelHumanInputRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MCP_WaitHumanInput_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WaitHumanInputRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MCPServer).WaitHumanInput(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MCP_WaitHumanInput_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MCPServer).WaitHumanInput(ctx, req.(*WaitHumanInputRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MCP_WatchHumanInputs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchHumanInputsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MCPServer).WatchHumanInputs(m, &grpc.GenericServerStream[WatchHumanInputsRequest, HumanInputEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MCP_WatchHumanInputsServer = grpc.ServerStreamingServer[HumanInputEvent]
+
 // MCP_ServiceDesc is the grpc.ServiceDesc for MCP service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -229,10 +607,26 @@ var MCP_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListTools",
 			Handler:    _MCP_ListTools_Handler,
 		},
+		{
+			MethodName: "GetToolStatus",
+			Handler:    _MCP_GetToolStatus_Handler,
+		},
 		{
 			MethodName: "ExecuteTool",
 			Handler:    _MCP_ExecuteTool_Handler,
 		},
+		{
+			MethodName: "ExecuteTools",
+			Handler:    _MCP_ExecuteTools_Handler,
+		},
+		{
+			MethodName: "RunWorkflow",
+			Handler:    _MCP_RunWorkflow_Handler,
+		},
+		{
+			MethodName: "RunAgent",
+			Handler:    _MCP_RunAgent_Handler,
+		},
 		{
 			MethodName: "ProvideHumanInput",
 			Handler:    _MCP_ProvideHumanInput_Handler,
@@ -241,8 +635,35 @@ var MCP_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetHumanInput",
 			Handler:    _MCP_GetHumanInput_Handler,
 		},
+		{
+			MethodName: "RegisterHumanInput",
+			Handler:    _MCP_RegisterHumanInput_Handler,
+		},
+		{
+			MethodName: "ListPendingHumanInputs",
+			Handler:    _MCP_ListPendingHumanInputs_Handler,
+		},
+		{
+			MethodName: "CancelHumanInput",
+			Handler:    _MCP_CancelHumanInput_Handler,
+		},
+		{
+			MethodName: "WaitHumanInput",
+			Handler:    _MCP_WaitHumanInput_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamToolLogs",
+			Handler:       _MCP_StreamToolLogs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchHumanInputs",
+			Handler:       _MCP_WatchHumanInputs_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "mcp.proto",
 }
 
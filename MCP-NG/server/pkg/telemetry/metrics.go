@@ -0,0 +1,48 @@
+package telemetry
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// upstreamHTTPDuration records the latency of outbound HTTP requests made by a tool.
+var upstreamHTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "mcp_tool_upstream_http_duration_seconds",
+	Help:    "Latency of outbound HTTP requests made by the tool, by host, method and status code.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"host", "method", "code"})
+
+func init() {
+	prometheus.MustRegister(upstreamHTTPDuration)
+}
+
+// metricsTransport times every round trip. Transport-level failures are recorded with code "error".
+type metricsTransport struct {
+	next http.RoundTripper
+}
+
+func (t metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	upstreamHTTPDuration.WithLabelValues(req.URL.Host, req.Method, code).Observe(time.Since(start).Seconds())
+	return resp, err
+}
+
+// ServeMetrics exposes the tool's metrics on addr at /metrics. It blocks, so run it in a goroutine.
+func ServeMetrics(addr string, logger *slog.Logger) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	logger.Info("Metrics endpoint listening", "address", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		logger.Error("Metrics endpoint failed", "address", addr, "error", err)
+	}
+}
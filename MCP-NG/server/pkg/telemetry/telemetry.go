@@ -0,0 +1,184 @@
+// Package telemetry configures OpenTelemetry tracing and Prometheus metrics for the MCP
+// server and its tools. The orchestrator owns the tracing configuration and hands it to
+// every tool process it launches through environment variables, so a single ExecuteTool
+// call produces one connected trace across the REST gateway, the MCP gRPC server, the
+// tool and any outbound HTTP calls.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"google.golang.org/grpc"
+)
+
+// Environment variables used to pass the tracing configuration to tool processes.
+// The first two are the standard OpenTelemetry variables; the others are MCP-NG specific.
+const (
+	EnvExporter     = "OTEL_TRACES_EXPORTER"
+	EnvOTLPEndpoint = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	EnvFile         = "MCP_TRACES_FILE"
+	EnvSampleRatio  = "MCP_TRACES_SAMPLE_RATIO"
+)
+
+// Supported exporter names.
+const (
+	ExporterNone = "none"
+	ExporterOTLP = "otlp"
+	ExporterFile = "file"
+)
+
+// Config describes where spans are exported to.
+type Config struct {
+	// Exporter is one of "none" (default), "otlp" or "file".
+	Exporter string `json:"exporter"`
+	// Endpoint is the OTLP/gRPC collector address, e.g. "localhost:4317".
+	Endpoint string `json:"endpoint"`
+	// File is the path spans are appended to when Exporter is "file".
+	File string `json:"file"`
+	// SampleRatio is the fraction of new traces that are sampled (0..1). Zero means 1.
+	SampleRatio float64 `json:"sample_ratio"`
+}
+
+// ConfigFromEnv builds a Config from the environment variables set by the orchestrator.
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Exporter: os.Getenv(EnvExporter),
+		Endpoint: os.Getenv(EnvOTLPEndpoint),
+		File:     os.Getenv(EnvFile),
+	}
+	if v := os.Getenv(EnvSampleRatio); v != "" {
+		if ratio, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.SampleRatio = ratio
+		}
+	}
+	return cfg
+}
+
+// Env returns the configuration as environment variables, ready to be appended to exec.Cmd.Env.
+func (c Config) Env() []string {
+	if c.Exporter == "" || c.Exporter == ExporterNone {
+		return []string{EnvExporter + "=" + ExporterNone}
+	}
+	env := []string{EnvExporter + "=" + c.Exporter}
+	if c.Endpoint != "" {
+		env = append(env, EnvOTLPEndpoint+"="+c.Endpoint)
+	}
+	if c.File != "" {
+		env = append(env, EnvFile+"="+c.File)
+	}
+	if c.SampleRatio > 0 {
+		env = append(env, EnvSampleRatio+"="+strconv.FormatFloat(c.SampleRatio, 'f', -1, 64))
+	}
+	return env
+}
+
+// Setup installs the global tracer provider and W3C trace-context propagator for serviceName.
+// The returned function flushes and stops the exporter; it is safe to call even when tracing is disabled.
+func Setup(ctx context.Context, serviceName string, cfg Config) (func(context.Context) error, error) {
+	// The propagator is always installed so that trace context passes through
+	// a process even when it does not export spans itself.
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var closeFile func() error
+	switch cfg.Exporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithInsecure()}
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(cfg.Endpoint))
+		}
+		exp, err := otlptracegrpc.New(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+		exporter = exp
+	case ExporterFile:
+		path := cfg.File
+		if path == "" {
+			path = "traces.jsonl"
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open trace file: %w", err)
+		}
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to create file exporter: %w", err)
+		}
+		exporter = exp
+		closeFile = f.Close
+	default:
+		return nil, fmt.Errorf("unsupported trace exporter: %q", cfg.Exporter)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(serviceName)),
+		resource.WithProcessPID(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	ratio := cfg.SampleRatio
+	if ratio <= 0 {
+		ratio = 1
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(tp)
+
+	return func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		if closeFile != nil {
+			if cerr := closeFile(); err == nil {
+				err = cerr
+			}
+		}
+		return err
+	}, nil
+}
+
+// ServerOption instruments a gRPC server so that incoming trace context is extracted from metadata.
+func ServerOption() grpc.ServerOption {
+	return grpc.StatsHandler(otelgrpc.NewServerHandler())
+}
+
+// DialOption instruments a gRPC client so that the current trace context is injected into metadata.
+func DialOption() grpc.DialOption {
+	return grpc.WithStatsHandler(otelgrpc.NewClientHandler())
+}
+
+// HTTPHandler wraps an HTTP handler with a server span named after the operation.
+func HTTPHandler(h http.Handler, operation string) http.Handler {
+	return otelhttp.NewHandler(h, operation)
+}
+
+// HTTPClient returns an HTTP client whose requests carry the caller's trace context
+// and are timed in the mcp_tool_upstream_http_duration_seconds histogram.
+func HTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: otelhttp.NewTransport(metricsTransport{next: http.DefaultTransport}),
+	}
+}
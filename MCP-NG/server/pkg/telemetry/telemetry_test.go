@@ -0,0 +1,90 @@
+package telemetry
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	pb "mcp-ng/server/pkg/mcp"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// traceCapturingTool records the span context seen by the tool side of a call.
+type traceCapturingTool struct {
+	pb.UnimplementedToolServer
+	seen chan trace.SpanContext
+}
+
+func (s *traceCapturingTool) GetDescription(ctx context.Context, in *pb.GetDescriptionRequest) (*pb.ToolDescription, error) {
+	s.seen <- trace.SpanContextFromContext(ctx)
+	return &pb.ToolDescription{Name: "trace_probe"}, nil
+}
+
+func TestConfigEnvRoundTrip(t *testing.T) {
+	cfg := Config{Exporter: ExporterOTLP, Endpoint: "collector:4317", SampleRatio: 0.25}
+	for _, kv := range cfg.Env() {
+		parts := strings.SplitN(kv, "=", 2)
+		t.Setenv(parts[0], parts[1])
+	}
+	if got := ConfigFromEnv(); got != cfg {
+		t.Errorf("ConfigFromEnv() = %+v, want %+v", got, cfg)
+	}
+}
+
+func TestTraceContextPropagatesToTool(t *testing.T) {
+	traceFile := filepath.Join(t.TempDir(), "traces.jsonl")
+	shutdown, err := Setup(context.Background(), "telemetry-test", Config{Exporter: ExporterFile, File: traceFile})
+	if err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	tool := &traceCapturingTool{seen: make(chan trace.SpanContext, 1)}
+	s := grpc.NewServer(ServerOption())
+	pb.RegisterToolServer(s, tool)
+	go s.Serve(lis)
+	defer s.Stop()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()), DialOption())
+	if err != nil {
+		t.Fatalf("did not connect: %v", err)
+	}
+	defer conn.Close()
+
+	ctx, span := otel.Tracer("telemetry-test").Start(context.Background(), "execute")
+	if _, err := pb.NewToolClient(conn).GetDescription(ctx, &pb.GetDescriptionRequest{}); err != nil {
+		t.Fatalf("GetDescription failed: %v", err)
+	}
+	span.End()
+
+	select {
+	case got := <-tool.seen:
+		if got.TraceID() != span.SpanContext().TraceID() {
+			t.Errorf("tool saw trace %s, want %s", got.TraceID(), span.SpanContext().TraceID())
+		}
+	case <-time.After(time.Second):
+		t.Fatal("tool was not called")
+	}
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown failed: %v", err)
+	}
+	data, err := os.ReadFile(traceFile)
+	if err != nil {
+		t.Fatalf("failed to read trace file: %v", err)
+	}
+	if !strings.Contains(string(data), span.SpanContext().TraceID().String()) {
+		t.Errorf("trace file does not contain trace %s", span.SpanContext().TraceID())
+	}
+}
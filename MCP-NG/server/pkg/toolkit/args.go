@@ -0,0 +1,127 @@
+package toolkit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	pb "mcp-ng/server/pkg/mcp"
+)
+
+// Struct tags read from a tool's argument struct, next to the usual json tag that names
+// the parameter:
+//
+//	description:"..."  the parameter description shown to clients
+//	required:"true"    the call fails unless the argument is present and not null
+//	default:"..."      the value used when the argument is absent; JSON for non-string types
+const (
+	tagDescription = "description"
+	tagRequired    = "required"
+	tagDefault     = "default"
+)
+
+// argField is one parameter of a tool, derived from a field of its argument struct.
+type argField struct {
+	name     string
+	jsonType string
+	required bool
+	def      interface{} // Decoded default value, or nil
+}
+
+// argSchema derives the parameters of a tool from its argument struct.
+func argSchema(t reflect.Type) ([]argField, *pb.ToolParameters, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, nil, fmt.Errorf("argument type %s is not a struct", t)
+	}
+	params := &pb.ToolParameters{Type: "object", Properties: map[string]*pb.ToolParameter{}, Required: []string{}}
+	var fields []argField
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		field := argField{name: name, jsonType: jsonType(f.Type), required: f.Tag.Get(tagRequired) == "true"}
+		if def, ok := f.Tag.Lookup(tagDefault); ok {
+			if field.jsonType == "string" {
+				field.def = def
+			} else if err := json.Unmarshal([]byte(def), &field.def); err != nil {
+				return nil, nil, fmt.Errorf("invalid default for %s: %v", name, err)
+			}
+		}
+		fields = append(fields, field)
+		params.Properties[name] = &pb.ToolParameter{Type: field.jsonType, Description: f.Tag.Get(tagDescription)}
+		if field.required {
+			params.Required = append(params.Required, name)
+		}
+	}
+	return fields, params, nil
+}
+
+// jsonType maps a Go type to the JSON Schema type of its JSON encoding. Maps, structs and
+// interfaces are declared as "object", matching how the tools have always described
+// values that a protobuf Struct field carries.
+func jsonType(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "string" // []byte is encoded as base64
+		}
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+// decodeArgs applies defaults, checks required arguments and decodes the arguments into
+// dst. Missing or mistyped arguments are reported as an ArgumentError.
+func decodeArgs(raw map[string]interface{}, fields []argField, dst interface{}) error {
+	args := make(map[string]interface{}, len(raw))
+	for k, v := range raw {
+		args[k] = v
+	}
+	for _, f := range fields {
+		if v, ok := args[f.name]; ok && v != nil {
+			continue
+		}
+		switch {
+		case f.def != nil:
+			args[f.name] = f.def
+		case f.required:
+			return &ArgumentError{Name: f.name}
+		}
+	}
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Errorf("failed to encode arguments: %v", err)
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			name, _, _ := strings.Cut(typeErr.Field, ".")
+			return &ArgumentError{Name: name}
+		}
+		return fmt.Errorf("failed to decode arguments: %v", err)
+	}
+	return nil
+}
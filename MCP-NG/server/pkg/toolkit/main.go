@@ -0,0 +1,138 @@
+package toolkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"mcp-ng/server/pkg/telemetry"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// ConfigFile is the tool configuration read by Main, relative to the working directory the
+// orchestrator starts the tool in.
+const ConfigFile = "config.json"
+
+// ShutdownTimeout bounds how long Main waits for in-flight calls on SIGINT or SIGTERM before
+// closing the remaining connections.
+const ShutdownTimeout = 10 * time.Second
+
+// AddressEnv names the environment variable through which the orchestrator tells a tool
+// where to listen: a loopback "host:port" or a Unix socket as "unix:///path/to/socket".
+// When it is unset, the tool listens on the port from config.json.
+const AddressEnv = "MCP_TOOL_ADDRESS"
+
+// ServerAddressEnv names the environment variable holding the orchestrator's own gRPC
+// address, for tools that call back into it. It is unset when the tool runs on its own.
+const ServerAddressEnv = "MCP_SERVER_ADDRESS"
+
+// Config holds the settings every tool reads from config.json. Tool-specific settings are
+// decoded from the same file into the value passed to Main.
+type Config struct {
+	Port        int `json:"port"`
+	MetricsPort int `json:"metrics_port"`
+}
+
+// Main runs a tool process. It reads config.json into the toolkit Config and into cfg
+// (when not nil), calls build to construct the tool, sets up tracing and the optional
+// metrics endpoint, serves the Tool and health services on the configured port and stops
+// gracefully on SIGINT or SIGTERM. Main exits the process on any setup error.
+func Main(cfg interface{}, build func(logger *slog.Logger) (*Tool, error)) {
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	if err := serve(logger, cfg, build); err != nil {
+		logger.Error("tool failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+func serve(logger *slog.Logger, cfg interface{}, build func(logger *slog.Logger) (*Tool, error)) error {
+	configFile, err := os.ReadFile(ConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	var config Config
+	if err := json.Unmarshal(configFile, &config); err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if cfg != nil {
+		if err := json.Unmarshal(configFile, cfg); err != nil {
+			return fmt.Errorf("failed to parse config file: %w", err)
+		}
+	}
+
+	tool, err := build(logger)
+	if err != nil {
+		return fmt.Errorf("failed to build tool: %w", err)
+	}
+	tool.logger = logger.With("tool", tool.Name())
+
+	shutdownTracing, err := telemetry.Setup(context.Background(), tool.Name(), telemetry.ConfigFromEnv())
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	if config.MetricsPort != 0 {
+		go telemetry.ServeMetrics(fmt.Sprintf(":%d", config.MetricsPort), logger)
+	}
+
+	lis, address, err := listen(config.Port)
+	if err != nil {
+		return err
+	}
+
+	s := grpc.NewServer(telemetry.ServerOption())
+	healthServer := tool.Register(s)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		logger.Info("Shutting down", "tool", tool.Name())
+		// Report NOT_SERVING first so the orchestrator stops routing calls here.
+		healthServer.SetServingStatus(HealthService, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+		stopped := make(chan struct{})
+		go func() {
+			s.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(ShutdownTimeout):
+			logger.Warn("Graceful shutdown timed out, closing remaining connections")
+			s.Stop()
+		}
+	}()
+
+	logger.Info("gRPC tool listening", "tool", tool.Name(), "address", address)
+	if err := s.Serve(lis); err != nil {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	return nil
+}
+
+// listen opens the listener at the address the orchestrator assigned through AddressEnv,
+// or on port of all interfaces when it did not assign one.
+func listen(port int) (net.Listener, string, error) {
+	network, address := "tcp", fmt.Sprintf(":%d", port)
+	if env := os.Getenv(AddressEnv); env != "" {
+		address = env
+		if path, ok := strings.CutPrefix(env, "unix://"); ok {
+			network, address = "unix", path
+		}
+	}
+	lis, err := net.Listen(network, address)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+	return lis, address, nil
+}
@@ -0,0 +1,194 @@
+// Package toolkit is the SDK for writing MCP-NG tools in Go. A tool is a typed Go function
+// plus an argument struct: the toolkit derives the tool's parameter schema from the struct
+// tags, decodes and validates the arguments of every call, converts the result and errors
+// into a ToolRunResponse, and takes care of configuration, health checks, telemetry and
+// graceful shutdown in Main.
+//
+//	type args struct {
+//		Expression string `json:"expression" required:"true" description:"The expression to evaluate."`
+//	}
+//
+//	func main() {
+//		toolkit.Main(nil, func(logger *slog.Logger) (*toolkit.Tool, error) {
+//			return toolkit.New("calculator", "Evaluates expressions.", evaluate), nil
+//		})
+//	}
+package toolkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+
+	pb "mcp-ng/server/pkg/mcp"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// HealthService is the service name the orchestrator checks on every tool.
+const HealthService = "mcp.Tool"
+
+// Tool is a tool built from a typed function. It implements the Tool gRPC service.
+type Tool struct {
+	pb.UnimplementedToolServer
+	desc   *pb.ToolDescription
+	fields []argField
+	run    func(ctx context.Context, args map[string]interface{}) (interface{}, error)
+	logger *slog.Logger
+	health func(ctx context.Context) error
+}
+
+// Option configures a Tool.
+type Option func(*Tool)
+
+// WithLogger sets the logger used for the tool's request logs and returned by Logger.
+// Main replaces it with the process logger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(t *Tool) { t.logger = logger }
+}
+
+// WithHealthCheck makes the tool report NOT_SERVING while check returns an error, e.g. when
+// a required credential is missing or a backing service is unreachable.
+func WithHealthCheck(check func(ctx context.Context) error) Option {
+	return func(t *Tool) { t.health = check }
+}
+
+// New builds a tool that calls run with the decoded arguments. A must be a struct; its
+// exported fields become the tool's parameters (see the package documentation for the tags).
+// New panics if A is not a struct, since that is a programming error in the tool.
+func New[A any](name, description string, run func(ctx context.Context, args A) (interface{}, error), opts ...Option) *Tool {
+	argsType := reflect.TypeOf((*A)(nil)).Elem()
+	fields, params, err := argSchema(argsType)
+	if err != nil {
+		panic(fmt.Sprintf("toolkit: tool %s: %v", name, err))
+	}
+	t := &Tool{
+		desc:   &pb.ToolDescription{Name: name, Description: description, Parameters: params},
+		fields: fields,
+		logger: slog.Default(),
+	}
+	t.run = func(ctx context.Context, raw map[string]interface{}) (interface{}, error) {
+		var args A
+		if err := decodeArgs(raw, t.fields, &args); err != nil {
+			return nil, err
+		}
+		return run(ctx, args)
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Name returns the tool's name.
+func (t *Tool) Name() string {
+	return t.desc.Name
+}
+
+// Description returns the tool's description, including the parameter schema derived from
+// its argument struct.
+func (t *Tool) Description() *pb.ToolDescription {
+	return t.desc
+}
+
+// GetDescription returns the tool's description.
+func (t *Tool) GetDescription(ctx context.Context, in *pb.GetDescriptionRequest) (*pb.ToolDescription, error) {
+	t.logger.Info("Received request for tool description")
+	return t.desc, nil
+}
+
+// Run decodes the arguments, calls the tool function and converts its result. Errors from
+// decoding or from the tool are returned in the response, not as gRPC errors, so the
+// orchestrator can tell a failed call from an unreachable tool.
+func (t *Tool) Run(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunResponse, error) {
+	t.logger.Info("Received request to run "+t.desc.Name, "args", in.Arguments)
+
+	result, err := t.run(withLogger(ctx, t.logger), in.GetArguments().AsMap())
+	if err != nil {
+		t.logger.Error("Tool call failed", "error", err)
+		return &pb.ToolRunResponse{Error: err.Error()}, nil
+	}
+	value, err := toValue(result)
+	if err != nil {
+		t.logger.Error("Error converting result to protobuf value", "error", err)
+		return &pb.ToolRunResponse{Error: fmt.Sprintf("Error converting result to protobuf value: %v", err)}, nil
+	}
+	return &pb.ToolRunResponse{Result: value}, nil
+}
+
+// Register registers the Tool and health services on s and returns the health server, so
+// the caller can flip the tool to NOT_SERVING before shutting down.
+func (t *Tool) Register(s *grpc.Server) *health.Server {
+	pb.RegisterToolServer(s, t)
+	hs := health.NewServer()
+	hs.SetServingStatus(HealthService, grpc_health_v1.HealthCheckResponse_SERVING)
+	grpc_health_v1.RegisterHealthServer(s, &healthServer{Server: hs, check: t.health, logger: t.logger})
+	return hs
+}
+
+// healthServer consults the tool's health check on every Check of a serving tool.
+type healthServer struct {
+	*health.Server
+	check  func(ctx context.Context) error
+	logger *slog.Logger
+}
+
+func (h *healthServer) Check(ctx context.Context, in *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	resp, err := h.Server.Check(ctx, in)
+	if err != nil || h.check == nil || resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		return resp, err
+	}
+	if err := h.check(ctx); err != nil {
+		h.logger.Warn("Health check failed", "error", err)
+		return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_NOT_SERVING}, nil
+	}
+	return resp, nil
+}
+
+type loggerKey struct{}
+
+func withLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// Logger returns the tool's logger inside a tool function, or the default logger elsewhere.
+func Logger(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// toValue converts a tool result into a protobuf Value. Plain values, maps and slices are
+// converted directly; anything else, such as a struct, goes through its JSON encoding.
+func toValue(result interface{}) (*structpb.Value, error) {
+	if v, ok := result.(*structpb.Value); ok {
+		return v, nil
+	}
+	if v, err := structpb.NewValue(result); err == nil {
+		return v, nil
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return structpb.NewValue(generic)
+}
+
+// ArgumentError reports an argument that is missing or has the wrong type.
+type ArgumentError struct {
+	Name string
+}
+
+func (e *ArgumentError) Error() string {
+	return fmt.Sprintf("Invalid or missing '%s' argument", e.Name)
+}
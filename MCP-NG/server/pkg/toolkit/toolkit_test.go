@@ -0,0 +1,205 @@
+package toolkit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	pb "mcp-ng/server/pkg/mcp"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type testArgs struct {
+	Query   string            `json:"query" required:"true" description:"The search query."`
+	Limit   int               `json:"limit" default:"5"`
+	Method  string            `json:"method" default:"GET"`
+	Tags    []string          `json:"tags"`
+	Headers map[string]string `json:"headers"`
+	Body    interface{}       `json:"body"`
+	Ignored string            `json:"-"`
+}
+
+type testResult struct {
+	Query string `json:"query"`
+	Limit int    `json:"limit"`
+}
+
+var nullLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func newTestTool(opts ...Option) *Tool {
+	run := func(ctx context.Context, args testArgs) (interface{}, error) {
+		if args.Query == "fail" {
+			return nil, errors.New("search failed")
+		}
+		return testResult{Query: args.Query + " " + args.Method, Limit: args.Limit}, nil
+	}
+	return New("search", "Searches.", run, append([]Option{WithLogger(nullLogger)}, opts...)...)
+}
+
+func TestSchemaFromArgs(t *testing.T) {
+	params := newTestTool().Description().Parameters
+	want := map[string]string{"query": "string", "limit": "integer", "method": "string", "tags": "array", "headers": "object", "body": "object"}
+	if len(params.Properties) != len(want) {
+		t.Fatalf("unexpected properties: %v", params.Properties)
+	}
+	for name, typ := range want {
+		if got := params.Properties[name].GetType(); got != typ {
+			t.Errorf("%s: expected type %s, got %s", name, typ, got)
+		}
+	}
+	if params.Properties["query"].Description != "The search query." {
+		t.Errorf("unexpected description: %q", params.Properties["query"].Description)
+	}
+	if len(params.Required) != 1 || params.Required[0] != "query" {
+		t.Errorf("unexpected required list: %v", params.Required)
+	}
+}
+
+func TestRunDecodesArguments(t *testing.T) {
+	tool := newTestTool()
+	run := func(args map[string]interface{}) *pb.ToolRunResponse {
+		t.Helper()
+		s, err := structpb.NewStruct(args)
+		if err != nil {
+			t.Fatalf("failed to create args struct: %v", err)
+		}
+		resp, err := tool.Run(context.Background(), &pb.ToolRunRequest{Arguments: s})
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		return resp
+	}
+
+	resp := run(map[string]interface{}{"query": "go"})
+	if resp.Error != "" {
+		t.Fatalf("Run returned an error: %s", resp.Error)
+	}
+	result := resp.Result.GetStructValue().AsMap()
+	if result["query"] != "go GET" || result["limit"] != float64(5) {
+		t.Errorf("expected defaults to be applied, got %v", result)
+	}
+
+	tests := []struct {
+		name string
+		args map[string]interface{}
+		want string
+	}{
+		{"missing required", map[string]interface{}{}, "Invalid or missing 'query' argument"},
+		{"null required", map[string]interface{}{"query": nil}, "Invalid or missing 'query' argument"},
+		{"wrong type", map[string]interface{}{"query": "go", "limit": "ten"}, "Invalid or missing 'limit' argument"},
+		{"wrong nested type", map[string]interface{}{"query": "go", "headers": map[string]interface{}{"a": 1}}, "Invalid or missing 'headers' argument"},
+		{"tool error", map[string]interface{}{"query": "fail"}, "search failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if resp := run(tt.args); resp.Error != tt.want {
+				t.Errorf("expected error %q, got %q", tt.want, resp.Error)
+			}
+		})
+	}
+}
+
+func TestNewRejectsNonStructArgs(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected New to panic for a non-struct argument type")
+		}
+	}()
+	New("bad", "", func(ctx context.Context, args string) (interface{}, error) { return nil, nil })
+}
+
+func TestRegisterServesToolAndHealth(t *testing.T) {
+	var healthy atomic.Bool
+	healthy.Store(true)
+	tool := newTestTool(WithHealthCheck(func(ctx context.Context) error {
+		if !healthy.Load() {
+			return errors.New("backend down")
+		}
+		return nil
+	}))
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	s := grpc.NewServer()
+	tool.Register(s)
+	go s.Serve(lis)
+	defer s.Stop()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("did not connect: %v", err)
+	}
+	defer conn.Close()
+
+	desc, err := pb.NewToolClient(conn).GetDescription(context.Background(), &pb.GetDescriptionRequest{})
+	if err != nil || desc.Name != "search" {
+		t.Fatalf("unexpected description: %v, %v", desc, err)
+	}
+
+	health := grpc_health_v1.NewHealthClient(conn)
+	check := func() grpc_health_v1.HealthCheckResponse_ServingStatus {
+		resp, err := health.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{Service: HealthService})
+		if err != nil {
+			t.Fatalf("health check failed: %v", err)
+		}
+		return resp.Status
+	}
+	if got := check(); got != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Errorf("expected SERVING, got %v", got)
+	}
+	healthy.Store(false)
+	if got := check(); got != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("expected NOT_SERVING while the health check fails, got %v", got)
+	}
+}
+
+func TestLoggerFromContext(t *testing.T) {
+	var got *slog.Logger
+	tool := New("log", "", func(ctx context.Context, args struct{}) (interface{}, error) {
+		got = Logger(ctx)
+		return "ok", nil
+	}, WithLogger(nullLogger))
+	if _, err := tool.Run(context.Background(), &pb.ToolRunRequest{}); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if got != nullLogger {
+		t.Error("expected the tool's logger in the call context")
+	}
+	if Logger(context.Background()) != slog.Default() {
+		t.Error("expected the default logger outside a tool call")
+	}
+}
+
+func TestListenHonorsAssignedAddress(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "tool.sock")
+	t.Setenv(AddressEnv, "unix://"+socket)
+	lis, address, err := listen(50051)
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	lis.Close()
+	if lis.Addr().Network() != "unix" || address != socket {
+		t.Errorf("expected a Unix socket at %s, got %s %s", socket, lis.Addr().Network(), address)
+	}
+
+	t.Setenv(AddressEnv, "127.0.0.1:0")
+	lis, _, err = listen(50051)
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	lis.Close()
+	if host, _, _ := net.SplitHostPort(lis.Addr().String()); host != "127.0.0.1" {
+		t.Errorf("expected a loopback listener, got %s", lis.Addr())
+	}
+}
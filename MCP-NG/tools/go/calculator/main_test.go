@@ -2,9 +2,7 @@ package main
 
 import (
 	"context"
-	"io"
 	"log"
-	"log/slog"
 	"net"
 	"testing"
 	"time"
@@ -24,11 +22,8 @@ func startTestServer() (string, func()) {
 		log.Fatalf("failed to listen: %v", err)
 	}
 
-	// Create a logger that discards output for clean test logs
-	nullLogger := slog.New(slog.NewTextHandler(io.Discard, nil))
-
 	s := grpc.NewServer()
-	pb.RegisterToolServer(s, &server{logger: nullLogger})
+	newTool().Register(s)
 
 	addr := lis.Addr().String()
 
@@ -3,94 +3,54 @@ package main
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"fmt"
 	"log/slog"
-	"net"
 	"os"
 	"path/filepath"
 
 	_ "github.com/mattn/go-sqlite3"
-	pb "mcp-ng/server/pkg/mcp"
-
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/health"
-	"google.golang.org/grpc/health/grpc_health_v1"
-	"google.golang.org/protobuf/types/known/structpb"
+	"mcp-ng/server/pkg/toolkit"
 )
 
-// server implements the Tool service.
-type server struct {
-	pb.UnimplementedToolServer
-	logger *slog.Logger
+type args struct {
+	DBPath string `json:"db_path" required:"true" description:"The path to the SQLite database file (can be relative or absolute)."`
+	Query  string `json:"query" required:"true" description:"The SQL query to execute."`
 }
 
-// GetDescription returns the tool's description.
-func (s *server) GetDescription(ctx context.Context, in *pb.GetDescriptionRequest) (*pb.ToolDescription, error) {
-	s.logger.Info("Received request for tool description")
-	return &pb.ToolDescription{
-		Name:        "db_querier",
-		Description: "Executes a SQL query against a specified SQLite database and returns the result. WARNING: Executes any SQL query, including destructive ones like DELETE/UPDATE.",
-		Parameters: &pb.ToolParameters{
-			Type: "object",
-			Properties: map[string]*pb.ToolParameter{
-				"db_path": {
-					Type:        "string",
-					Description: "The path to the SQLite database file (can be relative or absolute).",
-				},
-				"query": {
-					Type:        "string",
-					Description: "The SQL query to execute.",
-				},
-			},
-			Required: []string{"db_path", "query"},
-		},
-	}, nil
+// newTool builds the db_querier tool.
+func newTool() *toolkit.Tool {
+	return toolkit.New("db_querier",
+		"Executes a SQL query against a specified SQLite database and returns the result. WARNING: Executes any SQL query, including destructive ones like DELETE/UPDATE.",
+		query)
 }
 
-// Run executes the db_querier tool.
-func (s *server) Run(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunResponse, error) {
-	s.logger.Info("Received request to run db_querier", "args", in.Arguments)
-
-	dbPath, ok := in.Arguments.Fields["db_path"].AsInterface().(string)
-	if !ok {
-		s.logger.Error("Invalid or missing 'db_path' argument")
-		return &pb.ToolRunResponse{Error: "Invalid or missing 'db_path' argument"}, nil
-	}
-	query, ok := in.Arguments.Fields["query"].AsInterface().(string)
-	if !ok {
-		s.logger.Error("Invalid or missing 'query' argument")
-		return &pb.ToolRunResponse{Error: "Invalid or missing 'query' argument"}, nil
-	}
-
+// query runs the SQL query and returns the rows as a list of column-to-value maps.
+func query(ctx context.Context, a args) (interface{}, error) {
 	// Clean the path to prevent directory traversal issues (e.g., ../../etc/passwd)
 	// This makes the tool more secure and portable.
-	absPath := filepath.Clean(dbPath)
+	absPath := filepath.Clean(a.DBPath)
 
 	// Check if the database file exists before trying to open it.
 	if _, err := os.Stat(absPath); os.IsNotExist(err) {
-		s.logger.Error("Database file not found", "path", absPath)
-		return &pb.ToolRunResponse{Error: fmt.Sprintf("Database file not found: '%s'", dbPath)}, nil
+		return nil, fmt.Errorf("Database file not found: '%s'", a.DBPath)
 	}
 
 	db, err := sql.Open("sqlite3", absPath)
 	if err != nil {
-		s.logger.Error("Failed to open database", "path", absPath, "error", err)
-		return &pb.ToolRunResponse{Error: fmt.Sprintf("Failed to open database: %v", err)}, nil
+		return nil, fmt.Errorf("Failed to open database: %v", err)
 	}
 	defer db.Close()
 
-	rows, err := db.QueryContext(ctx, query)
+	rows, err := db.QueryContext(ctx, a.Query)
 	if err != nil {
-		s.logger.Error("SQL query error", "query", query, "error", err)
-		return &pb.ToolRunResponse{Error: fmt.Sprintf("SQL query error: %v", err)}, nil
+		toolkit.Logger(ctx).Error("SQL query error", "query", a.Query, "error", err)
+		return nil, fmt.Errorf("SQL query error: %v", err)
 	}
 	defer rows.Close()
 
 	columns, err := rows.Columns()
 	if err != nil {
-		s.logger.Error("Failed to get columns", "error", err)
-		return &pb.ToolRunResponse{Error: fmt.Sprintf("Failed to get columns: %v", err)}, nil
+		return nil, fmt.Errorf("Failed to get columns: %v", err)
 	}
 
 	var results []interface{}
@@ -102,8 +62,7 @@ func (s *server) Run(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunRes
 		}
 
 		if err := rows.Scan(rowPointers...); err != nil {
-			s.logger.Error("Failed to scan row", "error", err)
-			return &pb.ToolRunResponse{Error: fmt.Sprintf("Failed to scan row: %v", err)}, nil
+			return nil, fmt.Errorf("Failed to scan row: %v", err)
 		}
 
 		rowMap := make(map[string]interface{})
@@ -118,53 +77,11 @@ func (s *server) Run(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunRes
 		}
 		results = append(results, rowMap)
 	}
-
-	resultValue, err := structpb.NewValue(results)
-	if err != nil {
-		s.logger.Error("Error creating result value", "error", err)
-		return &pb.ToolRunResponse{Error: fmt.Sprintf("Error creating result value: %v", err)}, nil
-	}
-
-	return &pb.ToolRunResponse{Result: resultValue}, nil
-}
-
-type Config struct {
-	Port int `json:"port"`
+	return results, nil
 }
 
 func main() {
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
-	// Read configuration
-	configFile, err := os.ReadFile("config.json")
-	if err != nil {
-		logger.Error("failed to read config file", "error", err)
-		os.Exit(1)
-	}
-
-	var config Config
-	if err := json.Unmarshal(configFile, &config); err != nil {
-		logger.Error("failed to parse config file", "error", err)
-		os.Exit(1)
-	}
-
-	address := fmt.Sprintf(":%d", config.Port)
-	lis, err := net.Listen("tcp", address)
-	if err != nil {
-		logger.Error("failed to listen", "address", address, "error", err)
-		os.Exit(1)
-	}
-
-	s := grpc.NewServer()
-	pb.RegisterToolServer(s, &server{logger: logger})
-
-	// Register the health check service
-	healthServer := health.NewServer()
-	grpc_health_v1.RegisterHealthServer(s, healthServer)
-	healthServer.SetServingStatus("mcp.Tool", grpc_health_v1.HealthCheckResponse_SERVING)
-
-	logger.Info("DB_Querier gRPC tool listening", "address", address)
-	if err := s.Serve(lis); err != nil {
-		logger.Error("failed to serve", "error", err)
-		os.Exit(1)
-	}
-}
\ No newline at end of file
+	toolkit.Main(nil, func(logger *slog.Logger) (*toolkit.Tool, error) {
+		return newTool(), nil
+	})
+}
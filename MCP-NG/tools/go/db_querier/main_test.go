@@ -3,9 +3,7 @@ package main
 import (
 	"context"
 	"database/sql"
-	"log/slog"
 	"net"
-	"os"
 	"path/filepath"
 	"reflect"
 	"testing"
@@ -57,8 +55,7 @@ func startTestServer(t *testing.T) (string, func()) {
 	}
 
 	s := grpc.NewServer()
-	// We pass a logger to avoid nil pointer panics if logging is used.
-	pb.RegisterToolServer(s, &server{logger: slog.New(slog.NewTextHandler(os.Stdout, nil))})
+	newTool().Register(s)
 	addr := lis.Addr().String()
 
 	go func() {
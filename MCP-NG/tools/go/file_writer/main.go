@@ -2,128 +2,45 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log/slog"
-	"net"
-	"os"
 	"path/filepath"
 	"strings"
 
-	pb "mcp-ng/server/pkg/mcp"
-
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/health"
-	"google.golang.org/grpc/health/grpc_health_v1"
-	"google.golang.org/protobuf/types/known/structpb"
+	"mcp-ng/server/pkg/toolkit"
 )
 
-// server implements the Tool service.
-type server struct {
-	pb.UnimplementedToolServer
-	logger *slog.Logger
+type args struct {
+	Filepath string `json:"filepath" required:"true" description:"The path to the file to be written."`
+	Content  string `json:"content" required:"true" description:"The content to write to the file."`
 }
 
-// GetDescription returns the tool's description.
-func (s *server) GetDescription(ctx context.Context, in *pb.GetDescriptionRequest) (*pb.ToolDescription, error) {
-	s.logger.Info("Received request for file_writer description")
-	return &pb.ToolDescription{
-		Name:        "file_writer",
-		Description: "Writes specified content to a file. Overwrites the file if it already exists.",
-		Parameters: &pb.ToolParameters{
-			Type: "object",
-			Properties: map[string]*pb.ToolParameter{
-				"filepath": {
-					Type:        "string",
-					Description: "The path to the file to be written.",
-				},
-				"content": {
-					Type:        "string",
-					Description: "The content to write to the file.",
-				},
-			},
-			Required: []string{"filepath", "content"},
-		},
-	}, nil
+// newTool builds the file_writer tool.
+func newTool() *toolkit.Tool {
+	return toolkit.New("file_writer",
+		"Writes specified content to a file. Overwrites the file if it already exists.",
+		writeFile)
 }
 
-// Run executes the file_writer tool.
-func (s *server) Run(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunResponse, error) {
-	s.logger.Info("Received request to run file_writer", "args", in.Arguments)
-
-	pathArg, ok := in.Arguments.Fields["filepath"].AsInterface().(string)
-	if !ok {
-		s.logger.Error("Invalid or missing 'filepath' argument")
-		return &pb.ToolRunResponse{Error: "Invalid or missing 'filepath' argument"}, nil
-	}
-	contentArg, ok := in.Arguments.Fields["content"].AsInterface().(string)
-	if !ok {
-		s.logger.Error("Invalid or missing 'content' argument")
-		return &pb.ToolRunResponse{Error: "Invalid or missing 'content' argument"}, nil
-	}
-
+// writeFile writes the content to the file.
+func writeFile(ctx context.Context, a args) (interface{}, error) {
 	// Security measure
-	securePath := filepath.Clean(pathArg)
+	securePath := filepath.Clean(a.Filepath)
 	if strings.HasPrefix(securePath, "..") {
-		s.logger.Error("Access denied: filepath cannot be outside the project directory", "path", pathArg)
-		return &pb.ToolRunResponse{Error: "Access denied: filepath cannot be outside the project directory"}, nil
+		return nil, errors.New("Access denied: filepath cannot be outside the project directory")
 	}
 	absPath := filepath.Join("/app", securePath)
 
-	err := ioutil.WriteFile(absPath, []byte(contentArg), 0644)
-	if err != nil {
-		s.logger.Error("Error writing to file", "path", absPath, "error", err)
-		return &pb.ToolRunResponse{Error: fmt.Sprintf("Error writing to file: %v", err)}, nil
+	if err := ioutil.WriteFile(absPath, []byte(a.Content), 0644); err != nil {
+		return nil, fmt.Errorf("Error writing to file: %v", err)
 	}
-
-	successMsg := fmt.Sprintf("Successfully wrote %d bytes to %s", len(contentArg), securePath)
-	resultValue, err := structpb.NewValue(successMsg)
-	if err != nil {
-		s.logger.Error("Error creating success message", "error", err)
-		return &pb.ToolRunResponse{Error: fmt.Sprintf("Error creating success message: %v", err)}, nil
-	}
-
-	return &pb.ToolRunResponse{Result: resultValue}, nil
-}
-
-type Config struct {
-	Port int `json:"port"`
+	return fmt.Sprintf("Successfully wrote %d bytes to %s", len(a.Content), securePath), nil
 }
 
 func main() {
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
-	// Read configuration
-	configFile, err := os.ReadFile("config.json")
-	if err != nil {
-		logger.Error("failed to read config file", "error", err)
-		os.Exit(1)
-	}
-
-	var config Config
-	if err := json.Unmarshal(configFile, &config); err != nil {
-		logger.Error("failed to parse config file", "error", err)
-		os.Exit(1)
-	}
-
-	address := fmt.Sprintf(":%d", config.Port)
-	lis, err := net.Listen("tcp", address)
-	if err != nil {
-		logger.Error("failed to listen", "address", address, "error", err)
-		os.Exit(1)
-	}
-
-	s := grpc.NewServer()
-	pb.RegisterToolServer(s, &server{logger: logger})
-
-	// Register the health check service
-	healthServer := health.NewServer()
-	grpc_health_v1.RegisterHealthServer(s, healthServer)
-	healthServer.SetServingStatus("mcp.Tool", grpc_health_v1.HealthCheckResponse_SERVING)
-
-	logger.Info("File_writer gRPC tool listening", "address", address)
-	if err := s.Serve(lis); err != nil {
-		logger.Error("failed to serve", "error", err)
-		os.Exit(1)
-	}
-}
\ No newline at end of file
+	toolkit.Main(nil, func(logger *slog.Logger) (*toolkit.Tool, error) {
+		return newTool(), nil
+	})
+}
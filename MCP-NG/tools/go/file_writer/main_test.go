@@ -25,7 +25,7 @@ func startTestServer() (string, func()) {
 	}
 
 	s := grpc.NewServer()
-	pb.RegisterToolServer(s, &server{})
+	newTool().Register(s)
 	addr := lis.Addr().String()
 
 	go func() {
@@ -1,5 +1,7 @@
 package broker
 
+import "mcp-ng/server/pkg/humanform"
+
 // Publisher defines the interface for publishing messages.
 // This allows for different implementations (e.g., WebSocket, Kafka, Redis).
 type Publisher interface {
@@ -13,4 +15,6 @@ type Publisher interface {
 type Message struct {
 	TaskID string `json:"task_id"`
 	Prompt string `json:"prompt"`
+	// Form, if set, is the structured form the operator's client renders for the answer.
+	Form *humanform.Form `json:"form,omitempty"`
 }
\ No newline at end of file
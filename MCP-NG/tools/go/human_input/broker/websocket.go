@@ -1,23 +1,44 @@
 package broker
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/gorilla/websocket"
 )
 
+// publishTimeout bounds how long Publish waits for the bridge to acknowledge a message.
+const publishTimeout = 10 * time.Second
+
+// envelope is a frame of the human bridge protocol.
+type envelope struct {
+	Type    string          `json:"type"`
+	Topic   string          `json:"topic,omitempty"`
+	ID      string          `json:"id,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
 // WebSocketPublisher implements the Publisher interface for WebSockets.
 type WebSocketPublisher struct {
 	conn *websocket.Conn
 }
 
-// NewWebSocketPublisher creates and connects a new WebSocket client.
-func NewWebSocketPublisher(serverAddr string) (*WebSocketPublisher, error) {
+// NewWebSocketPublisher creates and connects a new WebSocket client. A non-empty token is
+// sent as a bearer token for bridges that authenticate their clients.
+func NewWebSocketPublisher(serverAddr, token string) (*WebSocketPublisher, error) {
 	u := url.URL{Scheme: "ws", Host: serverAddr, Path: "/ws"}
 	fmt.Printf("Connecting to WebSocket server at %s\n", u.String())
 
-	c, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	header := http.Header{}
+	if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+	c, _, err := websocket.DefaultDialer.Dial(u.String(), header)
 	if err != nil {
 		return nil, fmt.Errorf("failed to dial websocket server: %w", err)
 	}
@@ -25,13 +46,31 @@ func NewWebSocketPublisher(serverAddr string) (*WebSocketPublisher, error) {
 	return &WebSocketPublisher{conn: c}, nil
 }
 
-// Publish sends a message to the WebSocket server.
-// The concept of a "topic" is not native to a single WebSocket connection,
-// so we just send the message. The server will decide how to route it.
+// Publish sends a message to the bridge on the given topic and waits for the bridge to
+// accept it. The bridge delivers it only to the clients subscribed to the topic.
 func (p *WebSocketPublisher) Publish(topic string, message []byte) error {
-	// We can optionally prepend the topic to the message if the server is designed to handle it.
-	// For now, we assume the server broadcasts all messages from this connection.
-	return p.conn.WriteMessage(websocket.TextMessage, message)
+	frame, err := json.Marshal(envelope{Type: "publish", Topic: topic, Payload: message})
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	if err := p.conn.WriteMessage(websocket.TextMessage, frame); err != nil {
+		return err
+	}
+
+	p.conn.SetReadDeadline(time.Now().Add(publishTimeout))
+	defer p.conn.SetReadDeadline(time.Time{})
+	for {
+		var reply envelope
+		if err := p.conn.ReadJSON(&reply); err != nil {
+			return fmt.Errorf("no acknowledgement from the bridge: %w", err)
+		}
+		switch reply.Type {
+		case "published":
+			return nil
+		case "error":
+			return errors.New("bridge rejected the message: " + reply.Error)
+		}
+	}
 }
 
 // Close closes the WebSocket connection.
@@ -42,4 +81,4 @@ func (p *WebSocketPublisher) Close() error {
 		return err
 	}
 	return p.conn.Close()
-}
\ No newline at end of file
+}
@@ -5,156 +5,137 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
-	"net"
 	"os"
 
 	"github.com/google/uuid"
 	"mcp-ng/human_input-tool/broker"
+	"mcp-ng/server/pkg/humanform"
 	pb "mcp-ng/server/pkg/mcp"
+	"mcp-ng/server/pkg/toolkit"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/health"
-	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
-// server implements the Tool service.
-type server struct {
-	pb.UnimplementedToolServer
-	brokerType    string
-	brokerAddress string
-	logger        *slog.Logger
+type args struct {
+	Prompt string                 `json:"prompt" required:"true" description:"The question or prompt to show to the human operator."`
+	Form   map[string]interface{} `json:"form" description:"An optional structured form for the answer: an object with a type of approval, single_choice, multi_choice, number, text or json and the fields of that type."`
+	Topic  string                 `json:"topic" description:"The broker topic to send the prompt on, such as a team's queue. Defaults to human_intervention_required."`
 }
 
-// GetDescription returns the tool's description.
-func (s *server) GetDescription(ctx context.Context, in *pb.GetDescriptionRequest) (*pb.ToolDescription, error) {
-	s.logger.Info("Received request for tool description")
-	return &pb.ToolDescription{
-		Name:        "human_input",
-		Description: "Sends a prompt to a human operator and waits for an asynchronous response. Use for critical or irreversible actions.",
-		Parameters: &pb.ToolParameters{
-			Type: "object",
-			Properties: map[string]*pb.ToolParameter{
-				"prompt": {
-					Type:        "string",
-					Description: "The question or prompt to show to the human operator.",
-				},
-			},
-			Required: []string{"prompt"},
-		},
-	}, nil
+// defaultTopic is the topic prompts go out on unless the caller picks another.
+const defaultTopic = "human_intervention_required"
+
+// requester publishes prompts to the configured message broker.
+type requester struct {
+	broker BrokerConfig
+	// serverAddress is the MCP server's gRPC address. Tasks are registered there before the
+	// prompt goes out, so an early answer is never rejected as unknown. Empty skips this.
+	serverAddress string
 }
 
-// Run executes the human_input tool.
-func (s *server) Run(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunResponse, error) {
-	s.logger.Info("Received request to run human_input", "args", in.Arguments)
+// newTool builds the human_input tool.
+func newTool(broker BrokerConfig, serverAddress string) *toolkit.Tool {
+	r := &requester{broker: broker, serverAddress: serverAddress}
+	return toolkit.New("human_input",
+		"Sends a prompt to a human operator and waits for an asynchronous response. Use for critical or irreversible actions.",
+		r.request)
+}
 
-	prompt, ok := in.Arguments.Fields["prompt"].AsInterface().(string)
-	if !ok {
-		s.logger.Error("Invalid or missing 'prompt' argument")
-		return &pb.ToolRunResponse{Error: "Invalid or missing 'prompt' argument"}, nil
+// request publishes the prompt and returns immediately with the task ID the operator's
+// answer will be filed under.
+func (r *requester) request(ctx context.Context, a args) (interface{}, error) {
+	var form *humanform.Form
+	if a.Form != nil {
+		var err error
+		if form, err = humanform.Parse(a.Form); err != nil {
+			return nil, err
+		}
 	}
 
 	// 1. Create a publisher based on config
 	var pub broker.Publisher
 	var err error
-	switch s.brokerType {
+	switch r.broker.Type {
 	case "websocket":
-		pub, err = broker.NewWebSocketPublisher(s.brokerAddress)
+		pub, err = broker.NewWebSocketPublisher(r.broker.Address, r.broker.Token)
 		if err != nil {
-			s.logger.Error("Failed to connect to WebSocket broker", "error", err)
-			return &pb.ToolRunResponse{Error: fmt.Sprintf("Failed to connect to WebSocket broker: %v", err)}, nil
+			return nil, fmt.Errorf("Failed to connect to WebSocket broker: %v", err)
 		}
 	default:
-		s.logger.Error("Unsupported message broker type", "type", s.brokerType)
-		return &pb.ToolRunResponse{Error: fmt.Sprintf("Unsupported message broker type: %s", s.brokerType)}, nil
+		return nil, fmt.Errorf("Unsupported message broker type: %s", r.broker.Type)
 	}
 	defer pub.Close()
 
-	// 2. Generate task ID and message
+	// 2. Generate and register the task ID, then build the message
 	taskID := uuid.New().String()
+	if err := r.register(ctx, taskID, a.Prompt, form); err != nil {
+		return nil, fmt.Errorf("Failed to register task with the MCP server: %v", err)
+	}
 	msg := broker.Message{
 		TaskID: taskID,
-		Prompt: prompt,
+		Prompt: a.Prompt,
+		Form:   form,
 	}
 	msgBytes, err := json.Marshal(msg)
 	if err != nil {
-		s.logger.Error("Failed to create message", "error", err)
-		return &pb.ToolRunResponse{Error: fmt.Sprintf("Failed to create message: %v", err)}, nil
+		return nil, fmt.Errorf("Failed to create message: %v", err)
 	}
 
 	// 3. Publish the message
-	s.logger.Info("Publishing prompt", "task_id", taskID)
-	err = pub.Publish("human_intervention_required", msgBytes)
-	if err != nil {
-		s.logger.Error("Failed to publish message", "error", err)
-		return &pb.ToolRunResponse{Error: fmt.Sprintf("Failed to publish message: %v", err)}, nil
+	topic := a.Topic
+	if topic == "" {
+		topic = defaultTopic
+	}
+	toolkit.Logger(ctx).Info("Publishing prompt", "task_id", taskID, "topic", topic)
+	if err := pub.Publish(topic, msgBytes); err != nil {
+		return nil, fmt.Errorf("Failed to publish message: %v", err)
 	}
 
 	// 4. Return immediately with the task_id
-	result, err := structpb.NewStruct(map[string]interface{}{
+	return map[string]interface{}{
 		"status":  "waiting_for_human",
 		"task_id": taskID,
-	})
+	}, nil
+}
+
+// register records the task with the MCP server.
+func (r *requester) register(ctx context.Context, taskID, prompt string, form *humanform.Form) error {
+	if r.serverAddress == "" {
+		toolkit.Logger(ctx).Warn("No MCP server address, not registering task", "task_id", taskID)
+		return nil
+	}
+	conn, err := grpc.NewClient(r.serverAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	if err != nil {
-		s.logger.Error("Failed to create response", "error", err)
-		return &pb.ToolRunResponse{Error: fmt.Sprintf("Failed to create response: %v", err)}, nil
+		return err
 	}
-
-	return &pb.ToolRunResponse{
-		Result: &structpb.Value{
-			Kind: &structpb.Value_StructValue{StructValue: result},
-		},
-	}, nil
+	defer conn.Close()
+	req := &pb.RegisterHumanInputRequest{TaskId: taskID, Prompt: prompt, Creator: "human_input"}
+	if form != nil {
+		if req.Form, err = structpb.NewStruct(form.Map()); err != nil {
+			return err
+		}
+	}
+	_, err = pb.NewMCPClient(conn).RegisterHumanInput(ctx, req)
+	return err
 }
 
 type BrokerConfig struct {
 	Type    string `json:"type"`
 	Address string `json:"address"`
+	// Token authenticates the tool to the broker, if it requires that.
+	Token string `json:"token"`
 }
 
 type Config struct {
-	Port   int          `json:"port"`
 	Broker BrokerConfig `json:"broker"`
 }
 
 func main() {
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
-	// Read configuration
-	configFile, err := os.ReadFile("config.json")
-	if err != nil {
-		logger.Error("failed to read config file", "error", err)
-		os.Exit(1)
-	}
-
 	var config Config
-	if err := json.Unmarshal(configFile, &config); err != nil {
-		logger.Error("failed to parse config file", "error", err)
-		os.Exit(1)
-	}
-
-	address := fmt.Sprintf(":%d", config.Port)
-	lis, err := net.Listen("tcp", address)
-	if err != nil {
-		logger.Error("failed to listen", "address", address, "error", err)
-		os.Exit(1)
-	}
-
-	s := grpc.NewServer()
-	pb.RegisterToolServer(s, &server{
-		brokerType:    config.Broker.Type,
-		brokerAddress: config.Broker.Address,
-		logger:        logger,
+	toolkit.Main(&config, func(logger *slog.Logger) (*toolkit.Tool, error) {
+		logger.Info("Using message broker", "broker_type", config.Broker.Type, "broker_address", config.Broker.Address)
+		return newTool(config.Broker, os.Getenv(toolkit.ServerAddressEnv)), nil
 	})
-
-	// Register the health check service
-	healthServer := health.NewServer()
-	grpc_health_v1.RegisterHealthServer(s, healthServer)
-	healthServer.SetServingStatus("mcp.Tool", grpc_health_v1.HealthCheckResponse_SERVING)
-
-	logger.Info("Human_Input gRPC tool listening", "address", address, "broker_type", config.Broker.Type, "broker_address", config.Broker.Address)
-	if err := s.Serve(lis); err != nil {
-		logger.Error("failed to serve", "error", err)
-		os.Exit(1)
-	}
-}
\ No newline at end of file
+}
@@ -22,11 +22,14 @@ import (
 
 var upgrader = websocket.Upgrader{}
 
-// startTestWSServer creates a mock WebSocket server for testing.
+// startTestWSServer creates a mock WebSocket bridge for testing.
 // It returns the server, its address, and a channel to receive messages on.
 func startTestWSServer(t *testing.T) (*httptest.Server, chan broker.Message) {
 	messageChan := make(chan broker.Message, 1)
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "Bearer test-token" {
+			t.Errorf("expected the broker token to be sent, got %q", auth)
+		}
 		conn, err := upgrader.Upgrade(w, r, nil)
 		if err != nil {
 			t.Logf("Failed to upgrade connection: %v", err)
@@ -40,12 +43,22 @@ func startTestWSServer(t *testing.T) (*httptest.Server, chan broker.Message) {
 			return
 		}
 
+		var frame struct {
+			Type    string          `json:"type"`
+			Topic   string          `json:"topic"`
+			Payload json.RawMessage `json:"payload"`
+		}
+		if err := json.Unmarshal(msgBytes, &frame); err != nil || frame.Type != "publish" || frame.Topic == "" {
+			t.Logf("Unexpected frame %s: %v", msgBytes, err)
+			return
+		}
 		var msg broker.Message
-		if err := json.Unmarshal(msgBytes, &msg); err != nil {
+		if err := json.Unmarshal(frame.Payload, &msg); err != nil {
 			t.Logf("Failed to unmarshal message: %v", err)
 			return
 		}
 		messageChan <- msg
+		conn.WriteJSON(map[string]interface{}{"type": "published", "topic": frame.Topic, "id": "1", "delivered": 1})
 	})
 
 	server := httptest.NewServer(handler)
@@ -53,17 +66,14 @@ func startTestWSServer(t *testing.T) (*httptest.Server, chan broker.Message) {
 }
 
 // startTestGrpcServer starts the human_input gRPC server on a random available port.
-func startTestGrpcServer(brokerType, brokerAddr string) (string, func()) {
+func startTestGrpcServer(brokerType, brokerAddr, serverAddr string) (string, func()) {
 	lis, err := net.Listen("tcp", ":0")
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
 
 	s := grpc.NewServer()
-	pb.RegisterToolServer(s, &server{
-		brokerType:    brokerType,
-		brokerAddress: brokerAddr,
-	})
+	newTool(BrokerConfig{Type: brokerType, Address: brokerAddr, Token: "test-token"}, serverAddr).Register(s)
 	addr := lis.Addr().String()
 
 	go func() {
@@ -86,7 +96,7 @@ func TestRunHumanInput(t *testing.T) {
 	wsAddr := strings.TrimPrefix(mockWSServer.URL, "http://")
 
 	// 2. Start the gRPC server for testing
-	grpcAddr, stopGrpcServer := startTestGrpcServer("websocket", wsAddr)
+	grpcAddr, stopGrpcServer := startTestGrpcServer("websocket", wsAddr, "")
 	defer stopGrpcServer()
 
 	// 3. Connect to the gRPC server
@@ -136,4 +146,81 @@ func TestRunHumanInput(t *testing.T) {
 	}
 
 	t.Logf("Successfully tested human_input tool. Task ID: %s", taskID)
-}
\ No newline at end of file
+}
+
+// fakeMCPServer records the human-input tasks registered with it.
+type fakeMCPServer struct {
+	pb.UnimplementedMCPServer
+	registered chan *pb.RegisterHumanInputRequest
+}
+
+func (f *fakeMCPServer) RegisterHumanInput(ctx context.Context, in *pb.RegisterHumanInputRequest) (*pb.HumanInputTask, error) {
+	f.registered <- in
+	return &pb.HumanInputTask{TaskId: in.TaskId, Status: "pending"}, nil
+}
+
+func TestRunHumanInputRegistersTask(t *testing.T) {
+	mockWSServer, msgChan := startTestWSServer(t)
+	defer mockWSServer.Close()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mcp := &fakeMCPServer{registered: make(chan *pb.RegisterHumanInputRequest, 1)}
+	mcpServer := grpc.NewServer()
+	pb.RegisterMCPServer(mcpServer, mcp)
+	go mcpServer.Serve(lis)
+	defer mcpServer.Stop()
+
+	grpcAddr, stopGrpcServer := startTestGrpcServer("websocket", strings.TrimPrefix(mockWSServer.URL, "http://"), lis.Addr().String())
+	defer stopGrpcServer()
+	conn, err := grpc.NewClient(grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("did not connect: %v", err)
+	}
+	defer conn.Close()
+
+	args, _ := structpb.NewStruct(map[string]interface{}{
+		"prompt": "Deploy?",
+		"form":   map[string]interface{}{"type": "single_choice", "options": []interface{}{map[string]interface{}{"value": "eu"}, map[string]interface{}{"value": "us"}}},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	res, err := pb.NewToolClient(conn).Run(ctx, &pb.ToolRunRequest{Arguments: args})
+	if err != nil || res.Error != "" {
+		t.Fatalf("gRPC Run failed: %v, %s", err, res.GetError())
+	}
+	taskID := res.Result.GetStructValue().AsMap()["task_id"]
+
+	// The task is registered before the prompt reaches the operator.
+	select {
+	case reg := <-mcp.registered:
+		if reg.TaskId != taskID || reg.Prompt != "Deploy?" || reg.Creator != "human_input" {
+			t.Errorf("unexpected registration: %v", reg)
+		}
+		if reg.Form.AsMap()["type"] != "single_choice" {
+			t.Errorf("expected the form to be registered, got %v", reg.Form)
+		}
+	default:
+		t.Fatal("expected the task to be registered with the MCP server")
+	}
+	select {
+	case msg := <-msgChan:
+		if msg.Form == nil || len(msg.Form.Options) != 2 {
+			t.Errorf("expected the form in the message, got %+v", msg.Form)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for message on WebSocket server")
+	}
+
+	// An invalid form is rejected before anything is registered or published.
+	args, _ = structpb.NewStruct(map[string]interface{}{"prompt": "Deploy?", "form": map[string]interface{}{"type": "slider"}})
+	res, err = pb.NewToolClient(conn).Run(ctx, &pb.ToolRunRequest{Arguments: args})
+	if err != nil || res.Error == "" {
+		t.Errorf("expected an invalid form to fail the call, got %v, %v", res, err)
+	}
+	if len(mcp.registered) != 0 {
+		t.Error("expected no registration for an invalid form")
+	}
+}
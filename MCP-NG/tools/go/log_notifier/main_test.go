@@ -9,6 +9,7 @@ import (
 	"time"
 
 	pb "mcp-ng/server/pkg/mcp"
+	"mcp-ng/server/pkg/toolkit"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
@@ -26,7 +27,7 @@ func startTestServer(t *testing.T) (string, func()) {
 	s := grpc.NewServer()
 	// Используем slog, как и в основном коде, но выводим в io.Discard, чтобы не засорять вывод теста
 	testLogger := slog.New(slog.NewJSONHandler(io.Discard, nil))
-	pb.RegisterToolServer(s, &server{logger: testLogger})
+	newTool(toolkit.WithLogger(testLogger)).Register(s)
 	addr := lis.Addr().String()
 
 	go func() {
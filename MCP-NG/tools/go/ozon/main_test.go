@@ -71,7 +71,7 @@ func startTestGrpcServer(clientID, apiKey string) (string, func()) {
 	}
 
 	s := grpc.NewServer()
-	pb.RegisterToolServer(s, &server{clientID: clientID, apiKey: apiKey})
+	newTool(clientID, apiKey).Register(s)
 	addr := lis.Addr().String()
 
 	go func() {
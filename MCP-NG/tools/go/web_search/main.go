@@ -4,116 +4,80 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log/slog"
-	"net"
 	"net/http"
-	"os"
 	"time"
 
-	pb "mcp-ng/server/pkg/mcp"
-
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/health"
-	"google.golang.org/grpc/health/grpc_health_v1"
-	"google.golang.org/protobuf/types/known/structpb"
+	"mcp-ng/server/pkg/telemetry"
+	"mcp-ng/server/pkg/toolkit"
 )
 
 const tavilyAPIURL = "https://api.tavily.com/search"
 
-// server implements the Tool service.
-type server struct {
-	pb.UnimplementedToolServer
-	apiKey string
-	logger *slog.Logger
+type args struct {
+	Query      string  `json:"query" required:"true" description:"The search query."`
+	MaxResults float64 `json:"max_results" default:"5" description:"The maximum number of results to return. Defaults to 5."`
 }
 
-// GetDescription returns the tool's description.
-func (s *server) GetDescription(ctx context.Context, in *pb.GetDescriptionRequest) (*pb.ToolDescription, error) {
-	s.logger.Info("Received request for tool description")
-	return &pb.ToolDescription{
-		Name:        "web_search",
-		Description: "Performs a web search using the Tavily AI search engine. Use this for up-to-date information, facts, or news.",
-		Parameters: &pb.ToolParameters{
-			Type: "object",
-			Properties: map[string]*pb.ToolParameter{
-				"query": {
-					Type:        "string",
-					Description: "The search query.",
-				},
-				"max_results": {
-					Type:        "number",
-					Description: "The maximum number of results to return. Defaults to 5.",
-				},
-			},
-			Required: []string{"query"},
-		},
-	}, nil
+// searcher queries the Tavily API with the configured key.
+type searcher struct {
+	apiKey string
 }
 
-// Run executes the web_search tool.
-func (s *server) Run(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunResponse, error) {
-	s.logger.Info("Received request to run web_search", "args", in.Arguments)
+// newTool builds the web_search tool.
+func newTool(apiKey string) *toolkit.Tool {
+	s := &searcher{apiKey: apiKey}
+	return toolkit.New("web_search",
+		"Performs a web search using the Tavily AI search engine. Use this for up-to-date information, facts, or news.",
+		s.search)
+}
 
+// search runs the query and returns the list of results.
+func (s *searcher) search(ctx context.Context, a args) (interface{}, error) {
 	if s.apiKey == "" {
-		s.logger.Error("TAVILY_API_KEY is not set in the config")
-		return &pb.ToolRunResponse{Error: "TAVILY_API_KEY is not set in the config"}, nil
-	}
-
-	query, ok := in.Arguments.Fields["query"].AsInterface().(string)
-	if !ok {
-		s.logger.Error("Invalid or missing 'query' argument")
-		return &pb.ToolRunResponse{Error: "Invalid or missing 'query' argument"}, nil
-	}
-
-	maxResults := 5.0 // Default value
-	if val, ok := in.Arguments.Fields["max_results"]; ok {
-		maxResults = val.GetNumberValue()
+		return nil, errors.New("TAVILY_API_KEY is not set in the config")
 	}
 
 	// Prepare request body for Tavily API
 	requestBody := map[string]interface{}{
 		"api_key":      s.apiKey,
-		"query":        query,
+		"query":        a.Query,
 		"search_depth": "advanced",
-		"max_results":  int(maxResults),
+		"max_results":  int(a.MaxResults),
 	}
 
 	jsonBody, err := json.Marshal(requestBody)
 	if err != nil {
-		s.logger.Error("Failed to marshal request body", "error", err)
-		return &pb.ToolRunResponse{Error: fmt.Sprintf("Failed to marshal request body: %v", err)}, nil
+		return nil, fmt.Errorf("Failed to marshal request body: %v", err)
 	}
 
 	// Create and execute HTTP request
 	req, err := http.NewRequestWithContext(ctx, "POST", tavilyAPIURL, bytes.NewBuffer(jsonBody))
 	if err != nil {
-		s.logger.Error("Failed to create request", "error", err)
-		return &pb.ToolRunResponse{Error: fmt.Sprintf("Failed to create request: %v", err)}, nil
+		return nil, fmt.Errorf("Failed to create request: %v", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 20 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := telemetry.HTTPClient(20 * time.Second).Do(req)
 	if err != nil {
-		s.logger.Error("Failed to execute request", "error", err)
-		return &pb.ToolRunResponse{Error: fmt.Sprintf("Failed to execute request: %v", err)}, nil
+		return nil, fmt.Errorf("Failed to execute request: %v", err)
 	}
 	defer resp.Body.Close()
 
 	// Handle non-200 status codes
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := ioutil.ReadAll(resp.Body)
-		s.logger.Error("Tavily API error", "status", resp.StatusCode, "body", string(bodyBytes))
-		return &pb.ToolRunResponse{Error: fmt.Sprintf("Tavily API error (status %d): %s", resp.StatusCode, string(bodyBytes))}, nil
+		toolkit.Logger(ctx).Error("Tavily API error", "status", resp.StatusCode, "body", string(bodyBytes))
+		return nil, fmt.Errorf("Tavily API error (status %d): %s", resp.StatusCode, string(bodyBytes))
 	}
 
 	// Parse the response
 	var tavilyResponse map[string]interface{}
 	if err := json.NewDecoder(resp.Body).Decode(&tavilyResponse); err != nil {
-		s.logger.Error("Failed to decode response", "error", err)
-		return &pb.ToolRunResponse{Error: fmt.Sprintf("Failed to decode response: %v", err)}, nil
+		return nil, fmt.Errorf("Failed to decode response: %v", err)
 	}
 
 	// Extract the 'results' field
@@ -121,14 +85,7 @@ func (s *server) Run(ctx context.Context, in *pb.ToolRunRequest) (*pb.ToolRunRes
 	if !ok {
 		results = []interface{}{} // Return empty list if no results
 	}
-
-	resultValue, err := structpb.NewValue(results)
-	if err != nil {
-		s.logger.Error("Error creating result value", "error", err)
-		return &pb.ToolRunResponse{Error: fmt.Sprintf("Error creating result value: %v", err)}, nil
-	}
-
-	return &pb.ToolRunResponse{Result: resultValue}, nil
+	return results, nil
 }
 
 type APIConfig struct {
@@ -136,50 +93,15 @@ type APIConfig struct {
 }
 
 type Config struct {
-	Port       int       `json:"port"`
-	TavilyAPI  APIConfig `json:"tavily_api"`
+	TavilyAPI APIConfig `json:"tavily_api"`
 }
 
 func main() {
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
-	// Read configuration
-	configFile, err := os.ReadFile("config.json")
-	if err != nil {
-		logger.Error("failed to read config file", "error", err)
-		os.Exit(1)
-	}
-
 	var config Config
-	if err := json.Unmarshal(configFile, &config); err != nil {
-		logger.Error("failed to parse config file", "error", err)
-		os.Exit(1)
-	}
-
-	if config.TavilyAPI.APIKey == "" {
-		logger.Warn("TAVILY_API_KEY not set in config.json")
-	}
-
-	address := fmt.Sprintf(":%d", config.Port)
-	lis, err := net.Listen("tcp", address)
-	if err != nil {
-		logger.Error("failed to listen", "address", address, "error", err)
-		os.Exit(1)
-	}
-
-	s := grpc.NewServer()
-	pb.RegisterToolServer(s, &server{
-		apiKey: config.TavilyAPI.APIKey,
-		logger: logger,
+	toolkit.Main(&config, func(logger *slog.Logger) (*toolkit.Tool, error) {
+		if config.TavilyAPI.APIKey == "" {
+			logger.Warn("TAVILY_API_KEY not set in config.json")
+		}
+		return newTool(config.TavilyAPI.APIKey), nil
 	})
-
-	// Register the health check service
-	healthServer := health.NewServer()
-	grpc_health_v1.RegisterHealthServer(s, healthServer)
-	healthServer.SetServingStatus("mcp.Tool", grpc_health_v1.HealthCheckResponse_SERVING)
-
-	logger.Info("Web_Search gRPC tool listening", "address", address)
-	if err := s.Serve(lis); err != nil {
-		logger.Error("failed to serve", "error", err)
-		os.Exit(1)
-	}
-}
\ No newline at end of file
+}
@@ -22,7 +22,7 @@ func startTestServer(apiKey string) (string, func()) {
 	}
 
 	s := grpc.NewServer()
-	pb.RegisterToolServer(s, &server{apiKey: apiKey})
+	newTool(apiKey).Register(s)
 	addr := lis.Addr().String()
 
 	go func() {
@@ -75,7 +75,7 @@ func startTestGrpcServer(apiKey string) (string, func()) {
 	}
 
 	s := grpc.NewServer()
-	pb.RegisterToolServer(s, &server{apiKey: apiKey})
+	newTool(apiKey).Register(s)
 	addr := lis.Addr().String()
 
 	go func() {
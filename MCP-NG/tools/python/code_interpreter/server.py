@@ -1,6 +1,8 @@
 import grpc
 from concurrent import futures
-import time
+import os
+import signal
+import threading
 import sys
 from pathlib import Path
 import logging
@@ -77,18 +79,25 @@ class CodeInterpreterTool(mcp_pb2_grpc.ToolServicer):
 
         return response
 
+# How long running calls may take to finish after a shutdown signal.
+SHUTDOWN_GRACE_SECONDS = 10
+
 def serve():
     """
     Starts the gRPC server.
     """
-    config_path = Path(__file__).parent / 'config.json'
-    try:
-        with open(config_path, 'r') as f:
-            config = json.load(f)
-        port = config['port']
-    except (FileNotFoundError, KeyError) as e:
-        logging.critical(f"Failed to read or parse configuration: {e}")
-        sys.exit(1)
+    # The orchestrator assigns the address (a loopback port or a unix:// socket) through
+    # MCP_TOOL_ADDRESS; the port from config.json is used when the tool runs on its own.
+    address = os.environ.get('MCP_TOOL_ADDRESS')
+    if not address:
+        config_path = Path(__file__).parent / 'config.json'
+        try:
+            with open(config_path, 'r') as f:
+                config = json.load(f)
+            address = f"[::]:{config['port']}"
+        except (FileNotFoundError, KeyError) as e:
+            logging.critical(f"Failed to read or parse configuration: {e}")
+            sys.exit(1)
 
 
     server = grpc.server(futures.ThreadPoolExecutor(max_workers=10))
@@ -99,15 +108,19 @@ def serve():
     health_pb2_grpc.add_HealthServicer_to_server(health_servicer, server)
     health_servicer.set("mcp.Tool", health_pb2.HealthCheckResponse.SERVING)
 
-    server.add_insecure_port(f'[::]:{port}')
+    server.add_insecure_port(address)
     server.start()
-    logging.info(f"Code Interpreter gRPC tool listening on :{port}")
-    try:
-        while True:
-            time.sleep(86400) # One day
-    except KeyboardInterrupt:
-        logging.info("Shutting down server...")
-        server.stop(0)
+    logging.info(f"Code Interpreter gRPC tool listening on {address}")
+
+    # The orchestrator sends SIGTERM on shutdown and kills the tool after its grace period,
+    # so running calls get that long to finish.
+    stopping = threading.Event()
+    signal.signal(signal.SIGTERM, lambda signum, frame: stopping.set())
+    signal.signal(signal.SIGINT, lambda signum, frame: stopping.set())
+    stopping.wait()
+    logging.info("Shutting down server...")
+    health_servicer.set("mcp.Tool", health_pb2.HealthCheckResponse.NOT_SERVING)
+    server.stop(SHUTDOWN_GRACE_SECONDS).wait()
 
 if __name__ == '__main__':
     serve()
\ No newline at end of file